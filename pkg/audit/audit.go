@@ -0,0 +1,119 @@
+// Package audit writes the audit log: one JSON line per write request sent
+// to a cluster, appended as soon as the request returns so the log survives
+// an interrupted run. Entries identify objects only; object contents, and
+// so Secret data, are never recorded.
+package audit
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/a13x22/kube-copy/pkg/copier"
+)
+
+// Entry is a line of the audit log.
+type Entry struct {
+	Time   time.Time `json:"time"`
+	Server string    `json:"server"`         // API server URL of the cluster written to
+	User   string    `json:"user,omitempty"` // kubeconfig user the request was made as
+
+	Verb      string `json:"verb"`   // "create" or "delete"
+	Action    string `json:"action"` // what it was for, see copier.AuditEvent
+	Group     string `json:"group,omitempty"`
+	Version   string `json:"version"`
+	Resource  string `json:"resource"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Cluster identifies a cluster written to.
+type Cluster struct {
+	Server string
+	User   string
+}
+
+// Log is an audit log file. It is safe for concurrent use.
+type Log struct {
+	mu   sync.Mutex
+	file *os.File
+	err  error
+}
+
+// Open opens the audit log at path for appending, creating it readable by
+// its owner only.
+func Open(path string) (*Log, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &Log{file: f}, nil
+}
+
+// Write appends e as a line. Errors are kept and returned by Close, so a
+// failing log does not interrupt the writes it records.
+func (l *Log) Write(e Entry) {
+	line, err := json.Marshal(e)
+	if err != nil {
+		l.fail(err)
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// A single unbuffered write per entry, so each line is complete on disk
+	// once the request it records has returned
+	if _, err := l.file.Write(append(line, '\n')); err != nil && l.err == nil {
+		l.err = err
+	}
+}
+
+func (l *Log) fail(err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.err == nil {
+		l.err = err
+	}
+}
+
+// Recorder returns a function for copier.Copier.Audit that writes each event
+// as an entry for the cluster it was sent to.
+func (l *Log) Recorder(source, target Cluster) func(copier.AuditEvent) {
+	return func(e copier.AuditEvent) {
+		cluster := target
+		if e.Source {
+			cluster = source
+		}
+		entry := Entry{
+			Time:      time.Now().UTC(),
+			Server:    cluster.Server,
+			User:      cluster.User,
+			Verb:      e.Verb,
+			Action:    e.Action,
+			Group:     e.GVR.Group,
+			Version:   e.GVR.Version,
+			Resource:  e.GVR.Resource,
+			Namespace: e.Namespace,
+			Name:      e.Name,
+			Success:   e.Err == nil,
+		}
+		if e.Err != nil {
+			entry.Error = e.Err.Error()
+		}
+		l.Write(entry)
+	}
+}
+
+// Close closes the log and returns the first error writing it.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	err := l.file.Close()
+	if l.err != nil {
+		return l.err
+	}
+	return err
+}
@@ -0,0 +1,229 @@
+// Package bundle reads and writes the archives that carry sanitized objects
+// between clusters with no network path between them: export writes one from
+// the source, import applies it to the target. A bundle is a gzipped tarball
+// holding a manifest.json followed by one YAML file per object, in apply
+// order, each listed in the manifest with its checksum.
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+// FormatVersion is the layout of bundles this version writes and the only
+// one it reads. It changes whenever older versions could not import a
+// bundle correctly.
+const FormatVersion = 1
+
+const manifestFile = "manifest.json"
+
+// Manifest describes the contents of a bundle.
+type Manifest struct {
+	FormatVersion int       `json:"formatVersion"`
+	ToolVersion   string    `json:"toolVersion,omitempty"` // kubecopy version that wrote the bundle
+	CreatedAt     time.Time `json:"createdAt"`
+
+	SourceCluster string `json:"sourceCluster,omitempty"` // kubeconfig context exported from
+	SourceVersion string `json:"sourceVersion,omitempty"` // its Kubernetes version
+
+	Resources []Resource `json:"resources"`
+}
+
+// Resource is an object in the bundle.
+type Resource struct {
+	Group     string `json:"group,omitempty"`
+	Version   string `json:"version"`
+	Resource  string `json:"resource"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"` // empty for cluster-scoped resources
+	Name      string `json:"name"`
+
+	File   string `json:"file"`
+	SHA256 string `json:"sha256"` // of File's contents, hex-encoded
+
+	// Warnings raised while sanitizing the object for export.
+	Warnings []Warning `json:"warnings,omitempty"`
+}
+
+// GVR returns the resource type of r.
+func (r Resource) GVR() schema.GroupVersionResource {
+	return schema.GroupVersionResource{Group: r.Group, Version: r.Version, Resource: r.Resource}
+}
+
+// Warning is a sanitizer warning recorded at export.
+type Warning struct {
+	Resource string `json:"resource,omitempty"` // when about another object, e.g. a container
+	Code     string `json:"code,omitempty"`
+	Severity string `json:"severity,omitempty"`
+	Message  string `json:"message"`
+}
+
+// Bundle is a manifest and its objects; Objects[i] is Manifest.Resources[i].
+type Bundle struct {
+	Manifest Manifest
+	Objects  []*unstructured.Unstructured
+}
+
+// Write writes b as a gzipped tarball, filling in the format version and the
+// file name and checksum of every resource.
+func (b *Bundle) Write(w io.Writer) error {
+	if len(b.Objects) != len(b.Manifest.Resources) {
+		return fmt.Errorf("bundle has %d objects for %d resources", len(b.Objects), len(b.Manifest.Resources))
+	}
+	b.Manifest.FormatVersion = FormatVersion
+
+	files := make([][]byte, len(b.Objects))
+	for i, obj := range b.Objects {
+		data, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return fmt.Errorf("encode %s/%s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+		files[i] = data
+		r := &b.Manifest.Resources[i]
+		r.File = fmt.Sprintf("objects/%04d-%s-%s.yaml", i+1, strings.ToLower(r.Kind), r.Name)
+		r.SHA256 = checksum(data)
+	}
+	manifest, err := json.MarshalIndent(b.Manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	add := func(name string, data []byte) error {
+		header := &tar.Header{Name: name, Mode: 0o600, Size: int64(len(data)), ModTime: b.Manifest.CreatedAt}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+	if err := add(manifestFile, manifest); err != nil {
+		return err
+	}
+	for i, data := range files {
+		if err := add(b.Manifest.Resources[i].File, data); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// Read reads a bundle written by Write. It refuses bundles of another format
+// version and bundles whose files are missing or do not match their
+// checksums.
+func Read(r io.Reader) (*Bundle, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("not a bundle: %w", err)
+	}
+	defer gz.Close()
+
+	files := map[string][]byte{}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("not a bundle: %w", err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", header.Name, err)
+		}
+		files[header.Name] = data
+	}
+
+	data, ok := files[manifestFile]
+	if !ok {
+		return nil, fmt.Errorf("not a bundle: no %s", manifestFile)
+	}
+	b := &Bundle{}
+	if err := json.Unmarshal(data, &b.Manifest); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", manifestFile, err)
+	}
+	if m := b.Manifest; m.FormatVersion != FormatVersion {
+		writer := "another version of kubectl-copy"
+		if m.ToolVersion != "" {
+			writer = "kubectl-copy " + m.ToolVersion
+		}
+		return nil, fmt.Errorf("the bundle was written by %s in format %d, but this version only reads format %d", writer, m.FormatVersion, FormatVersion)
+	}
+
+	for _, r := range b.Manifest.Resources {
+		data, ok := files[r.File]
+		if !ok {
+			return nil, fmt.Errorf("bundle is missing %s (%s/%s)", r.File, r.Kind, r.Name)
+		}
+		if checksum(data) != r.SHA256 {
+			return nil, fmt.Errorf("checksum mismatch for %s (%s/%s): the bundle is corrupt or was modified", r.File, r.Kind, r.Name)
+		}
+		// Through JSON, so integers stay int64 as in objects read from a cluster
+		obj := &unstructured.Unstructured{}
+		jsonData, err := yaml.YAMLToJSON(data)
+		if err == nil {
+			err = obj.UnmarshalJSON(jsonData)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("decode %s: %w", r.File, err)
+		}
+		b.Objects = append(b.Objects, obj)
+	}
+	return b, nil
+}
+
+// WriteFile writes b to the file at path, replacing it. Bundles can hold
+// Secrets, so the file is made readable by its owner only, like the audit
+// log.
+func WriteFile(path string, b *Bundle) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	// A file being replaced keeps its mode otherwise
+	if err := f.Chmod(0o600); err != nil {
+		f.Close()
+		return err
+	}
+	if err := b.Write(f); err != nil {
+		f.Close()
+		return fmt.Errorf("write bundle %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+// ReadFile reads the bundle at path.
+func ReadFile(path string) (*Bundle, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	b, err := Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("bundle %s: %w", path, err)
+	}
+	return b, nil
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,88 @@
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func testBundle() *Bundle {
+	secret := &unstructured.Unstructured{}
+	secret.SetAPIVersion("v1")
+	secret.SetKind("Secret")
+	secret.SetName("db-credentials")
+	secret.SetNamespace("prod")
+	_ = unstructured.SetNestedStringMap(secret.Object, map[string]string{"password": "hunter2"}, "stringData")
+	return &Bundle{
+		Manifest: Manifest{
+			CreatedAt: time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC),
+			Resources: []Resource{{Version: "v1", Resource: "secrets", Kind: "Secret", Namespace: "prod", Name: "db-credentials"}},
+		},
+		Objects: []*unstructured.Unstructured{secret},
+	}
+}
+
+func TestWriteFileIsPrivate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.tar.gz")
+	// A bundle replacing a readable file must not stay readable
+	if err := os.WriteFile(path, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFile(path, testBundle()); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0o600 {
+		t.Errorf("bundle file mode %o, want 600", mode)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if header.Mode != 0o600 {
+			t.Errorf("%s extracts with mode %o, want 600", header.Name, header.Mode)
+		}
+	}
+}
+
+func TestWriteFileRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.tar.gz")
+	if err := WriteFile(path, testBundle()); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	b, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if len(b.Objects) != 1 || b.Objects[0].GetName() != "db-credentials" {
+		t.Fatalf("read back %d objects, want the Secret", len(b.Objects))
+	}
+	if password, _, _ := unstructured.NestedString(b.Objects[0].Object, "stringData", "password"); password != "hunter2" {
+		t.Errorf("Secret data %q, want it unchanged", password)
+	}
+}
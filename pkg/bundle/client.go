@@ -0,0 +1,110 @@
+package bundle
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+)
+
+// Client returns a read-only dynamic client serving the bundle's objects, so
+// they can be planned and applied like objects read from a source cluster.
+// Only Get and List are supported.
+func (b *Bundle) Client() dynamic.Interface {
+	c := &client{objects: map[objectKey]*unstructured.Unstructured{}}
+	for i, r := range b.Manifest.Resources {
+		c.objects[objectKey{r.GVR().GroupResource(), r.Namespace, r.Name}] = b.Objects[i]
+	}
+	return c
+}
+
+type objectKey struct {
+	resource  schema.GroupResource
+	namespace string
+	name      string
+}
+
+type client struct {
+	objects map[objectKey]*unstructured.Unstructured
+}
+
+func (c *client) Resource(gvr schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	return &resource{client: c, gvr: gvr}
+}
+
+type resource struct {
+	client    *client
+	gvr       schema.GroupVersionResource
+	namespace string
+}
+
+func (r *resource) Namespace(ns string) dynamic.ResourceInterface {
+	return &resource{client: r.client, gvr: r.gvr, namespace: ns}
+}
+
+func (r *resource) Get(_ context.Context, name string, _ metav1.GetOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	if len(subresources) > 0 {
+		return nil, r.readOnly()
+	}
+	obj, ok := r.client.objects[objectKey{r.gvr.GroupResource(), r.namespace, name}]
+	if !ok {
+		return nil, apierrors.NewNotFound(r.gvr.GroupResource(), name)
+	}
+	return obj.DeepCopy(), nil
+}
+
+func (r *resource) List(_ context.Context, _ metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	list := &unstructured.UnstructuredList{}
+	for key, obj := range r.client.objects {
+		if key.resource == r.gvr.GroupResource() && (r.namespace == "" || key.namespace == r.namespace) {
+			list.Items = append(list.Items, *obj.DeepCopy())
+		}
+	}
+	return list, nil
+}
+
+// readOnly is the error of every call a bundle cannot serve.
+func (r *resource) readOnly() error {
+	return apierrors.NewMethodNotSupported(r.gvr.GroupResource(), "this call on a bundle")
+}
+
+func (r *resource) Create(context.Context, *unstructured.Unstructured, metav1.CreateOptions, ...string) (*unstructured.Unstructured, error) {
+	return nil, r.readOnly()
+}
+
+func (r *resource) Update(context.Context, *unstructured.Unstructured, metav1.UpdateOptions, ...string) (*unstructured.Unstructured, error) {
+	return nil, r.readOnly()
+}
+
+func (r *resource) UpdateStatus(context.Context, *unstructured.Unstructured, metav1.UpdateOptions) (*unstructured.Unstructured, error) {
+	return nil, r.readOnly()
+}
+
+func (r *resource) Delete(context.Context, string, metav1.DeleteOptions, ...string) error {
+	return r.readOnly()
+}
+
+func (r *resource) DeleteCollection(context.Context, metav1.DeleteOptions, metav1.ListOptions) error {
+	return r.readOnly()
+}
+
+func (r *resource) Watch(context.Context, metav1.ListOptions) (watch.Interface, error) {
+	return nil, r.readOnly()
+}
+
+func (r *resource) Patch(context.Context, string, types.PatchType, []byte, metav1.PatchOptions, ...string) (*unstructured.Unstructured, error) {
+	return nil, r.readOnly()
+}
+
+func (r *resource) Apply(context.Context, string, *unstructured.Unstructured, metav1.ApplyOptions, ...string) (*unstructured.Unstructured, error) {
+	return nil, r.readOnly()
+}
+
+func (r *resource) ApplyStatus(context.Context, string, *unstructured.Unstructured, metav1.ApplyOptions) (*unstructured.Unstructured, error) {
+	return nil, r.readOnly()
+}
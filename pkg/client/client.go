@@ -1,12 +1,21 @@
 package client
 
 import (
+	"errors"
 	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"time"
 
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/version"
 	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/restmapper"
 	"k8s.io/client-go/tools/clientcmd"
@@ -14,70 +23,294 @@ import (
 
 // Clients holds dynamic clients and REST mappers for source and target clusters.
 type Clients struct {
-	SourceDynamic dynamic.Interface
-	SourceMapper  meta.RESTMapper
+	SourceDynamic   dynamic.Interface
+	SourceMapper    meta.RESTMapper
+	SourceDiscovery discovery.DiscoveryInterface
 
-	TargetDynamic dynamic.Interface
-	TargetMapper  meta.RESTMapper
+	TargetDynamic   dynamic.Interface
+	TargetMapper    meta.RESTMapper
+	TargetDiscovery discovery.DiscoveryInterface
+
+	// Typed clients and their configs, for the pod, log, and port-forward
+	// APIs used by volume data transfers
+	SourceTyped  kubernetes.Interface
+	TargetTyped  kubernetes.Interface
+	SourceConfig *rest.Config
+	TargetConfig *rest.Config
+
+	// SameCluster is true when source and target use the same API server.
+	SameCluster bool
+
+	// Server URLs of the clusters, and the kubeconfig users the clients
+	// authenticate as, for the audit log.
+	SourceServer, SourceUser string
+	TargetServer, TargetUser string
+
+	// Requests counts the API requests sent to both clusters, including
+	// discovery.
+	Requests *RequestCounter
+}
+
+// Options configures how Clients connect to the source and target clusters.
+type Options struct {
+	// Kubeconfig and Context select the source cluster; empty means the
+	// default kubeconfig and its current context. User and Cluster override
+	// the context's kubeconfig user and cluster entries.
+	Kubeconfig string
+	Context    string
+	User       string
+	Cluster    string
+
+	// TargetKubeconfig and TargetContext point the target at another
+	// cluster, and TargetUser and TargetCluster override the entries of the
+	// target context; when all are empty the target is the source cluster.
+	// The target context defaults to the source's; without a target
+	// kubeconfig or context, so do the user and cluster overrides.
+	TargetKubeconfig string
+	TargetContext    string
+	TargetUser       string
+	TargetCluster    string
+
+	// QPS and Burst rate-limit requests to each cluster; zero keeps the
+	// client-go defaults. TargetQPS and TargetBurst override them for the
+	// target when non-zero.
+	QPS         float32
+	Burst       int
+	TargetQPS   float32
+	TargetBurst int
+
+	// Timeout bounds every single request; zero means no timeout.
+	Timeout time.Duration
+
+	// TLS overrides the kubeconfig's server certificate verification for
+	// the source, TargetTLS for the target.
+	TLS       TLSOptions
+	TargetTLS TLSOptions
+}
+
+// TLSOptions override how a cluster's serving certificate is verified. At
+// most one of them may be set.
+type TLSOptions struct {
+	// Insecure skips verification entirely.
+	Insecure bool
+	// CAFile verifies against this CA bundle instead of the kubeconfig's.
+	CAFile string
+}
+
+// apply sets the overrides on cfg, replacing any CA from the kubeconfig.
+func (t TLSOptions) apply(cfg *rest.Config) {
+	switch {
+	case t.Insecure:
+		cfg.Insecure = true
+		cfg.CAFile, cfg.CAData = "", nil
+	case t.CAFile != "":
+		cfg.Insecure = false
+		cfg.CAFile, cfg.CAData = t.CAFile, nil
+	}
 }
 
-// New creates Clients from the given kubeconfig parameters.
-// sourceContext uses the current kubeconfig/context. Target parameters
-// allow pointing to a different context/kubeconfig for cross-cluster copies.
-func New(kubeconfig, sourceContext, targetKubeconfig, targetContext string) (*Clients, error) {
-	sourceCfg, err := buildConfig(kubeconfig, sourceContext)
+// New creates Clients from the given options. The target defaults to the
+// source cluster unless a target kubeconfig or context is given, for
+// cross-cluster copies.
+func New(opts Options) (*Clients, error) {
+	sourceCfg, err := buildConfig(opts.Kubeconfig, opts.Context, opts.User, opts.Cluster)
 	if err != nil {
 		return nil, fmt.Errorf("source cluster config: %w", err)
 	}
+	opts.TLS.apply(sourceCfg)
 
 	// Determine target config: use target overrides if provided, otherwise same as source.
 	var targetCfg *rest.Config
-	if targetKubeconfig != "" || targetContext != "" {
-		kc := kubeconfig
-		if targetKubeconfig != "" {
-			kc = targetKubeconfig
-		}
-		ctx := sourceContext
-		if targetContext != "" {
-			ctx = targetContext
-		}
-		targetCfg, err = buildConfig(kc, ctx)
+	separateTarget := opts.targetOverridden()
+	if separateTarget {
+		kc, ctx, user, cluster := opts.target()
+		targetCfg, err = buildConfig(kc, ctx, user, cluster)
 		if err != nil {
 			return nil, fmt.Errorf("target cluster config: %w", err)
 		}
 	} else {
-		targetCfg = sourceCfg
+		targetCfg = rest.CopyConfig(sourceCfg)
+	}
+
+	opts.TargetTLS.apply(targetCfg)
+	opts.apply(sourceCfg, opts.QPS, opts.Burst)
+	opts.apply(targetCfg, opts.TargetQPS, opts.TargetBurst)
+
+	c := &Clients{SameCluster: sameServer(sourceCfg.Host, targetCfg.Host), Requests: &RequestCounter{}}
+	c.SourceServer, c.SourceUser = sourceCfg.Host, kubeconfigUser(opts.Kubeconfig, opts.Context, opts.User)
+	c.TargetServer, c.TargetUser = targetCfg.Host, c.SourceUser
+	if separateTarget {
+		kc, ctx, user, _ := opts.target()
+		c.TargetUser = kubeconfigUser(kc, ctx, user)
+	}
+	c.Requests.instrument(sourceCfg)
+	c.Requests.instrument(targetCfg)
+	c.SourceConfig, c.TargetConfig = sourceCfg, targetCfg
+	c.SourceDynamic, c.SourceMapper, c.SourceDiscovery, c.SourceTyped, err = buildClients(sourceCfg)
+	if err != nil {
+		return nil, fmt.Errorf("source %w", err)
+	}
+	if !separateTarget {
+		// Same cluster and credentials: share discovery, so it happens once.
+		// The target still gets its own clients for its rate limits.
+		c.TargetMapper, c.TargetDiscovery = c.SourceMapper, c.SourceDiscovery
+		if c.TargetDynamic, err = dynamic.NewForConfig(targetCfg); err != nil {
+			return nil, fmt.Errorf("target dynamic client: %w", err)
+		}
+		if c.TargetTyped, err = kubernetes.NewForConfig(targetCfg); err != nil {
+			return nil, fmt.Errorf("target client: %w", err)
+		}
+		return c, nil
+	}
+	c.TargetDynamic, c.TargetMapper, c.TargetDiscovery, c.TargetTyped, err = buildClients(targetCfg)
+	if err != nil {
+		return nil, fmt.Errorf("target %w", err)
+	}
+	return c, nil
+}
+
+// NewSourceOnly creates Clients for the source cluster only, leaving every
+// target field nil. It is for runs that never talk to a target, such as
+// exporting sanitized manifests.
+func NewSourceOnly(opts Options) (*Clients, error) {
+	sourceCfg, err := buildConfig(opts.Kubeconfig, opts.Context, opts.User, opts.Cluster)
+	if err != nil {
+		return nil, fmt.Errorf("source cluster config: %w", err)
+	}
+	opts.TLS.apply(sourceCfg)
+	opts.apply(sourceCfg, opts.QPS, opts.Burst)
+
+	c := &Clients{Requests: &RequestCounter{}}
+	c.SourceServer, c.SourceUser = sourceCfg.Host, kubeconfigUser(opts.Kubeconfig, opts.Context, opts.User)
+	c.Requests.instrument(sourceCfg)
+	c.SourceDynamic, c.SourceMapper, c.SourceDiscovery, c.SourceTyped, err = buildClients(sourceCfg)
+	if err != nil {
+		return nil, fmt.Errorf("source %w", err)
+	}
+	return c, nil
+}
+
+// NewTargetOnly creates Clients for the target cluster only, leaving every
+// source field nil. It is for runs whose objects come from elsewhere, such as
+// importing a bundle.
+func NewTargetOnly(opts Options) (*Clients, error) {
+	kc, ctx, user, cluster := opts.target()
+	targetCfg, err := buildConfig(kc, ctx, user, cluster)
+	if err != nil {
+		return nil, fmt.Errorf("target cluster config: %w", err)
+	}
+	if opts.targetOverridden() {
+		opts.TargetTLS.apply(targetCfg)
+	} else {
+		opts.TLS.apply(targetCfg)
 	}
+	opts.apply(targetCfg, opts.TargetQPS, opts.TargetBurst)
 
-	srcDyn, err := dynamic.NewForConfig(sourceCfg)
+	c := &Clients{Requests: &RequestCounter{}}
+	c.TargetServer, c.TargetUser = targetCfg.Host, kubeconfigUser(kc, ctx, user)
+	c.Requests.instrument(targetCfg)
+	c.TargetDynamic, c.TargetMapper, c.TargetDiscovery, c.TargetTyped, err = buildClients(targetCfg)
 	if err != nil {
-		return nil, fmt.Errorf("source dynamic client: %w", err)
+		return nil, fmt.Errorf("target %w", err)
 	}
+	return c, nil
+}
+
+// targetOverridden reports whether any target connection setting is given.
+func (o Options) targetOverridden() bool {
+	return o.TargetKubeconfig != "" || o.TargetContext != "" || o.TargetUser != "" || o.TargetCluster != "" ||
+		o.TargetTLS != TLSOptions{}
+}
 
-	srcMapper, err := buildMapper(sourceCfg)
+// target returns the kubeconfig, context, user, and cluster of the target,
+// falling back to the source settings as documented on Options.
+func (o Options) target() (kubeconfig, context, user, cluster string) {
+	kubeconfig, context, user, cluster = o.TargetKubeconfig, o.TargetContext, o.TargetUser, o.TargetCluster
+	if kubeconfig == "" {
+		kubeconfig = o.Kubeconfig
+	}
+	if context == "" {
+		context = o.Context
+	}
+	if o.TargetKubeconfig == "" && o.TargetContext == "" {
+		if user == "" {
+			user = o.User
+		}
+		if cluster == "" {
+			cluster = o.Cluster
+		}
+	}
+	return kubeconfig, context, user, cluster
+}
+
+// apply sets the rate limits and timeout on cfg. qps and burst fall back to
+// the source settings when zero.
+func (o Options) apply(cfg *rest.Config, qps float32, burst int) {
+	if qps == 0 {
+		qps = o.QPS
+	}
+	if burst == 0 {
+		burst = o.Burst
+	}
+	if qps > 0 {
+		cfg.QPS = qps
+	}
+	if burst > 0 {
+		cfg.Burst = burst
+	}
+	cfg.Timeout = o.Timeout
+}
+
+// buildClients creates the dynamic client, REST mapper, discovery client, and
+// typed client for one cluster. Errors name the client that failed.
+// Discovery is cached in memory and only happens when the mapper or the
+// discovery client is first used.
+func buildClients(cfg *rest.Config) (dynamic.Interface, meta.RESTMapper, discovery.DiscoveryInterface, kubernetes.Interface, error) {
+	dyn, err := dynamic.NewForConfig(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("source REST mapper: %w", err)
+		return nil, nil, nil, nil, fmt.Errorf("dynamic client: %w", err)
 	}
 
-	tgtDyn, err := dynamic.NewForConfig(targetCfg)
+	disc, err := discovery.NewDiscoveryClientForConfig(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("target dynamic client: %w", err)
+		return nil, nil, nil, nil, fmt.Errorf("discovery client: %w", err)
 	}
+	cached := memory.NewMemCacheClient(disc)
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(cached)
 
-	tgtMapper, err := buildMapper(targetCfg)
+	typed, err := kubernetes.NewForConfig(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("target REST mapper: %w", err)
+		return nil, nil, nil, nil, fmt.Errorf("client: %w", err)
 	}
 
-	return &Clients{
-		SourceDynamic: srcDyn,
-		SourceMapper:  srcMapper,
-		TargetDynamic: tgtDyn,
-		TargetMapper:  tgtMapper,
-	}, nil
+	return dyn, mapper, cached, typed, nil
+}
+
+// kubeconfigUser returns the name of the kubeconfig user a context
+// authenticates as: user when it overrides the context's, or empty when the
+// kubeconfig cannot be read.
+func kubeconfigUser(kubeconfig, context, user string) string {
+	if user != "" {
+		return user
+	}
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if kubeconfig != "" {
+		rules.ExplicitPath = kubeconfig
+	}
+	raw, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{}).RawConfig()
+	if err != nil {
+		return ""
+	}
+	if context == "" {
+		context = raw.CurrentContext
+	}
+	if c, ok := raw.Contexts[context]; ok {
+		return c.AuthInfo
+	}
+	return ""
 }
 
-func buildConfig(kubeconfig, context string) (*rest.Config, error) {
+func buildConfig(kubeconfig, context, user, cluster string) (*rest.Config, error) {
 	rules := clientcmd.NewDefaultClientConfigLoadingRules()
 	if kubeconfig != "" {
 		rules.ExplicitPath = kubeconfig
@@ -86,14 +319,41 @@ func buildConfig(kubeconfig, context string) (*rest.Config, error) {
 	if context != "" {
 		overrides.CurrentContext = context
 	}
+	overrides.Context.AuthInfo = user
+	overrides.Context.Cluster = cluster
 	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides).ClientConfig()
 }
 
-func buildMapper(cfg *rest.Config) (meta.RESTMapper, error) {
-	dc, err := discovery.NewDiscoveryClientForConfig(cfg)
-	if err != nil {
-		return nil, err
+// sameServer reports whether two API server URLs name the same server,
+// ignoring case, an explicit default port, and a trailing slash, so that
+// contexts spelling one cluster's URL differently still compare equal.
+func sameServer(a, b string) bool {
+	if a == b {
+		return true
+	}
+	ua, errA := url.Parse(a)
+	ub, errB := url.Parse(b)
+	if errA != nil || errB != nil || ua.Host == "" || ub.Host == "" {
+		return false
 	}
+	return strings.EqualFold(ua.Scheme, ub.Scheme) &&
+		strings.EqualFold(ua.Hostname(), ub.Hostname()) &&
+		serverPort(ua) == serverPort(ub) &&
+		strings.TrimSuffix(ua.Path, "/") == strings.TrimSuffix(ub.Path, "/")
+}
+
+// serverPort returns the port of an API server URL, defaulting by scheme.
+func serverPort(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return port
+	}
+	if strings.EqualFold(u.Scheme, "http") {
+		return "80"
+	}
+	return "443"
+}
+
+func buildMapper(dc discovery.DiscoveryInterface) (meta.RESTMapper, error) {
 	groups, err := restmapper.GetAPIGroupResources(dc)
 	if err != nil {
 		return nil, err
@@ -101,6 +361,28 @@ func buildMapper(cfg *rest.Config) (meta.RESTMapper, error) {
 	return restmapper.NewDiscoveryRESTMapper(groups), nil
 }
 
+// ServerVersions returns the Kubernetes versions reported by the source and
+// target API servers' /version endpoints. A version that cannot be fetched or
+// parsed is returned as nil.
+func (c *Clients) ServerVersions() (source, target *version.Version) {
+	return serverVersion(c.SourceDiscovery), serverVersion(c.TargetDiscovery)
+}
+
+func serverVersion(dc discovery.DiscoveryInterface) *version.Version {
+	if dc == nil {
+		return nil
+	}
+	info, err := dc.ServerVersion()
+	if err != nil {
+		return nil
+	}
+	v, err := version.ParseGeneric(info.GitVersion)
+	if err != nil {
+		return nil
+	}
+	return v
+}
+
 // ResolvedResource holds a resolved GVR and the proper Kind name from the API server.
 type ResolvedResource struct {
 	GVR        schema.GroupVersionResource
@@ -116,8 +398,13 @@ func (c *Clients) Resolve(resource string) (ResolvedResource, error) {
 	// - plural/singular ("deployment" / "deployments")
 	// - short names ("deploy", "svc", "cm", "po", etc.)
 	// - resource.group format ("deployments.apps")
+	// - names several groups serve ("gateways"), which fail listing each
 	// - CRDs and any other API-server-registered resource
-	gvr, err := resolveGVR(c.SourceMapper, resource)
+	gvr, err := resolveGVR(c.SourceMapper, c.SourceDiscovery, resource)
+	var ambiguous *ambiguousResourceError
+	if errors.As(err, &ambiguous) {
+		return ResolvedResource{}, err
+	}
 	if err != nil {
 		return ResolvedResource{}, fmt.Errorf("cannot resolve resource type %q: %w\n    Run 'kubectl api-resources' to see available types.", resource, err)
 	}
@@ -144,8 +431,10 @@ func isNamespaced(mapper meta.RESTMapper, gvr schema.GroupVersionResource) bool
 }
 
 // resolveGVR uses the REST mapper to convert a user-provided resource string
-// to a fully qualified GroupVersionResource.
-func resolveGVR(mapper meta.RESTMapper, resource string) (schema.GroupVersionResource, error) {
+// to a fully qualified GroupVersionResource. A name that several API groups
+// serve resolves to the core group's resource, as kubectl's does, or fails
+// with an ambiguousResourceError when the core group does not serve it.
+func resolveGVR(mapper meta.RESTMapper, dc discovery.DiscoveryInterface, resource string) (schema.GroupVersionResource, error) {
 	// Try as a fully qualified resource first (handles "deployments.apps" format)
 	fullySpecifiedGVR, groupResource := schema.ParseResourceArg(resource)
 	if fullySpecifiedGVR != nil {
@@ -155,7 +444,20 @@ func resolveGVR(mapper meta.RESTMapper, resource string) (schema.GroupVersionRes
 		}
 	}
 
-	// Use the mapper to resolve short names, plural, singular
+	// The mapper picks one group by priority when several serve the name,
+	// and does not expand short names, so match discovery first
+	if candidates := resourceCandidates(dc, groupResource); len(candidates) == 1 {
+		return candidates[0], nil
+	} else if len(candidates) > 1 {
+		for _, gvr := range candidates {
+			if gvr.Group == "" {
+				return gvr, nil
+			}
+		}
+		return schema.GroupVersionResource{}, &ambiguousResourceError{resource: resource, candidates: candidates}
+	}
+
+	// Use the mapper to resolve plural and singular names it knows
 	gvr, err := mapper.ResourceFor(groupResource.WithVersion(""))
 	if err != nil {
 		return schema.GroupVersionResource{}, err
@@ -164,6 +466,54 @@ func resolveGVR(mapper meta.RESTMapper, resource string) (schema.GroupVersionRes
 	return gvr, nil
 }
 
+// resourceCandidates returns the resources, in their preferred version, whose
+// plural, singular or short name is gr.Resource, in gr.Group when it is set.
+// It returns nil when discovery fails entirely; groups whose discovery
+// fails are skipped.
+func resourceCandidates(dc discovery.DiscoveryInterface, gr schema.GroupResource) []schema.GroupVersionResource {
+	if dc == nil {
+		return nil
+	}
+	lists, err := dc.ServerPreferredResources()
+	if err != nil && len(lists) == 0 {
+		return nil
+	}
+	name := strings.ToLower(gr.Resource)
+
+	var candidates []schema.GroupVersionResource
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil || (gr.Group != "" && gv.Group != gr.Group) {
+			continue
+		}
+		for _, r := range list.APIResources {
+			if strings.Contains(r.Name, "/") {
+				continue // subresource
+			}
+			if r.Name == name || r.SingularName == name || slices.Contains(r.ShortNames, name) {
+				candidates = append(candidates, gv.WithResource(r.Name))
+			}
+		}
+	}
+	return candidates
+}
+
+// ambiguousResourceError reports a resource name that several API groups
+// serve, none of them the core group.
+type ambiguousResourceError struct {
+	resource   string
+	candidates []schema.GroupVersionResource
+}
+
+func (e *ambiguousResourceError) Error() string {
+	var options []string
+	for _, gvr := range e.candidates {
+		options = append(options, gvr.GroupResource().String())
+	}
+	sort.Strings(options)
+	return fmt.Sprintf("resource type %q is ambiguous, it matches: %s\n    Specify one as <resource>.<group>, e.g. %q.", e.resource, strings.Join(options, ", "), options[0])
+}
+
 // kindForGVR looks up the Kind string for a GVR from the REST mapper.
 func kindForGVR(mapper meta.RESTMapper, gvr schema.GroupVersionResource) string {
 	gvk, err := mapper.KindFor(gvr)
@@ -177,3 +527,42 @@ func kindForGVR(mapper meta.RESTMapper, gvr schema.GroupVersionResource) string
 	}
 	return gvk.Kind
 }
+
+// NamespacedResources returns every namespaced resource type the source
+// cluster serves that can be listed and deleted, in its preferred version.
+// Groups whose discovery fails are skipped.
+func (c *Clients) NamespacedResources() ([]ResolvedResource, error) {
+	return namespacedResources(c.SourceDiscovery)
+}
+
+// TargetNamespacedResources is NamespacedResources for the target cluster.
+func (c *Clients) TargetNamespacedResources() ([]ResolvedResource, error) {
+	return namespacedResources(c.TargetDiscovery)
+}
+
+func namespacedResources(dc discovery.DiscoveryInterface) ([]ResolvedResource, error) {
+	lists, err := dc.ServerPreferredNamespacedResources()
+	if err != nil && len(lists) == 0 {
+		return nil, fmt.Errorf("listing resource types: %w", err)
+	}
+	lists = discovery.FilteredBy(discovery.SupportsAllVerbs{Verbs: []string{"list", "delete"}}, lists)
+
+	var resources []ResolvedResource
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, r := range list.APIResources {
+			if strings.Contains(r.Name, "/") {
+				continue // subresource
+			}
+			resources = append(resources, ResolvedResource{
+				GVR:        gv.WithResource(r.Name),
+				Kind:       r.Kind,
+				Namespaced: true,
+			})
+		}
+	}
+	return resources, nil
+}
@@ -0,0 +1,131 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// discoveryServer serves the discovery documents of a cluster with core
+// ConfigMaps and apps Deployments, and counts the requests for each path.
+type discoveryServer struct {
+	mu       sync.Mutex
+	requests map[string]int
+}
+
+func (s *discoveryServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests[r.URL.Path]++
+	s.mu.Unlock()
+
+	var doc interface{}
+	switch r.URL.Path {
+	case "/api":
+		doc = metav1.APIVersions{TypeMeta: metav1.TypeMeta{Kind: "APIVersions"}, Versions: []string{"v1"}}
+	case "/apis":
+		apps := metav1.GroupVersionForDiscovery{GroupVersion: "apps/v1", Version: "v1"}
+		doc = metav1.APIGroupList{
+			TypeMeta: metav1.TypeMeta{Kind: "APIGroupList", APIVersion: "v1"},
+			Groups:   []metav1.APIGroup{{Name: "apps", Versions: []metav1.GroupVersionForDiscovery{apps}, PreferredVersion: apps}},
+		}
+	case "/api/v1":
+		doc = metav1.APIResourceList{GroupVersion: "v1", APIResources: []metav1.APIResource{
+			{Name: "configmaps", SingularName: "configmap", Kind: "ConfigMap", Namespaced: true, ShortNames: []string{"cm"}, Verbs: metav1.Verbs{"get", "list"}},
+		}}
+	case "/apis/apps/v1":
+		doc = metav1.APIResourceList{GroupVersion: "apps/v1", APIResources: []metav1.APIResource{
+			{Name: "deployments", SingularName: "deployment", Kind: "Deployment", Namespaced: true, ShortNames: []string{"deploy"}, Verbs: metav1.Verbs{"get", "list"}},
+		}}
+	case "/version":
+		doc = map[string]string{"gitVersion": "v1.33.0"}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(doc)
+}
+
+// count returns how many requests were made for path, or for all paths
+// when path is empty.
+func (s *discoveryServer) count(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if path != "" {
+		return s.requests[path]
+	}
+	var n int
+	for _, c := range s.requests {
+		n += c
+	}
+	return n
+}
+
+// kubeconfigFor writes a kubeconfig whose current context points at server.
+func kubeconfigFor(t *testing.T, server string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config")
+	config := `apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: ` + server + `
+users:
+- name: test
+  user:
+    token: secret
+contexts:
+- name: test
+  context: {cluster: test, user: test}
+current-context: test
+`
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestNewDiscoversOnceOnFirstUse(t *testing.T) {
+	server := &discoveryServer{requests: map[string]int{}}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	clients, err := New(Options{Kubeconfig: kubeconfigFor(t, ts.URL)})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if n := server.count(""); n != 0 {
+		t.Fatalf("New sent %d requests, want discovery deferred to first use", n)
+	}
+
+	for _, resource := range []string{"deployment", "deploy", "deployments.apps", "cm", "configmaps"} {
+		if _, err := clients.Resolve(resource); err != nil {
+			t.Fatalf("Resolve(%q): %v", resource, err)
+		}
+	}
+	// The target is the source cluster, so it shares the source's discovery
+	deployments := schema.GroupKind{Group: "apps", Kind: "Deployment"}
+	if _, err := clients.TargetMapper.RESTMapping(deployments, "v1"); err != nil {
+		t.Fatalf("target RESTMapping: %v", err)
+	}
+	if _, err := clients.TargetDiscovery.ServerPreferredResources(); err != nil {
+		t.Fatalf("target ServerPreferredResources: %v", err)
+	}
+
+	for _, path := range []string{"/api", "/apis", "/api/v1", "/apis/apps/v1"} {
+		if n := server.count(path); n != 1 {
+			t.Errorf("%d requests for %s, want 1", n, path)
+		}
+	}
+	if got := clients.Requests.Count(); got != int64(server.count("")) {
+		t.Errorf("Requests counted %d, server saw %d", got, server.count(""))
+	}
+}
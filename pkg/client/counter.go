@@ -0,0 +1,38 @@
+package client
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"k8s.io/client-go/rest"
+)
+
+// RequestCounter counts the API requests sent to either cluster.
+type RequestCounter struct {
+	n atomic.Int64
+}
+
+// Count returns the number of requests sent so far.
+func (c *RequestCounter) Count() int64 {
+	if c == nil {
+		return 0
+	}
+	return c.n.Load()
+}
+
+// instrument makes every client built from cfg count its requests.
+func (c *RequestCounter) instrument(cfg *rest.Config) {
+	cfg.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return countingTransport{next: rt, counter: c}
+	})
+}
+
+type countingTransport struct {
+	next    http.RoundTripper
+	counter *RequestCounter
+}
+
+func (t countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.counter.n.Add(1)
+	return t.next.RoundTrip(req)
+}
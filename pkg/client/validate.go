@@ -0,0 +1,135 @@
+package client
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// ValidateContexts checks, before anything connects, that the kubeconfig
+// files, contexts, users, and clusters in opts exist, so a typo is reported
+// up front with the closest matching names instead of from deep inside
+// client-go.
+func ValidateContexts(opts Options) error {
+	source := kubeconfigRefs{
+		kubeconfig: opts.Kubeconfig, context: opts.Context, user: opts.User, cluster: opts.Cluster,
+		flags: [4]string{"--kubeconfig", "--context", "--user", "--cluster"},
+	}
+	if err := source.validate(); err != nil {
+		return err
+	}
+	if !opts.targetOverridden() {
+		return nil
+	}
+	target := kubeconfigRefs{flags: [4]string{"--to-kubeconfig", "--to-context", "--to-user", "--to-cluster"}}
+	target.kubeconfig, target.context, target.user, target.cluster = opts.target()
+	return target.validate()
+}
+
+// kubeconfigRefs are the kubeconfig entries one side of a copy uses, with
+// the flags that set them for error messages.
+type kubeconfigRefs struct {
+	kubeconfig, context, user, cluster string
+	flags                              [4]string // kubeconfig, context, user, cluster
+}
+
+func (r kubeconfigRefs) validate() error {
+	if r.kubeconfig != "" {
+		if _, err := os.Stat(r.kubeconfig); err != nil {
+			return fmt.Errorf("%s %q: %w", r.flags[0], r.kubeconfig, err)
+		}
+	}
+	if r.context == "" && r.user == "" && r.cluster == "" {
+		return nil
+	}
+
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if r.kubeconfig != "" {
+		rules.ExplicitPath = r.kubeconfig
+	}
+	config, err := rules.Load()
+	if err != nil {
+		return fmt.Errorf("loading kubeconfig: %w", err)
+	}
+	if err := checkEntry(r.flags[1], r.context, "contexts", keysOf(config.Contexts)); err != nil {
+		return err
+	}
+	if err := checkEntry(r.flags[2], r.user, "users", keysOf(config.AuthInfos)); err != nil {
+		return err
+	}
+	return checkEntry(r.flags[3], r.cluster, "clusters", keysOf(config.Clusters))
+}
+
+// checkEntry reports a name missing from the kubeconfig entries names, with
+// the closest matches and the full list.
+func checkEntry(flag, name, what string, names []string) error {
+	if name == "" || slices.Contains(names, name) {
+		return nil
+	}
+	msg := fmt.Sprintf("%s %q does not exist in the kubeconfig", flag, name)
+	if matches := closestMatches(name, names); len(matches) > 0 {
+		msg += fmt.Sprintf("\n    Did you mean: %s?", strings.Join(matches, ", "))
+	}
+	if len(names) > 0 {
+		msg += fmt.Sprintf("\n    Available %s: %s", what, strings.Join(names, ", "))
+	}
+	return fmt.Errorf("%s", msg)
+}
+
+func keysOf[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// closestMatches returns up to three candidates within a few edits of s,
+// closest first.
+func closestMatches(s string, candidates []string) []string {
+	limit := max(2, len(s)/3)
+	type match struct {
+		name     string
+		distance int
+	}
+	var matches []match
+	for _, c := range candidates {
+		if d := editDistance(strings.ToLower(s), strings.ToLower(c)); d <= limit {
+			matches = append(matches, match{c, d})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].distance < matches[j].distance })
+
+	var names []string
+	for i := 0; i < len(matches) && i < 3; i++ {
+		names = append(names, matches[i].name)
+	}
+	return names
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/a13x22/kube-copy/pkg/audit"
+	"github.com/a13x22/kube-copy/pkg/client"
+	"github.com/a13x22/kube-copy/pkg/copier"
+	"github.com/a13x22/kube-copy/pkg/output"
+	"github.com/a13x22/kube-copy/pkg/provenance"
+)
+
+// CleanupOptions holds the flags of the cleanup subcommand.
+type CleanupOptions struct {
+	Kubeconfig string
+	Context    string
+	Namespace  string
+
+	SourceNamespace string
+	SourceCluster   string
+
+	AuditLog string // file every deletion is appended to, see Options.AuditLog
+
+	DryRun bool
+	Yes    bool
+	Quiet  bool
+
+	stdout, stderr io.Writer // the command's streams, see Options
+}
+
+// NewCleanupCommand creates the cleanup subcommand, which deletes resources
+// that earlier copies created.
+func NewCleanupCommand() *cobra.Command {
+	o := &CleanupOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "cleanup --to-namespace <namespace> [flags]",
+		Short: "Delete resources that earlier copies created in a namespace",
+		Long: `Delete the resources in a namespace that kubectl copy created, as marked by
+its provenance label (` + provenance.LabelManaged + `) and annotations. Resources
+without them are never touched. Ingresses are deleted first and configuration
+last, the reverse of the order copies create them in.`,
+		Example: `  # Remove everything copied from prod into staging
+  kubectl copy cleanup --to-namespace staging --source-namespace prod
+
+  # Preview what would be deleted
+  kubectl copy cleanup --to-namespace staging --dry-run`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Args:          cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return o.Complete(cmd)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run()
+		},
+	}
+
+	cmd.Flags().StringVar(&o.Kubeconfig, "to-kubeconfig", "", "kubeconfig file of the cluster to clean up")
+	cmd.Flags().StringVar(&o.Context, "to-context", "", "kubeconfig context of the cluster to clean up")
+	cmd.Flags().StringVar(&o.Namespace, "to-namespace", "", "namespace to clean up (defaults to the context's namespace)")
+	cmd.Flags().StringVar(&o.Namespace, "to-ns", "", "namespace to clean up (alias for --to-namespace)")
+	cmd.Flags().StringVar(&o.SourceNamespace, "source-namespace", "", "only delete copies of resources from this namespace")
+	cmd.Flags().StringVar(&o.SourceCluster, "source-cluster", "", "only delete copies from this source kubeconfig context")
+	cmd.Flags().StringVar(&o.AuditLog, "audit-log", "", "append a JSON line to this file for every delete sent to the cluster, with the server, user, object, and outcome")
+	cmd.Flags().BoolVar(&o.DryRun, "dry-run", false, "show what would be deleted without deleting")
+	cmd.Flags().BoolVarP(&o.Yes, "yes", "y", false, "skip confirmation prompt")
+	cmd.Flags().BoolVarP(&o.Quiet, "quiet", "q", false, "suppress progress output")
+
+	return cmd
+}
+
+// Complete fills in defaults.
+func (o *CleanupOptions) Complete(cmd *cobra.Command) error {
+	o.stdout, o.stderr = cmd.OutOrStdout(), cmd.ErrOrStderr()
+	if o.Namespace == "" {
+		o.Namespace = getDefaultNamespace(o.Kubeconfig, o.Context)
+	}
+	return nil
+}
+
+// Run plans the cleanup, shows it, and deletes after confirmation.
+func (o *CleanupOptions) Run() (err error) {
+	// The plan and results tables are the output, see Options.Run
+	prog := output.NewProgress(o.Quiet)
+	stdout, stderr := prog.Writer(o.stdout), prog.Writer(o.stderr)
+	prog.Connecting()
+
+	// Cleanup talks to a single cluster, built as the "source" clients
+	// Validated as the target, which is what the flags name
+	if err := client.ValidateContexts(client.Options{TargetKubeconfig: o.Kubeconfig, TargetContext: o.Context}); err != nil {
+		prog.Clear()
+		return err
+	}
+	clients, err := client.NewSourceOnly(client.Options{Kubeconfig: o.Kubeconfig, Context: o.Context})
+	if err != nil {
+		prog.Clear()
+		return fmt.Errorf("cannot connect to cluster: %w\n    Check your kubeconfig and network connectivity.", err)
+	}
+	resources, err := clients.NamespacedResources()
+	if err != nil {
+		prog.Clear()
+		return err
+	}
+	types := make([]copier.ResourceRef, len(resources))
+	for i, r := range resources {
+		types[i] = copier.ResourceRef{GVR: r.GVR, Kind: r.Kind, Namespaced: true}
+	}
+
+	c, err := copier.New(nil, clients.SourceDynamic, copier.WithProgress(prog))
+	if err != nil {
+		prog.Clear()
+		return err
+	}
+	if o.AuditLog != "" {
+		auditLog, err := audit.Open(o.AuditLog)
+		if err != nil {
+			prog.Clear()
+			return fmt.Errorf("cannot open audit log: %w", err)
+		}
+		defer func() {
+			if closeErr := auditLog.Close(); closeErr != nil && err == nil {
+				err = fmt.Errorf("writing audit log %s: %w", o.AuditLog, closeErr)
+			}
+		}()
+		c.Audit = auditLog.Recorder(audit.Cluster{}, audit.Cluster{Server: clients.SourceServer, User: clients.SourceUser})
+	}
+	ctx, stop := interruptible(context.TODO(), prog)
+	defer stop()
+
+	planned, err := c.PlanCleanup(ctx, types, o.Namespace, copier.CleanupFilter{
+		SourceCluster:   o.SourceCluster,
+		SourceNamespace: o.SourceNamespace,
+	})
+	prog.Clear()
+	if err != nil {
+		return err
+	}
+	if len(planned) == 0 {
+		fmt.Fprintf(stderr, "\n  No copied resources found in namespace %q.\n\n", o.Namespace)
+		return nil
+	}
+
+	output.PrintPlan(stdout, stdout, planned, "table", nil)
+	if o.DryRun {
+		return nil
+	}
+	if !o.Yes && !askConfirmation(ctx, prog) {
+		fmt.Fprintf(stderr, "  Aborted.\n\n")
+		return nil
+	}
+	fmt.Fprintln(stdout)
+
+	c.ApplyCleanup(ctx, planned)
+	prog.Clear()
+	if err := output.PrintResults(stdout, stdout, planned, "table", nil); err != nil {
+		return err
+	}
+	if ctx.Err() != nil {
+		return ErrCanceled
+	}
+	return nil
+}
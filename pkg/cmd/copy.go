@@ -1,54 +1,181 @@
 package cmd
 
 import (
-	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"path"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/tools/clientcmd"
 
+	"github.com/a13x22/kube-copy/pkg/audit"
+	"github.com/a13x22/kube-copy/pkg/bundle"
 	"github.com/a13x22/kube-copy/pkg/client"
+	"github.com/a13x22/kube-copy/pkg/conflict"
 	"github.com/a13x22/kube-copy/pkg/copier"
 	"github.com/a13x22/kube-copy/pkg/discovery"
+	"github.com/a13x22/kube-copy/pkg/kubecopy"
 	"github.com/a13x22/kube-copy/pkg/output"
+	"github.com/a13x22/kube-copy/pkg/provenance"
+	"github.com/a13x22/kube-copy/pkg/sanitizer"
 )
 
+// ErrCanceled is returned by Run when the apply phase was interrupted. The
+// results of whatever completed have been printed by then.
+var ErrCanceled = errors.New("interrupted: the copy is incomplete")
+
+// sourceNodeName is the value of --preserve-node-name given without one:
+// keep the node of the source Pod. It is not a valid node name.
+const sourceNodeName = "*"
+
 // Options holds all flags and parsed arguments for the copy command.
 type Options struct {
 	// Source identification
 	SourceKubeconfig string
 	SourceContext    string
+	SourceUser       string // kubeconfig user overriding the source context's
+	SourceCluster    string // kubeconfig cluster overriding the source context's
 	SourceNamespace  string
 	ResourceArg      string // raw argument like "deployment/myapp"
 
 	// Parsed from ResourceArg
 	ResourceKind string
 	ResourceName string
+	pick         bool // no name was given: Run asks which resources of ResourceKind to copy
 
 	// Target overrides
 	ToNamespace  string
 	ToName       string
 	ToContext    string
 	ToKubeconfig string
+	ToUser       string // kubeconfig user overriding the target context's
+	ToCluster    string // kubeconfig cluster overriding the target context's
+
+	// Client tuning
+	QPS            float32       // requests per second to each cluster
+	Burst          int           // request burst to each cluster
+	ToQPS          float32       // target override for QPS; 0 uses QPS
+	ToBurst        int           // target override for Burst; 0 uses Burst
+	RequestTimeout time.Duration // per-request timeout; 0 means none
+
+	// TLS verification overrides
+	InsecureSkipTLSVerify   bool
+	CertificateAuthority    string
+	ToInsecureSkipTLSVerify bool
+	ToCertificateAuthority  string
 
 	// Behavior flags
-	Recursive  bool
-	DryRun     bool
-	Yes        bool   // skip confirmation prompt
-	Quiet      bool   // suppress progress output
-	OnConflict string // "skip", "warn", "overwrite"
-	Output     string // "table", "yaml", "json"
+	Recursive    bool
+	DryRun       bool
+	Yes          bool   // skip confirmation prompt
+	Quiet        bool   // suppress progress output
+	OnConflict   string // "skip", "warn", "overwrite", "prompt"
+	SkipExisting bool   // report resources that only already exist as "exists", not "skip"
+	Output       string // "table", "wide", "yaml", "json", "report", "tree", "dot"
+	List         bool   // -o yaml: wrap objects in a v1 List instead of separate documents
+	Items        bool   // -o json: print {"items": [...]} instead of a v1 List
+	Objects      bool   // -o report: include the sanitized objects
+
+	DeletePropagation string        // how overwrites delete: "foreground", "background", "orphan"
+	DeleteTimeout     time.Duration // how long an overwrite waits for the deletion; 0 derives it
+
+	MaxWarnings int  // warning and conflict lines shown below the plan table; 0 shows all
+	NoPager     bool // never show a long plan through $PAGER
+
+	ValidateWithServer bool   // server-side dry-run create during planning
+	FailOn             string // "error", "warning": lowest conflict severity that blocks a create
+	Force              bool   // create even when blocking conflicts are reported
+	SkipConflictCheck  bool   // plan without looking at the target cluster
+
+	FollowOwner     bool // copy the top-level controller instead of a managed resource
+	SkipSecrets     bool // leave Secrets out of recursive copies
+	IncludeGateways bool // follow HTTPRoutes to their Gateways during discovery
+	IncludeSTSPVCs  bool // follow StatefulSets to the claims of their volumeClaimTemplates
+
+	NamespaceMap    map[string]string // source namespace -> target namespace for multi-namespace graphs
+	CreateNamespace bool              // create missing target namespaces from their sources
+
+	Parallelism int  // resources created concurrently during apply
+	Atomic      bool // roll back everything created when any create fails
+
+	WithData  bool // copy PersistentVolumeClaim contents after creating the claims
+	ForceData bool // copy data even from ReadWriteOnce claims mounted by running pods
+	WithPV    bool // copy the PersistentVolumes of bound claims, sharing their storage
+
+	Timings bool // print where the time went, per phase
+
+	Verify bool // read created resources back and report what the target changed
+
+	NoProvenance     bool // do not stamp created resources with kubecopy.io/ provenance
+	KeepHelmMetadata bool // keep the release annotations and label of objects installed by Helm
+	Prune            bool // delete earlier copies from the same source that this copy no longer includes
+
+	AuditLog string // file every write to a cluster is appended to, as JSON lines
+
+	Resume  string              // -o report file of an interrupted run to resume
+	resumed []copier.CopyResult // read from Resume
+
+	IgnoreConflicts []string        // raw --ignore-conflicts values
+	ignoredTypes    []conflict.Type // parsed from IgnoreConflicts
+
+	SuppressWarnings []string // warning codes or globs to hide
+
+	Labels            map[string]string // stamped on every created resource
+	Annotations       map[string]string
+	OverwriteMetadata bool // replace existing keys with --label/--annotation values
+	LabelPodTemplates bool // also label workloads' pod templates
+
+	ClearHostPorts    bool   // remove the hostPorts of copied pod specs
+	PreserveNodePorts bool   // keep the nodePorts of copied Services
+	PreserveClusterIP bool   // keep the clusterIP of copied Services
+	PreserveNodeName  string // node to pin copied Pods to; sourceNodeName keeps theirs
+
+	Images  map[string]string  // container name or "*" -> image
+	Env     []string           // raw --env values
+	envVars []sanitizer.EnvVar // parsed from Env
+
+	// Move deletes the sources after a successful copy (the move subcommand)
+	Move              bool
+	KeepSourceSecrets bool
+	Wait              bool
+	WaitTimeout       time.Duration
+
+	// Export writes the plan to a bundle instead of applying it (the export
+	// subcommand); Import applies the bundle at BundlePath (the import
+	// subcommand).
+	Export     bool
+	Import     bool
+	BundlePath string
+	bundle     *bundle.Bundle // read by Run for Import
+
+	// Release copies the Helm release ReleaseName (the release subcommand)
+	Release     bool
+	ReleaseName string
+
+	version string // kubecopy version, from the root command
+
+	stdout, stderr io.Writer // the command's streams, os.Stdout and os.Stderr unless set by tests
+
+	clients *client.Clients // set by tests; built from the connection flags when nil
 }
 
 // NewCopyCommand creates the root cobra command for kubectl-copy.
 func NewCopyCommand() *cobra.Command {
-	o := &Options{}
+	return newCopyCommand(&Options{})
+}
 
+func newCopyCommand(o *Options) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "copy <resource>/<name> [flags]",
+		Use:   "copy (<resource>/<name> | <resource> <name>) [flags]",
 		Short: "Copy Kubernetes resources across namespaces or clusters",
 		Long: `Copy Kubernetes resources intelligently, sanitizing metadata and
 detecting conflicts to avoid broken or duplicate resources.
@@ -60,7 +187,13 @@ Resource can be specified as:
   deployment/myapp              slash-separated
   deployment myapp              space-separated
   deployment.apps/myapp         kubectl-style with API group
-  deploy/myapp                  short alias`,
+  deploy/myapp                  short alias
+  deployment                    on a terminal, pick from a list of them
+
+Dependencies --recursive cannot infer (e.g. a ConfigMap read through the API
+at runtime) can be declared on any resource with an annotation listing
+comma-separated Kind/name entries; Kind may include the API group:
+  kubecopy.io/depends-on: "ConfigMap/feature-flags,Secret/api-keys"`,
 		Example: `  # Copy a deployment to another namespace
   kubectl copy deployment/myapp --to-namespace staging
   kubectl copy deployment myapp --to-namespace staging
@@ -90,33 +223,287 @@ Resource can be specified as:
 		},
 	}
 
+	o.addFlags(cmd)
+	cmd.AddCommand(NewCleanupCommand())
+	cmd.AddCommand(NewMoveCommand())
+	cmd.AddCommand(NewExportCommand())
+	cmd.AddCommand(NewImportCommand())
+	cmd.AddCommand(NewReleaseCommand())
+
+	return cmd
+}
+
+// addFlags registers the flags shared by copy and move.
+func (o *Options) addFlags(cmd *cobra.Command) {
 	// Source flags (standard kubectl flags)
 	cmd.Flags().StringVar(&o.SourceKubeconfig, "kubeconfig", "", "path to the kubeconfig file")
 	cmd.Flags().StringVar(&o.SourceContext, "context", "", "kubeconfig context to use for the source")
+	cmd.Flags().StringVar(&o.SourceUser, "user", "", "kubeconfig user to use for the source instead of the context's")
+	cmd.Flags().StringVar(&o.SourceCluster, "cluster", "", "kubeconfig cluster to use for the source instead of the context's")
+	cmd.Flags().BoolVar(&o.InsecureSkipTLSVerify, "insecure-skip-tls-verify", false, "do not verify the source cluster's TLS certificate (insecure)")
+	cmd.Flags().StringVar(&o.CertificateAuthority, "certificate-authority", "", "CA file to verify the source cluster's certificate with")
 	cmd.Flags().StringVarP(&o.SourceNamespace, "namespace", "n", "", "source namespace (defaults to current context namespace)")
 
 	// Target flags
-	cmd.Flags().StringVar(&o.ToNamespace, "to-namespace", "", "target namespace (defaults to source namespace)")
+	cmd.Flags().StringVar(&o.ToNamespace, "to-namespace", "", "target namespace (defaults to the --to-context namespace, else the source namespace)")
 	cmd.Flags().StringVar(&o.ToNamespace, "to-ns", "", "target namespace (alias for --to-namespace)")
 	cmd.Flags().StringVar(&o.ToName, "to-name", "", "new resource name (required for same-namespace copy)")
 	cmd.Flags().StringVar(&o.ToContext, "to-context", "", "target kubeconfig context (for cross-cluster copy)")
 	cmd.Flags().StringVar(&o.ToKubeconfig, "to-kubeconfig", "", "target kubeconfig file (for cross-cluster copy)")
+	cmd.Flags().StringVar(&o.ToUser, "to-user", "", "kubeconfig user to use for the target instead of the context's, e.g. one with write access")
+	cmd.Flags().StringVar(&o.ToCluster, "to-cluster", "", "kubeconfig cluster to use for the target instead of the context's")
+	cmd.Flags().BoolVar(&o.ToInsecureSkipTLSVerify, "to-insecure-skip-tls-verify", false, "do not verify the target cluster's TLS certificate (insecure)")
+	cmd.Flags().StringVar(&o.ToCertificateAuthority, "to-certificate-authority", "", "CA file to verify the target cluster's certificate with")
+
+	// Client flags
+	cmd.Flags().Float32Var(&o.QPS, "qps", 20, "maximum requests per second to each cluster")
+	cmd.Flags().IntVar(&o.Burst, "burst", 30, "maximum request burst to each cluster")
+	cmd.Flags().Float32Var(&o.ToQPS, "to-qps", 0, "maximum requests per second to the target cluster (defaults to --qps)")
+	cmd.Flags().IntVar(&o.ToBurst, "to-burst", 0, "maximum request burst to the target cluster (defaults to --burst)")
+	cmd.Flags().DurationVar(&o.RequestTimeout, "request-timeout", 0, "timeout for each API request, e.g. 30s (0 means no timeout)")
 
 	// Behavior flags
 	cmd.Flags().BoolVarP(&o.Recursive, "recursive", "r", false, "copy the full dependency graph")
 	cmd.Flags().BoolVar(&o.DryRun, "dry-run", false, "preview what would be copied without making changes")
 	cmd.Flags().BoolVarP(&o.Yes, "yes", "y", false, "skip confirmation prompt")
 	cmd.Flags().BoolVarP(&o.Quiet, "quiet", "q", false, "suppress progress output")
-	cmd.Flags().StringVar(&o.OnConflict, "on-conflict", "skip", "conflict strategy: skip, warn, overwrite")
-	cmd.Flags().StringVarP(&o.Output, "output", "o", "table", "output format: table, yaml, json")
-
-	return cmd
+	cmd.Flags().StringVar(&o.OnConflict, "on-conflict", "skip", "conflict strategy: skip, warn, overwrite, prompt (ask about each existing resource)")
+	cmd.Flags().StringVar(&o.DeletePropagation, "delete-propagation", "foreground", "how overwrites delete existing resources: foreground (wait for dependents), background, orphan")
+	cmd.Flags().DurationVar(&o.DeleteTimeout, "delete-timeout", 0, "how long an overwrite waits for the existing resource to be deleted (0 allows 60s plus the termination grace period of the pods the deletion waits for)")
+	cmd.Flags().BoolVar(&o.SkipExisting, "skip-existing", false, "treat resources that already exist in the target, with no other conflict, as expected: leave them alone and report them as \"exists\" rather than skipped")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", "table", "output format: table, wide, yaml, json, report, tree, dot (wide does not shorten names to fit the terminal; report is JSON describing each resource's action, conflicts, and warnings; tree and dot print the dependency graph of --recursive)")
+	cmd.Flags().BoolVar(&o.List, "list", false, "with -o yaml, wrap the objects in a v1 List instead of ---separated documents")
+	cmd.Flags().BoolVar(&o.Items, "items", false, "with -o json, print {\"items\": [...]} instead of a v1 List")
+	cmd.Flags().BoolVar(&o.Objects, "objects", false, "with -o report, include each sanitized object")
+	cmd.Flags().IntVar(&o.MaxWarnings, "max-warnings", 0, "show at most this many warning and conflict lines below the plan table (0 shows all)")
+	cmd.Flags().BoolVar(&o.NoPager, "no-pager", false, "do not show a plan taller than the terminal through $PAGER before the confirmation prompt")
+	cmd.Flags().BoolVar(&o.ValidateWithServer, "validate-with-server", false, "run a server-side dry-run create against the target to catch admission rejections")
+	cmd.Flags().StringVar(&o.FailOn, "fail-on", "error", "lowest conflict severity that blocks a create: error, warning")
+	cmd.Flags().BoolVar(&o.SkipConflictCheck, "skip-conflict-check", false, "do not check the target for conflicts; with --dry-run -o yaml|json the target is never contacted")
+	cmd.Flags().BoolVar(&o.Force, "force", false, "create resources even when blocking conflicts are reported")
+	cmd.Flags().BoolVar(&o.Atomic, "atomic", false, "all-or-nothing: if a resource fails to create, delete everything this run created")
+	cmd.Flags().BoolVar(&o.Verify, "verify", false, "read each created resource back and warn when the target cluster modified it (e.g. mutating webhooks)")
+	cmd.Flags().BoolVar(&o.Timings, "timings", false, "print the time and API requests spent connecting, discovering, planning, and applying")
+	cmd.Flags().BoolVar(&o.WithData, "with-data", false, "also copy the contents of copied PersistentVolumeClaims, using temporary rsync pods")
+	cmd.Flags().BoolVar(&o.ForceData, "force-data", false, "with --with-data, copy claims that running pods mount ReadWriteOnce (the data may be inconsistent)")
+	cmd.Flags().BoolVar(&o.WithPV, "with-pv", false, "also copy the PersistentVolume each bound claim uses and bind the copied claim to it, for storage the target can reach (e.g. NFS); both clusters then use the same storage")
+	cmd.Flags().IntVar(&o.Parallelism, "parallelism", 1, "number of resources to create concurrently (configs first, then workloads, then ingresses)")
+	cmd.Flags().StringToStringVar(&o.NamespaceMap, "namespace-map", nil, "map source namespaces to target namespaces for cross-namespace references (e.g. shared=shared-staging); unmapped namespaces go to --to-namespace")
+	cmd.Flags().BoolVar(&o.CreateNamespace, "create-namespace", false, "create target namespaces that do not exist, with the labels and annotations of the source namespace (e.g. Pod Security level, istio-injection)")
+	cmd.Flags().BoolVar(&o.IncludeGateways, "include-gateways", false, "with --recursive, also copy the Gateways that discovered HTTPRoutes attach to")
+	cmd.Flags().BoolVar(&o.IncludeSTSPVCs, "include-sts-pvcs", false, "with --recursive, also copy the claims StatefulSets created from their volumeClaimTemplates (e.g. data-web-0); combine with --with-data to move a stateful app")
+	cmd.Flags().BoolVar(&o.SkipSecrets, "skip-secrets", false, "with --recursive, do not copy the Secrets discovered; they are listed in the plan to be provisioned in the target")
+	cmd.Flags().BoolVar(&o.FollowOwner, "follow-owner", false, "when the resource is managed by a controller (e.g. a Pod of a Deployment), copy the top-level controller instead")
+	cmd.Flags().BoolVar(&o.NoProvenance, "no-provenance", false, "do not annotate created resources with where they were copied from")
+	cmd.Flags().BoolVar(&o.KeepHelmMetadata, "keep-helm-metadata", false, "keep the meta.helm.sh/ annotations and app.kubernetes.io/managed-by=Helm label of objects installed by Helm, e.g. to move them into an identical release in the target")
+	cmd.Flags().StringVar(&o.AuditLog, "audit-log", "", "append a JSON line to this file for every create and delete sent to a cluster, with the server, user, object, and outcome")
+	cmd.Flags().StringVar(&o.Resume, "resume", "", "resume the run whose -o report output is in this file: resources it created, overwrote, or found unchanged, and that are still in the target, are not copied again")
+	cmd.Flags().BoolVar(&o.Prune, "prune", false, "after applying, delete the resources earlier copies from the same source cluster and namespace created in the target namespace that this copy no longer includes")
+	cmd.Flags().StringToStringVar(&o.Labels, "label", nil, "label to add to every created resource (e.g. team=payments); repeatable")
+	cmd.Flags().StringToStringVar(&o.Annotations, "annotation", nil, "annotation to add to every created resource (e.g. change-ticket=OPS-1234); repeatable")
+	cmd.Flags().BoolVar(&o.OverwriteMetadata, "overwrite-metadata", false, "let --label and --annotation replace keys the resources already have")
+	cmd.Flags().BoolVar(&o.LabelPodTemplates, "label-pod-templates", false, "also add --label labels to pod templates (rolls out new pods)")
+	cmd.Flags().BoolVar(&o.ClearHostPorts, "clear-host-ports", false, "remove the hostPorts of copied containers, which may be taken on the target's nodes")
+	cmd.Flags().BoolVar(&o.PreserveNodePorts, "preserve-nodeport", false, "keep the nodePorts of copied Services instead of letting the target assign new ones; fails Services whose nodePorts are taken in the target")
+	cmd.Flags().BoolVar(&o.PreserveClusterIP, "preserve-cluster-ip", false, "keep the clusterIP of copied Services instead of letting the target assign a new one; fails Services whose clusterIP is taken in the target")
+	cmd.Flags().StringVar(&o.PreserveNodeName, "preserve-node-name", "", "keep the nodeName of copied Pods instead of letting the target's scheduler place them, or pin them to another node with --preserve-node-name=NODE")
+	cmd.Flags().Lookup("preserve-node-name").NoOptDefVal = sourceNodeName
+	cmd.Flags().StringToStringVar(&o.Images, "image", nil, "replace the image of a container in every workload, like kubectl set image (e.g. api=registry/app:staging, '*=registry/app:staging'); repeatable")
+	cmd.Flags().StringArrayVar(&o.Env, "env", nil, "set an environment variable in copied workloads, as [container:]NAME=value; without a container it is set in all of them; repeatable")
+	cmd.Flags().StringSliceVar(&o.IgnoreConflicts, "ignore-conflicts", nil, "comma-separated conflict types to ignore (e.g. reference,address)")
+	cmd.Flags().StringSliceVar(&o.SuppressWarnings, "suppress-warnings", nil, "comma-separated warning codes or globs to hide (e.g. KC-SVC-001,KC-POD-*)")
 }
 
 // Complete parses and validates the command arguments.
 func (o *Options) Complete(cmd *cobra.Command, args []string) error {
-	// Support both "resource/name" and "resource name" formats
+	o.version = cmd.Root().Version
+	o.stdout, o.stderr = cmd.OutOrStdout(), cmd.ErrOrStderr()
+
+	switch {
+	case o.Import:
+		if err := o.completeImport(args); err != nil {
+			return err
+		}
+	case o.Release:
+		if err := o.completeRelease(args); err != nil {
+			return err
+		}
+	default:
+		if err := o.completeResource(args); err != nil {
+			return err
+		}
+	}
+
+	// Validate TLS overrides
+	if o.InsecureSkipTLSVerify && o.CertificateAuthority != "" {
+		return fmt.Errorf("--insecure-skip-tls-verify and --certificate-authority cannot be combined")
+	}
+	if o.ToInsecureSkipTLSVerify && o.ToCertificateAuthority != "" {
+		return fmt.Errorf("--to-insecure-skip-tls-verify and --to-certificate-authority cannot be combined")
+	}
+
+	// Validate namespace-map
+	for from, to := range o.NamespaceMap {
+		if from == "" || to == "" {
+			return fmt.Errorf("invalid --namespace-map entry %q=%q: expected source=target", from, to)
+		}
+	}
+
+	if o.ForceData && !o.WithData {
+		return fmt.Errorf("--force-data requires --with-data")
+	}
+	if o.WithPV && o.WithData {
+		return fmt.Errorf("--with-pv and --with-data cannot be used together: with --with-pv the copied claims use the source's storage")
+	}
+
+	// Validate client tuning
+	if o.QPS < 0 || o.ToQPS < 0 || o.Burst < 0 || o.ToBurst < 0 || o.RequestTimeout < 0 {
+		return fmt.Errorf("--qps, --burst, --to-qps, --to-burst, and --request-timeout must not be negative")
+	}
+
+	// Validate parallelism
+	if o.Parallelism < 1 {
+		return fmt.Errorf("invalid --parallelism value %d: must be at least 1", o.Parallelism)
+	}
+
+	// Validate on-conflict
+	switch o.OnConflict {
+	case "skip", "warn", "overwrite":
+	case "prompt":
+		if !interactive() {
+			return fmt.Errorf("--on-conflict=prompt asks about each existing resource and needs an interactive terminal")
+		}
+	default:
+		return fmt.Errorf("invalid --on-conflict value %q: must be skip, warn, overwrite, or prompt", o.OnConflict)
+	}
+	switch o.DeletePropagation {
+	case "foreground", "background", "orphan":
+	default:
+		return fmt.Errorf("invalid --delete-propagation value %q: must be foreground, background, or orphan", o.DeletePropagation)
+	}
+	if o.DeleteTimeout < 0 {
+		return fmt.Errorf("invalid --delete-timeout %s: must not be negative", o.DeleteTimeout)
+	}
+	if o.SkipExisting && o.OnConflict != "skip" {
+		return fmt.Errorf("--skip-existing cannot be combined with --on-conflict=%s", o.OnConflict)
+	}
+
+	// Validate fail-on
+	switch o.FailOn {
+	case "error", "warning":
+	default:
+		return fmt.Errorf("invalid --fail-on value %q: must be error or warning", o.FailOn)
+	}
+
+	// Validate prune
+	if o.Prune {
+		switch {
+		case o.NoProvenance:
+			return fmt.Errorf("--prune finds earlier copies by their provenance and cannot be combined with --no-provenance")
+		case o.Move:
+			return fmt.Errorf("--prune cannot be combined with move")
+		}
+	}
+
+	// Read the report to resume
+	o.resumed = nil
+	if o.Resume != "" {
+		resumed, err := output.ReadReport(o.Resume)
+		if err != nil {
+			return fmt.Errorf("invalid --resume report: %w", err)
+		}
+		o.resumed = resumed
+	}
+
+	// Validate ignore-conflicts
+	o.ignoredTypes = nil
+	for _, name := range o.IgnoreConflicts {
+		t, err := conflict.ParseType(strings.TrimSpace(name))
+		if err != nil {
+			return fmt.Errorf("invalid --ignore-conflicts value: %w", err)
+		}
+		o.ignoredTypes = append(o.ignoredTypes, t)
+	}
+
+	// --to-name is left to the copier, which checks it against the rules of
+	// the resource's kind before fetching anything: RBAC objects accept
+	// names such as "system:foo" that other kinds reject
+
+	if o.PreserveNodeName != "" && o.PreserveNodeName != sourceNodeName {
+		if err := sanitizer.ValidateName("", o.PreserveNodeName); err != nil {
+			return fmt.Errorf("invalid --preserve-node-name: %w", err)
+		}
+	}
+
+	// Validate label and annotation keys
+	if err := o.metadata().Validate(); err != nil {
+		return fmt.Errorf("invalid --label or --annotation: %w", err)
+	}
+
+	// Validate image overrides
+	for name, image := range o.Images {
+		if name != "*" {
+			if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+				return fmt.Errorf("invalid --image container name %q: %s", name, strings.Join(errs, "; "))
+			}
+		}
+		if image == "" {
+			return fmt.Errorf("invalid --image %s=: the image must not be empty", name)
+		}
+	}
+
+	// Validate env
+	o.envVars = nil
+	for _, raw := range o.Env {
+		v, err := sanitizer.ParseEnvVar(raw)
+		if err != nil {
+			return fmt.Errorf("invalid --env value: %w", err)
+		}
+		o.envVars = append(o.envVars, v)
+	}
+
+	// Validate suppress-warnings
+	for _, pattern := range o.SuppressWarnings {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid --suppress-warnings pattern %q: %w", pattern, err)
+		}
+	}
+
+	// Validate output
+	switch o.Output {
+	case "table", "wide", "yaml", "json", "report":
+	case "tree", "dot":
+		if !o.Recursive && !o.Release {
+			return fmt.Errorf("--output %s shows the discovered dependency graph and requires --recursive", o.Output)
+		}
+	default:
+		return fmt.Errorf("invalid --output value %q: must be table, wide, yaml, json, report, tree, or dot", o.Output)
+	}
+	if o.List && o.Output != "yaml" {
+		return fmt.Errorf("--list only applies to -o yaml")
+	}
+	if o.Items && o.Output != "json" {
+		return fmt.Errorf("--items only applies to -o json")
+	}
+	if o.Objects && o.Output != "report" {
+		return fmt.Errorf("--objects only applies to -o report")
+	}
+
+	return nil
+}
+
+// completeResource parses the resource argument of copy, move, and export
+// and defaults the namespaces.
+func (o *Options) completeResource(args []string) error {
+	// Support both "resource/name" and "resource name" formats, like kubectl get
 	if len(args) == 2 {
+		if strings.Contains(args[0], "/") {
+			return fmt.Errorf("ambiguous resource arguments %q %q: either %s with an extra argument %q, or an object named %q of type %q, which is not a resource type; pass one resource, as <resource>/<name> or <resource> <name>",
+				args[0], args[1], args[0], args[1], args[1], args[0])
+		}
+		if strings.Contains(args[1], "/") {
+			return fmt.Errorf("invalid resource name %q: names cannot contain \"/\"; pass <resource>/<name> or <resource> <name>", args[1])
+		}
 		// Space-separated: "deployment myapp"
 		o.ResourceKind = strings.ToLower(args[0])
 		o.ResourceName = args[1]
@@ -124,11 +511,17 @@ func (o *Options) Complete(cmd *cobra.Command, args []string) error {
 		o.ResourceArg = args[0]
 		// Parse resource/name
 		parts := strings.SplitN(o.ResourceArg, "/", 2)
-		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		switch {
+		case len(parts) == 1 && parts[0] != "" && interactive():
+			// Only a type: Run lists its resources to pick from
+			o.ResourceKind = strings.ToLower(parts[0])
+			o.pick = true
+		case len(parts) != 2 || parts[0] == "" || parts[1] == "":
 			return fmt.Errorf("invalid resource argument %q: expected <resource>/<name> or <resource> <name>", o.ResourceArg)
+		default:
+			o.ResourceKind = strings.ToLower(parts[0])
+			o.ResourceName = parts[1]
 		}
-		o.ResourceKind = strings.ToLower(parts[0])
-		o.ResourceName = parts[1]
 	}
 
 	// Note: we do NOT strip the ".group" suffix here (e.g. "deployment.apps").
@@ -139,31 +532,97 @@ func (o *Options) Complete(cmd *cobra.Command, args []string) error {
 		o.SourceNamespace = getDefaultNamespace(o.SourceKubeconfig, o.SourceContext)
 	}
 
-	// Default target namespace to source namespace
-	if o.ToNamespace == "" {
-		o.ToNamespace = o.SourceNamespace
+	if o.Export {
+		if err := o.completeExport(); err != nil {
+			return err
+		}
 	}
 
+	o.defaultTargetNamespace()
+
 	// Validate: same namespace + no rename = conflict (for namespaced resources)
-	if o.ToNamespace == o.SourceNamespace && o.ToName == "" && o.ToContext == "" && o.ToKubeconfig == "" {
+	if !o.Export && o.ToNamespace == o.SourceNamespace && o.ToName == "" && o.ToContext == "" && o.ToKubeconfig == "" && o.ToCluster == "" {
 		return fmt.Errorf("copying within the same namespace requires --to-name to avoid name collision")
 	}
+	return nil
+}
+
+// Offline reports whether the run only exports sanitized manifests and so
+// never needs to contact the target cluster.
+func (o *Options) Offline() bool {
+	if o.Export {
+		return true
+	}
+	export := o.DryRun && (o.Output == "yaml" || o.Output == "json")
+	return export && !o.ValidateWithServer && !o.Prune
+}
 
-	// Validate on-conflict
-	switch o.OnConflict {
-	case "skip", "warn", "overwrite":
-	default:
-		return fmt.Errorf("invalid --on-conflict value %q: must be skip, warn, or overwrite", o.OnConflict)
+// pinnedNodeName is the node --preserve-node-name pins copied Pods to, or
+// an empty string to keep the nodes of the source Pods.
+func (o *Options) pinnedNodeName() string {
+	if o.PreserveNodeName == sourceNodeName {
+		return ""
 	}
+	return o.PreserveNodeName
+}
 
-	// Validate output
-	switch o.Output {
-	case "table", "yaml", "json":
-	default:
-		return fmt.Errorf("invalid --output value %q: must be table, yaml, or json", o.Output)
+// connection returns the client options for the source and target clusters.
+func (o *Options) connection() client.Options {
+	return client.Options{
+		Kubeconfig:       o.SourceKubeconfig,
+		Context:          o.SourceContext,
+		User:             o.SourceUser,
+		Cluster:          o.SourceCluster,
+		TargetKubeconfig: o.ToKubeconfig,
+		TargetContext:    o.ToContext,
+		TargetUser:       o.ToUser,
+		TargetCluster:    o.ToCluster,
+		QPS:              o.QPS,
+		Burst:            o.Burst,
+		TargetQPS:        o.ToQPS,
+		TargetBurst:      o.ToBurst,
+		Timeout:          o.RequestTimeout,
+		TLS:              client.TLSOptions{Insecure: o.InsecureSkipTLSVerify, CAFile: o.CertificateAuthority},
+		TargetTLS:        client.TLSOptions{Insecure: o.ToInsecureSkipTLSVerify, CAFile: o.ToCertificateAuthority},
 	}
+}
 
-	return nil
+// format returns the output package format for --output and the flags that
+// refine it (--list, --items, --objects).
+func (o *Options) format() string {
+	switch {
+	case o.List:
+		return output.FormatYAMLList
+	case o.Items:
+		return output.FormatJSONItems
+	case o.Objects:
+		return output.FormatReportObjects
+	}
+	return o.Output
+}
+
+// tableOutput reports whether the output is a table rather than objects.
+func (o *Options) tableOutput() bool {
+	return o.Output == "table" || o.Output == "wide"
+}
+
+// tableFormat is the table format the plan is shown in before confirmation:
+// wide when asked for, table otherwise.
+func (o *Options) tableFormat() string {
+	if o.Output == "wide" {
+		return output.FormatWide
+	}
+	return "table"
+}
+
+// metadata is the --label and --annotation configuration.
+func (o *Options) metadata() sanitizer.Metadata {
+	return sanitizer.Metadata{
+		Labels:       o.Labels,
+		Annotations:  o.Annotations,
+		Overwrite:    o.OverwriteMetadata,
+		PodTemplates: o.LabelPodTemplates,
+	}
 }
 
 // TargetName returns the target resource name, falling back to the source name.
@@ -175,122 +634,393 @@ func (o *Options) TargetName() string {
 }
 
 // Run executes the copy operation with plan/apply flow.
-func (o *Options) Run() error {
+func (o *Options) Run() (err error) {
 	ctx := context.TODO()
 
-	// Set up progress reporter
+	if o.pick {
+		names, err := o.pickResources(ctx)
+		if err != nil {
+			return err
+		}
+		// Each chosen resource is copied as if it had been named
+		for _, name := range names {
+			run := *o
+			run.pick = false
+			run.ResourceName = name
+			if err := run.Run(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	output.MaxWarnings = o.MaxWarnings
+
+	// Set up progress reporter. Everything else written to the terminal
+	// goes through it, so its status line is never drawn over
 	prog := output.NewProgress(o.Quiet)
+	stdout, stderr := o.stdout, prog.Writer(o.stderr)
 
-	// Build clients
-	prog.Connecting()
-	clients, err := client.New(o.SourceKubeconfig, o.SourceContext, o.ToKubeconfig, o.ToContext)
-	if err != nil {
-		prog.Clear()
-		return fmt.Errorf("cannot connect to cluster: %w\n    Check your kubeconfig and network connectivity.", err)
+	// Tables are the output when nothing else is printed on stdout, so they
+	// go there and can be piped; progress and prompts stay on stderr
+	log := stderr
+	if o.tableOutput() {
+		log = prog.Writer(stdout)
 	}
 
-	// Resolve resource type dynamically via the API server's discovery
-	// This handles short names, plural, singular, CRDs, resource.group format, etc.
-	resolved, err := clients.Resolve(o.ResourceKind)
-	if err != nil {
-		prog.Clear()
-		return err
+	// Timings of the phases; the request counter is hooked up by Copy
+	var stats *copier.Stats
+	if o.Timings {
+		stats = &copier.Stats{}
 	}
 
-	primaryRef := copier.ResourceRef{
-		GVR:        resolved.GVR,
-		Kind:       resolved.Kind,
-		Name:       o.ResourceName,
-		Namespace:  o.SourceNamespace,
-		Namespaced: resolved.Namespaced,
+	if o.InsecureSkipTLSVerify {
+		fmt.Fprintf(stderr, "  WARNING: not verifying the source cluster's TLS certificate (--insecure-skip-tls-verify)\n")
 	}
-	if !resolved.Namespaced {
-		primaryRef.Namespace = ""
+	if o.ToInsecureSkipTLSVerify {
+		fmt.Fprintf(stderr, "  WARNING: not verifying the target cluster's TLS certificate (--to-insecure-skip-tls-verify)\n")
 	}
 
-	// Cluster-scoped in same cluster requires --to-name to avoid overwriting
-	if !primaryRef.Namespaced && o.ToName == "" && o.ToContext == "" && o.ToKubeconfig == "" {
-		return fmt.Errorf("copying a cluster-scoped resource (e.g. StorageClass) in the same cluster requires --to-name")
+	if o.Import {
+		b, err := bundle.ReadFile(o.BundlePath)
+		if err != nil {
+			return err
+		}
+		o.bundle = b
 	}
 
-	// Build list of resources to copy
-	refs := []copier.ResourceRef{primaryRef}
+	var auditLog *audit.Log
+	if o.AuditLog != "" {
+		if auditLog, err = audit.Open(o.AuditLog); err != nil {
+			return fmt.Errorf("cannot open audit log: %w", err)
+		}
+		defer func() {
+			if closeErr := auditLog.Close(); closeErr != nil && err == nil {
+				err = fmt.Errorf("writing audit log %s: %w", o.AuditLog, closeErr)
+			}
+		}()
+	}
 
-	if o.Recursive {
-		prog.Discovering()
-		discovered, err := discovery.Discover(ctx, clients.SourceDynamic, primaryRef.GVR, primaryRef.Name, primaryRef.Namespace)
-		if err != nil {
-			prog.Clear()
-			return fmt.Errorf("discovering dependencies: %w", err)
+	req := kubecopy.CopyRequest{
+		Clients:                o.clients,
+		Connection:             o.connection(),
+		SourceOnly:             o.Offline(),
+		Resource:               o.ResourceKind,
+		Name:                   o.ResourceName,
+		Namespace:              o.SourceNamespace,
+		Release:                o.ReleaseName,
+		TargetNamespace:        o.ToNamespace,
+		TargetName:             o.ToName,
+		Recursive:              o.Recursive,
+		IncludeGateways:        o.IncludeGateways,
+		IncludeStatefulSetPVCs: o.IncludeSTSPVCs,
+		FollowOwner:            o.FollowOwner,
+		SkipSecrets:            o.SkipSecrets,
+		DiscoverOnly:           o.Output == "tree" || o.Output == "dot",
+		WithData:               o.WithData,
+		ForceData:              o.ForceData,
+		Prune:                  o.Prune,
+		AuditLog:               auditLog,
+	}
+	if o.Move {
+		req.Move = &copier.MoveOptions{KeepSecrets: o.KeepSourceSecrets}
+		if o.Wait {
+			req.Move.WaitTimeout = o.WaitTimeout
+		}
+	}
+	req.Options = []copier.Option{
+		copier.WithConflictStrategy(copier.ConflictStrategy(o.OnConflict)),
+		copier.WithDeletePropagation(deletionPropagation(o.DeletePropagation)),
+		copier.WithDeletionTimeout(o.DeleteTimeout),
+		copier.WithProgress(prog),
+		copier.WithFieldManager("kubectl-copy"),
+		copier.WithFailOn(conflict.Severity(o.FailOn)),
+		copier.WithIgnoredConflicts(o.ignoredTypes...),
+		copier.WithSuppressedWarnings(o.SuppressWarnings...),
+		copier.WithNamespaceMap(o.NamespaceMap),
+		copier.WithParallelism(o.Parallelism),
+		copier.WithStats(stats),
+	}
+	for _, opt := range []struct {
+		set    bool
+		option copier.Option
+	}{
+		{o.ValidateWithServer, copier.WithServerValidation()},
+		{o.Force, copier.WithForce()},
+		{o.SkipExisting, copier.WithSkipExisting()},
+		{o.SkipConflictCheck || o.Offline(), copier.WithoutConflictCheck()},
+		{o.Atomic, copier.WithAtomic()},
+		{o.CreateNamespace, copier.WithCreateNamespace()},
+		{o.WithPV, copier.WithPersistentVolumes()},
+		{o.Verify, copier.WithVerify()},
+		{o.DryRun, copier.WithDryRun()},
+		{len(o.Labels) > 0 || len(o.Annotations) > 0, copier.WithMetadata(o.metadata())},
+		{len(o.Images) > 0, copier.WithImages(o.Images)},
+		{len(o.envVars) > 0, copier.WithEnv(o.envVars...)},
+		{o.ClearHostPorts, copier.WithClearHostPorts()},
+		{o.PreserveNodePorts, copier.WithPreserveNodePorts()},
+		{o.PreserveClusterIP, copier.WithPreserveClusterIP()},
+		{o.PreserveNodeName != "", copier.WithPreserveNodeName(o.pinnedNodeName())},
+		{o.KeepHelmMetadata, copier.WithHelmMetadata()},
+		{len(o.resumed) > 0, copier.WithResumed(o.resumed)},
+		{!o.NoProvenance, copier.WithProvenance(provenance.Info{
+			Cluster: o.sourceCluster(),
+			Version: o.version,
+		})},
+	} {
+		if opt.set {
+			req.Options = append(req.Options, opt.option)
 		}
-		refs = append(refs, discovered...)
-		prog.DiscoveredCount(len(discovered))
 	}
 
-	// Create copier
-	c := &copier.Copier{
-		SourceClient: clients.SourceDynamic,
-		TargetClient: clients.TargetDynamic,
-		OnConflict:   o.OnConflict,
-		Progress:     prog,
+	// Ctrl-C stops the run after the requests in flight, so the results of
+	// what was already created are still shown.
+	ctx, stop := interruptible(ctx, prog)
+	defer stop()
+
+	if o.OnConflict == "prompt" {
+		prompter := &conflictPrompter{ctx: ctx, prog: prog, out: stderr}
+		req.Options = append(req.Options, copier.WithResolver(prompter.resolve))
 	}
 
-	// Target namespace is empty for cluster-scoped resources
-	toNamespace := o.ToNamespace
-	if !primaryRef.Namespaced {
-		toNamespace = ""
+	// Show the plan, and unless --yes ask for confirmation before applying
+	req.Confirm = func(report *kubecopy.Report) bool {
+		prog.Clear()
+		resume := prog.Pause()
+		defer resume()
+		if o.Yes || o.NoPager {
+			o.printPlan(stdout, log, report, o.tableFormat(), nil)
+		} else {
+			// A plan taller than the terminal is paged, so it can be read
+			// before answering the prompt
+			pager := output.NewPager(log)
+			o.printPlan(stdout, pager, report, o.tableFormat(), nil)
+			pager.Flush()
+		}
+		if o.Yes {
+			fmt.Fprintln(log)
+			return true
+		}
+		// Identical resources still have sources for a move to delete
+		if !hasWork(report.Results) && !o.Move {
+			fmt.Fprintf(stderr, "\n  Nothing to do.\n\n")
+			return false
+		}
+		if !askConfirmation(ctx, prog) {
+			fmt.Fprintf(stderr, "  Aborted.\n\n")
+			return false
+		}
+		fmt.Fprintln(log)
+		return true
 	}
 
-	// Phase 1: Plan (fetch, sanitize, detect conflicts)
-	planned := c.PlanAll(ctx, refs, toNamespace, o.ToName)
+	var report *kubecopy.Report
+	if o.Import {
+		report, err = kubecopy.Import(ctx, kubecopy.ImportRequest{
+			Bundle:          o.bundle,
+			Clients:         o.clients,
+			Connection:      req.Connection,
+			TargetNamespace: o.ToNamespace,
+			AuditLog:        auditLog,
+			Options:         req.Options,
+			Confirm:         req.Confirm,
+		})
+	} else {
+		report, err = kubecopy.Copy(ctx, req)
+	}
 	prog.Clear()
+	if ctx.Err() != nil {
+		if report != nil && report.Applied {
+			output.PrintResults(stdout, log, report.Results, o.format(), stats)
+		}
+		return ErrCanceled
+	}
+	if err != nil && (report == nil || !report.Applied) {
+		return err
+	}
+
+	switch {
+	case req.DiscoverOnly:
+		// Graph formats only describe why each resource is in the plan
+		return output.PrintGraph(stdout, report.Graph, o.Output)
+	case report.Applied:
+		if printErr := output.PrintResults(stdout, log, report.Results, o.format(), stats); printErr != nil {
+			return printErr
+		}
+		// A move that did not delete its sources
+		return err
+	case o.Export:
+		if err := o.printPlan(stdout, log, report, o.format(), stats); err != nil {
+			return err
+		}
+		return o.writeBundle(report, stderr)
+	case o.DryRun:
+		return o.printPlan(stdout, log, report, o.format(), stats)
+	}
+	return nil
+}
 
-	// Show the plan
-	if o.DryRun {
-		return output.PrintPlan(planned, o.Output)
+// sourceCluster names the source cluster in provenance: the source context,
+// or for an import the context the bundle was exported from.
+func (o *Options) sourceCluster() string {
+	if o.bundle != nil {
+		return o.bundle.Manifest.SourceCluster
 	}
+	return getContextName(o.SourceKubeconfig, o.SourceContext)
+}
 
-	// Show plan table and ask for confirmation (unless --yes)
-	output.PrintPlan(planned, "table")
+// printPlan prints the planned results in format, objects to out and tables
+// to log, after the cluster versions, notices, and discovery findings when it
+// is a table.
+func (o *Options) printPlan(out, log io.Writer, report *kubecopy.Report, format string, stats *copier.Stats) error {
+	var discoveryErrors []discovery.Warning
+	if report.Graph != nil {
+		discoveryErrors = report.Graph.Errors
+	}
+	table := format == o.tableFormat()
+	if table {
+		output.PrintPlanHeader(log, o.planHeader(report))
+		if report.Graph != nil {
+			output.PrintDiscoveryWarnings(log, report.Graph.Warnings)
+			output.PrintDiscoveryErrors(log, report.Graph.Errors)
+			output.PrintExcluded(log, report.Graph.Excluded)
+		}
+	}
+	err := output.PrintPlan(out, log, report.Results, format, stats)
+	if table {
+		output.PrintDiscoveryIncomplete(log, len(discoveryErrors))
+	}
+	return err
+}
 
-	if !o.Yes {
-		// Check if any actionable work exists
-		hasWork := false
-		for _, r := range planned {
-			if r.Error == nil && r.Action != "skip" {
-				hasWork = true
-				break
-			}
+// planHeader describes the cluster versions and namespaces and, for a
+// managed resource, how it relates to its controller.
+func (o *Options) planHeader(report *kubecopy.Report) output.PlanHeader {
+	var header output.PlanHeader
+	if report.SourceVersion != nil {
+		header.SourceVersion = "v" + report.SourceVersion.String()
+	}
+	if report.TargetVersion != nil {
+		header.TargetVersion = "v" + report.TargetVersion.String()
+	}
+	header.SourceNamespace, header.TargetNamespace = o.SourceNamespace, o.ToNamespace
+	if report.SameCluster && (o.ToContext != "" || o.ToKubeconfig != "" || o.ToCluster != "") {
+		header.Notices = append(header.Notices, "the target context points at the source cluster: this is an intra-cluster copy, so the cross-cluster caveats do not apply")
+	}
+	if b := o.bundle; b != nil {
+		notice := fmt.Sprintf("importing %s, exported", o.BundlePath)
+		if b.Manifest.SourceCluster != "" {
+			notice += " from " + b.Manifest.SourceCluster
 		}
-		if !hasWork {
-			fmt.Fprintf(os.Stderr, "\n  Nothing to do.\n\n")
-			return nil
+		notice += " at " + b.Manifest.CreatedAt.Format(time.RFC3339)
+		if b.Manifest.ToolVersion != "" {
+			notice += " by kubectl-copy " + b.Manifest.ToolVersion
 		}
+		header.Notices = append(header.Notices, notice)
+	}
+	if o.Move {
+		notice := "move: once every resource is created in the target, the sources are deleted"
+		if o.Wait {
+			notice += fmt.Sprintf(" after the copied workloads are ready (up to %s)", o.WaitTimeout)
+		}
+		if o.KeepSourceSecrets {
+			notice += ", except Secrets"
+		}
+		header.Notices = append(header.Notices, notice)
+	}
+	switch {
+	case o.SkipSecrets && o.Release:
+		header.Notices = append(header.Notices, fmt.Sprintf("copying the release record %s although --skip-secrets is set, so helm in the target recognizes the release", report.Primary.DisplayName()))
+	case o.SkipSecrets && report.Primary.Kind == "Secret":
+		header.Notices = append(header.Notices, fmt.Sprintf("copying %s although --skip-secrets is set, since it was named explicitly", report.Primary.DisplayName()))
+	}
+	if owner := report.ManagedBy; owner != nil {
+		if o.FollowOwner {
+			header.Notices = append(header.Notices, fmt.Sprintf("%s is managed by %s; copying %s instead (--follow-owner)",
+				report.Requested.DisplayName(), owner.DisplayName(), owner.DisplayName()))
+		} else {
+			header.Notices = append(header.Notices, fmt.Sprintf("%s is managed by %s and the copy will not track it.\n"+
+				"    To copy the controller instead, re-run with --follow-owner or: kubectl copy %s/%s -n %s",
+				report.Requested.DisplayName(), owner.DisplayName(), strings.ToLower(owner.Kind), owner.Name, owner.Namespace))
+		}
+	}
+	return header
+}
 
-		if !askConfirmation() {
-			fmt.Fprintf(os.Stderr, "  Aborted.\n\n")
-			return nil
+// hasWork reports whether applying the plan would change anything.
+func hasWork(planned []copier.CopyResult) bool {
+	for _, r := range planned {
+		if r.Error == nil && r.Action != "skip" && r.Action != "unchanged" && r.Action != "exists" && r.Action != "done" {
+			return true
 		}
 	}
+	return false
+}
+
+// interruptible returns a context canceled by the first SIGINT or SIGTERM.
+// A second signal exits immediately.
+func interruptible(parent context.Context, prog *output.ProgressReporter) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(parent)
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
 
-	// Phase 2: Apply
-	fmt.Fprintln(os.Stderr)
-	c.ApplyAll(ctx, planned)
+	go func() {
+		select {
+		case <-signals:
+		case <-ctx.Done():
+			return
+		}
+		prog.Clear()
+		fmt.Fprintf(prog.Writer(os.Stderr), "\n  Interrupted -- stopping after the requests in flight (press Ctrl-C again to exit now)\n")
+		cancel()
+		<-signals
+		os.Exit(130)
+	}()
 
-	// Show results
-	return output.PrintResults(planned, o.Output)
+	return ctx, func() {
+		signal.Stop(signals)
+		cancel()
+	}
 }
 
-// askConfirmation prompts the user for y/N confirmation on stderr.
-func askConfirmation() bool {
-	fmt.Fprintf(os.Stderr, "  Proceed? [y/N]: ")
-	reader := bufio.NewReader(os.Stdin)
-	answer, err := reader.ReadString('\n')
+// askConfirmation prompts the user for y/N confirmation on stderr, with the
+// status line of prog paused. It answers no when ctx is canceled while
+// waiting.
+func askConfirmation(ctx context.Context, prog *output.ProgressReporter) bool {
+	resume := prog.Pause()
+	defer resume()
+	fmt.Fprintf(prog.Writer(os.Stderr), "  Proceed? [y/N]: ")
+	answer, _ := readAnswer(ctx)
+	answer = strings.ToLower(answer)
+	return answer == "y" || answer == "yes"
+}
+
+// deletionPropagation maps a --delete-propagation value to its API policy.
+func deletionPropagation(value string) metav1.DeletionPropagation {
+	switch value {
+	case "background":
+		return metav1.DeletePropagationBackground
+	case "orphan":
+		return metav1.DeletePropagationOrphan
+	default:
+		return metav1.DeletePropagationForeground
+	}
+}
+
+// getContextName returns the kubeconfig context the source is read from.
+func getContextName(kubeconfig, context string) string {
+	if context != "" {
+		return context
+	}
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if kubeconfig != "" {
+		rules.ExplicitPath = kubeconfig
+	}
+	raw, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{}).RawConfig()
 	if err != nil {
-		return false
+		return ""
 	}
-	answer = strings.TrimSpace(strings.ToLower(answer))
-	return answer == "y" || answer == "yes"
+	return raw.CurrentContext
 }
 
 // getDefaultNamespace returns the namespace from the current kubeconfig context.
@@ -310,3 +1040,39 @@ func getDefaultNamespace(kubeconfig, context string) string {
 	}
 	return ns
 }
+
+// contextNamespace returns the namespace a kubeconfig context sets. Unlike
+// getDefaultNamespace, it returns empty rather than "default" when the
+// context sets none or the kubeconfig cannot be read.
+func contextNamespace(kubeconfig, context string) string {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if kubeconfig != "" {
+		rules.ExplicitPath = kubeconfig
+	}
+	raw, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{}).RawConfig()
+	if err != nil {
+		return ""
+	}
+	if context == "" {
+		context = raw.CurrentContext
+	}
+	if ctx, ok := raw.Contexts[context]; ok {
+		return ctx.Namespace
+	}
+	return ""
+}
+
+// defaultTargetNamespace defaults --to-namespace to the namespace of the
+// target context when --to-context or --to-kubeconfig picks one, and to the
+// source namespace when it does not or the context sets none.
+func (o *Options) defaultTargetNamespace() {
+	if o.ToNamespace != "" {
+		return
+	}
+	if o.ToContext != "" || o.ToKubeconfig != "" {
+		o.ToNamespace = contextNamespace(o.ToKubeconfig, o.ToContext)
+	}
+	if o.ToNamespace == "" {
+		o.ToNamespace = o.SourceNamespace
+	}
+}
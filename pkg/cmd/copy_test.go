@@ -0,0 +1,296 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/discovery/cached/memory"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/restmapper"
+	k8stesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/yaml"
+
+	"github.com/a13x22/kube-copy/pkg/client"
+)
+
+var (
+	configMapGVR  = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	deploymentGVR = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+)
+
+// served are the resources the fake cluster serves, with their list kinds.
+var served = []struct {
+	gvr        schema.GroupVersionResource
+	kind       string
+	namespaced bool
+	shortNames []string
+}{
+	{configMapGVR, "ConfigMap", true, []string{"cm"}},
+	{schema.GroupVersionResource{Version: "v1", Resource: "secrets"}, "Secret", true, nil},
+	{schema.GroupVersionResource{Version: "v1", Resource: "services"}, "Service", true, []string{"svc"}},
+	{schema.GroupVersionResource{Version: "v1", Resource: "serviceaccounts"}, "ServiceAccount", true, []string{"sa"}},
+	{schema.GroupVersionResource{Version: "v1", Resource: "persistentvolumeclaims"}, "PersistentVolumeClaim", true, []string{"pvc"}},
+	{schema.GroupVersionResource{Version: "v1", Resource: "pods"}, "Pod", true, []string{"po"}},
+	{schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}, "Namespace", false, []string{"ns"}},
+	{deploymentGVR, "Deployment", true, []string{"deploy"}},
+}
+
+// fakeClients returns clients for one fake cluster, serving as both source
+// and target, holding objects.
+func fakeClients(t *testing.T, manifests ...string) (*client.Clients, *dynamicfake.FakeDynamicClient) {
+	t.Helper()
+	listKinds := map[schema.GroupVersionResource]string{}
+	lists := map[string]*metav1.APIResourceList{}
+	var order []string
+	for _, r := range served {
+		listKinds[r.gvr] = r.kind + "List"
+		gv := r.gvr.GroupVersion().String()
+		if lists[gv] == nil {
+			lists[gv] = &metav1.APIResourceList{GroupVersion: gv}
+			order = append(order, gv)
+		}
+		lists[gv].APIResources = append(lists[gv].APIResources, metav1.APIResource{
+			Name:       r.gvr.Resource,
+			Kind:       r.kind,
+			Namespaced: r.namespaced,
+			ShortNames: r.shortNames,
+			Verbs:      metav1.Verbs{"get", "list", "create", "delete"},
+		})
+	}
+
+	var objects []runtime.Object
+	for _, m := range manifests {
+		obj := &unstructured.Unstructured{}
+		if err := yaml.Unmarshal([]byte(m), &obj.Object); err != nil {
+			t.Fatalf("parse manifest: %v", err)
+		}
+		objects = append(objects, obj)
+	}
+	fake := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, objects...)
+	dynamic := servedOnly{Interface: fake, listKinds: listKinds}
+
+	discovery := &discoveryfake.FakeDiscovery{Fake: &k8stesting.Fake{}, FakedServerVersion: &version.Info{GitVersion: "v1.33.0"}}
+	for _, gv := range order {
+		discovery.Resources = append(discovery.Resources, lists[gv])
+	}
+	cached := memory.NewMemCacheClient(discovery)
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(cached)
+
+	return &client.Clients{
+		SourceDynamic:   dynamic,
+		SourceMapper:    mapper,
+		SourceDiscovery: cached,
+		TargetDynamic:   dynamic,
+		TargetMapper:    mapper,
+		TargetDiscovery: cached,
+		SameCluster:     true,
+		Requests:        &client.RequestCounter{},
+	}, fake
+}
+
+// servedOnly answers requests for resources the fake cluster does not serve
+// with NotFound, as an API server does, where the fake would panic.
+type servedOnly struct {
+	dynamic.Interface
+	listKinds map[schema.GroupVersionResource]string
+}
+
+func (s servedOnly) Resource(gvr schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	if _, ok := s.listKinds[gvr]; !ok {
+		return unserved{gr: gvr.GroupResource()}
+	}
+	return s.Interface.Resource(gvr)
+}
+
+type unserved struct {
+	dynamic.NamespaceableResourceInterface // other calls are not expected
+	gr                                     schema.GroupResource
+}
+
+func (u unserved) Namespace(string) dynamic.ResourceInterface { return u }
+
+func (u unserved) Get(context.Context, string, metav1.GetOptions, ...string) (*unstructured.Unstructured, error) {
+	return nil, apierrors.NewNotFound(u.gr, "")
+}
+
+func (u unserved) List(context.Context, metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	return nil, apierrors.NewNotFound(u.gr, "")
+}
+
+// runCopy runs the copy command with args against clients and returns what
+// it printed on stdout.
+func runCopy(t *testing.T, clients *client.Clients, args ...string) (string, error) {
+	t.Helper()
+	// No kubeconfig: namespaces and contexts come from the flags
+	t.Setenv("KUBECONFIG", filepath.Join(t.TempDir(), "config"))
+
+	var stdout, stderr bytes.Buffer
+	cmd := newCopyCommand(&Options{clients: clients})
+	cmd.SetArgs(args)
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	err := cmd.Execute()
+	if err != nil {
+		t.Logf("stderr:\n%s", stderr.String())
+	}
+	return stdout.String(), err
+}
+
+const webDeployment = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod
+  uid: 6f1c0e0a-1111-4c1e-9a57-000000000001
+  resourceVersion: "42"
+spec:
+  selector:
+    matchLabels: {app: web}
+  template:
+    metadata:
+      labels: {app: web}
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.27
+        envFrom:
+        - configMapRef:
+            name: web-config
+status:
+  replicas: 1
+`
+
+const webConfig = `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web-config
+  namespace: prod
+data:
+  mode: production
+`
+
+const stagingNamespace = `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: staging
+`
+
+func TestCopyCommandCopiesRecursively(t *testing.T) {
+	clients, cluster := fakeClients(t, webDeployment, webConfig, stagingNamespace)
+
+	stdout, err := runCopy(t, clients, "deployment/web", "-n", "prod", "--to-namespace", "staging", "-r", "-y", "-q", "-o", "json")
+	if err != nil {
+		t.Fatalf("copy: %v", err)
+	}
+
+	deployment, err := cluster.Resource(deploymentGVR).Namespace("staging").Get(t.Context(), "web", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Deployment not copied: %v", err)
+	}
+	if deployment.GetUID() != "" || deployment.GetResourceVersion() != "" {
+		t.Errorf("copied Deployment kept the source's uid %q and resourceVersion %q", deployment.GetUID(), deployment.GetResourceVersion())
+	}
+	if _, found := deployment.Object["status"]; found {
+		t.Error("copied Deployment kept the source's status")
+	}
+	if _, err := cluster.Resource(configMapGVR).Namespace("staging").Get(t.Context(), "web-config", metav1.GetOptions{}); err != nil {
+		t.Errorf("ConfigMap the Deployment references not copied: %v", err)
+	}
+	if _, err := cluster.Resource(deploymentGVR).Namespace("prod").Get(t.Context(), "web", metav1.GetOptions{}); err != nil {
+		t.Errorf("source Deployment gone: %v", err)
+	}
+
+	var list struct {
+		Kind  string
+		Items []unstructured.Unstructured
+	}
+	if err := json.Unmarshal([]byte(stdout), &list); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, stdout)
+	}
+	if list.Kind != "List" || len(list.Items) != 2 {
+		t.Errorf("output is a %q of %d items, want a List of 2", list.Kind, len(list.Items))
+	}
+}
+
+func TestCopyCommandDryRunCreatesNothing(t *testing.T) {
+	clients, cluster := fakeClients(t, webDeployment, webConfig, stagingNamespace)
+
+	if _, err := runCopy(t, clients, "deployment", "web", "-n", "prod", "--to-namespace", "staging", "-r", "--dry-run", "-q"); err != nil {
+		t.Fatalf("copy: %v", err)
+	}
+	for _, action := range cluster.Actions() {
+		if action.GetVerb() == "create" || action.GetVerb() == "delete" {
+			t.Errorf("dry run sent a %s of %s", action.GetVerb(), action.GetResource().Resource)
+		}
+	}
+}
+
+func TestCopyCommandRejectsWithPVWithinOneCluster(t *testing.T) {
+	clients, cluster := fakeClients(t, webDeployment, webConfig, stagingNamespace)
+
+	_, err := runCopy(t, clients, "deployment/web", "-n", "prod", "--to-namespace", "staging", "-r", "--with-pv", "-y", "-q")
+	if err == nil || !strings.Contains(err.Error(), "--with-pv cannot copy within one cluster") {
+		t.Fatalf("copy --with-pv: error %v, want a same-cluster error", err)
+	}
+	if actions := cluster.Actions(); len(actions) > 0 {
+		t.Errorf("copy --with-pv sent %d requests before failing", len(actions))
+	}
+}
+
+func TestCopyCommandPrintsTablesToStdout(t *testing.T) {
+	clients, _ := fakeClients(t, webDeployment, webConfig, stagingNamespace)
+
+	for range 2 { // the writers of one run are not left behind for the next
+		stdout, err := runCopy(t, clients, "deployment/web", "-n", "prod", "--to-namespace", "staging", "-r", "--dry-run", "-q")
+		if err != nil {
+			t.Fatalf("copy: %v", err)
+		}
+		if !strings.Contains(stdout, "Deployment/web") || !strings.Contains(stdout, "ConfigMap/web-config") {
+			t.Errorf("stdout has no plan table:\n%s", stdout)
+		}
+	}
+}
+
+func TestCopyCommandSkipsExistingTargets(t *testing.T) {
+	existing := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: staging
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.25
+`
+	clients, cluster := fakeClients(t, webDeployment, stagingNamespace, existing)
+
+	if _, err := runCopy(t, clients, "deployment/web", "-n", "prod", "--to-namespace", "staging", "-y", "-q"); err != nil {
+		t.Fatalf("copy: %v", err)
+	}
+	deployment, err := cluster.Resource(deploymentGVR).Namespace("staging").Get(t.Context(), "web", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	image, _, _ := unstructured.NestedSlice(deployment.Object, "spec", "template", "spec", "containers")
+	if image[0].(map[string]interface{})["image"] != "nginx:1.25" {
+		t.Errorf("existing Deployment was replaced without --on-conflict overwrite")
+	}
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/a13x22/kube-copy/pkg/bundle"
+	"github.com/a13x22/kube-copy/pkg/kubecopy"
+	"github.com/a13x22/kube-copy/pkg/provenance"
+)
+
+// NewExportCommand creates the export subcommand, which writes the sanitized
+// objects of a copy to a bundle for import into a cluster that cannot be
+// reached from the source.
+func NewExportCommand() *cobra.Command {
+	o := &Options{Export: true}
+
+	cmd := &cobra.Command{
+		Use:   "export (<resource>/<name> | <resource> <name>) --bundle <file> [flags]",
+		Short: "Write sanitized resources to a bundle for import elsewhere",
+		Long: `Plan a copy like kubectl copy does, without contacting any target cluster,
+and write the sanitized objects to a bundle: a gzipped tarball with a manifest
+of the resources, the warnings raised while sanitizing them, and where they
+were exported from. Carry the bundle to a machine that can reach the target
+and apply it with kubectl copy import, which checks it for conflicts against
+the target first.
+
+Objects keep their source namespaces; import chooses the target namespace.`,
+		Example: `  # Export a deployment and everything it uses
+  kubectl copy export deployment/myapp -r --bundle app.tar.gz
+
+  # Later, on a machine with access to the target
+  kubectl copy import app.tar.gz --to-namespace dr`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Args:          cobra.RangeArgs(1, 2),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return o.Complete(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run()
+		},
+	}
+
+	o.addFlags(cmd)
+	cmd.Flags().StringVar(&o.BundlePath, "bundle", "", "file to write the bundle to")
+	cmd.MarkFlagRequired("bundle")
+
+	return cmd
+}
+
+// completeExport validates the flags of export, which plans against no
+// target: the target is chosen at import.
+func (o *Options) completeExport() error {
+	switch {
+	case o.ToNamespace != "" || o.ToName != "":
+		return fmt.Errorf("export keeps the source names and namespaces; pass --to-namespace to import instead")
+	case o.ToKubeconfig != "" || o.ToContext != "" || o.ToUser != "" || o.ToCluster != "":
+		return fmt.Errorf("export never contacts a target cluster; pass the target flags to import instead")
+	case o.ValidateWithServer || o.Prune || o.WithData || o.CreateNamespace:
+		return fmt.Errorf("--validate-with-server, --prune, --with-data, and --create-namespace need a target cluster and cannot be used with export")
+	}
+	o.DryRun = true
+	o.SkipConflictCheck = true
+	return nil
+}
+
+// writeBundle writes the planned results of an export to BundlePath and
+// reports it on stderr.
+func (o *Options) writeBundle(report *kubecopy.Report, stderr io.Writer) error {
+	b, err := kubecopy.NewBundle(report, provenance.Info{Cluster: o.sourceCluster(), Version: o.version})
+	if err != nil {
+		return err
+	}
+	if err := bundle.WriteFile(o.BundlePath, b); err != nil {
+		return err
+	}
+	fmt.Fprintf(stderr, "  Exported %d resource(s) to %s\n\n", len(b.Objects), o.BundlePath)
+	return nil
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// NewImportCommand creates the import subcommand, which applies a bundle
+// written by export.
+func NewImportCommand() *cobra.Command {
+	o := &Options{Import: true}
+
+	cmd := &cobra.Command{
+		Use:   "import <bundle> [flags]",
+		Short: "Apply a bundle written by kubectl copy export",
+		Long: `Apply the resources of a bundle written by kubectl copy export to a target
+cluster. The objects are checked for conflicts against the target and the
+plan is shown for confirmation, as for a copy; the warnings raised when they
+were exported are shown again.
+
+The target cluster is selected with --to-context and --to-kubeconfig, or
+defaults to the current context. Without --to-namespace, resources are
+imported into the namespaces they were exported from. Bundles written by a
+version of kubectl copy with another bundle format, or whose files do not
+match their checksums, are refused.`,
+		Example: `  # Import into the dr namespace of the current context
+  kubectl copy import app.tar.gz --to-namespace dr
+
+  # Preview the import into another cluster
+  kubectl copy import app.tar.gz --to-context dr-cluster --dry-run`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Args:          cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return o.Complete(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run()
+		},
+	}
+
+	o.addFlags(cmd)
+
+	return cmd
+}
+
+// completeImport takes the bundle argument of import and rejects the flags
+// that select or discover source resources, which the bundle fixed at export.
+func (o *Options) completeImport(args []string) error {
+	o.BundlePath = args[0]
+	switch {
+	case o.SourceNamespace != "":
+		return fmt.Errorf("--namespace selects source resources; import takes them from the bundle (pass --to-namespace to choose the target)")
+	case o.ToName != "":
+		return fmt.Errorf("--to-name cannot be used with import")
+	case o.Recursive || o.FollowOwner || o.IncludeGateways || o.IncludeSTSPVCs || o.SkipSecrets || o.KeepHelmMetadata || o.PreserveNodePorts || o.PreserveClusterIP || o.PreserveNodeName != "" || o.WithPV:
+		return fmt.Errorf("--recursive, --follow-owner, --include-gateways, --include-sts-pvcs, --skip-secrets, --keep-helm-metadata, --with-pv, and the --preserve flags apply at export, not import")
+	case o.WithData || o.Prune:
+		return fmt.Errorf("--with-data and --prune need the source cluster and cannot be used with import")
+	}
+	return nil
+}
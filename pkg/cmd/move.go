@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// NewMoveCommand creates the move subcommand: a copy that deletes the
+// sources once everything was created in the target.
+func NewMoveCommand() *cobra.Command {
+	o := &Options{Move: true}
+
+	cmd := &cobra.Command{
+		Use:   "move (<resource>/<name> | <resource> <name>) [flags]",
+		Short: "Copy resources, then delete the originals",
+		Long: `Copy resources like kubectl copy does, then delete the source resources.
+
+Sources are only deleted when every resource in the copy was created (or was
+already identical) in the target, and with --wait only once the copied
+workloads are ready. If anything failed, nothing is deleted. Sources are
+deleted in the reverse of the order the copy created them in, so Ingresses go
+before Services and workloads before their configuration.`,
+		Example: `  # Move a deployment and everything it uses to another namespace
+  kubectl copy move deployment/myapp --to-namespace new-ns -r
+  kubectl copy move deployment myapp --to-namespace new-ns -r
+
+  # Wait for the copy to be ready before deleting, and keep the source Secrets
+  kubectl copy move deployment/myapp --to-namespace new-ns -r --wait --keep-source-secrets
+
+  # Preview the move
+  kubectl copy move deployment/myapp --to-namespace new-ns -r --dry-run`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Args:          cobra.RangeArgs(1, 2),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return o.Complete(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run()
+		},
+	}
+
+	o.addFlags(cmd)
+	cmd.Flags().BoolVar(&o.KeepSourceSecrets, "keep-source-secrets", false, "do not delete the source Secrets")
+	cmd.Flags().BoolVar(&o.Wait, "wait", false, "wait for the copied workloads to become ready before deleting the sources")
+	cmd.Flags().DurationVar(&o.WaitTimeout, "wait-timeout", 5*time.Minute, "with --wait, how long to wait for readiness before giving up without deleting anything")
+
+	return cmd
+}
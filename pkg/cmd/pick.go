@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/a13x22/kube-copy/pkg/client"
+	"github.com/a13x22/kube-copy/pkg/output"
+)
+
+// pickResources lists the resources of o.ResourceKind in the source
+// namespace and asks which of them to copy, for a run on a terminal that
+// named a type but no resource.
+func (o *Options) pickResources(ctx context.Context) ([]string, error) {
+	conn := o.connection()
+	if err := client.ValidateContexts(conn); err != nil {
+		return nil, err
+	}
+	clients, err := client.NewSourceOnly(conn)
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to cluster: %w\n    Check your kubeconfig and network connectivity.", err)
+	}
+	resolved, err := clients.Resolve(o.ResourceKind)
+	if err != nil {
+		return nil, err
+	}
+	ns, where := o.SourceNamespace, fmt.Sprintf("namespace %q", o.SourceNamespace)
+	if !resolved.Namespaced {
+		ns, where = "", "the source cluster"
+	}
+	list, err := clients.SourceDynamic.Resource(resolved.GVR).Namespace(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing %s in %s: %w", resolved.GVR.Resource, where, err)
+	}
+	if len(list.Items) == 0 {
+		return nil, fmt.Errorf("no %s in %s to copy", resolved.GVR.Resource, where)
+	}
+	names := make([]string, len(list.Items))
+	for i, item := range list.Items {
+		names[i] = item.GetName()
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(os.Stderr, "\n  %s in %s:\n", resolved.Kind, where)
+	output.PrintChoices(os.Stderr, names)
+	for {
+		fmt.Fprintf(os.Stderr, "  Copy which? (a number, or comma-separated numbers): ")
+		answer, ok := readAnswer(ctx)
+		if !ok || answer == "" {
+			return nil, fmt.Errorf("no %s chosen", resolved.Kind)
+		}
+		chosen, err := output.ParseChoices(answer, len(names))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "  invalid answer: %s\n", err)
+			continue
+		}
+		if len(chosen) > 1 && o.ToName != "" {
+			fmt.Fprintf(os.Stderr, "  invalid answer: --to-name names a single copy; choose one\n")
+			continue
+		}
+		picked := make([]string, len(chosen))
+		for i, c := range chosen {
+			picked[i] = names[c]
+		}
+		fmt.Fprintln(os.Stderr)
+		return picked, nil
+	}
+}
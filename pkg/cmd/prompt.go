@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/a13x22/kube-copy/pkg/copier"
+	"github.com/a13x22/kube-copy/pkg/output"
+	"github.com/a13x22/kube-copy/pkg/sanitizer"
+)
+
+// stdin is shared by every prompt, so answers typed ahead are not lost
+// between them.
+var stdin = bufio.NewReader(os.Stdin)
+
+// interactive reports whether prompts can be answered: stdin and stderr,
+// where they are shown, are terminals.
+func interactive() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stderr.Fd()))
+}
+
+// readAnswer reads a line from stdin, trimmed. It returns false when ctx is
+// canceled while waiting or stdin is closed.
+func readAnswer(ctx context.Context) (string, bool) {
+	answers := make(chan string, 1)
+	go func() {
+		answer, err := stdin.ReadString('\n')
+		if err != nil && answer == "" {
+			close(answers)
+			return
+		}
+		answers <- strings.TrimSpace(answer)
+	}()
+
+	select {
+	case <-ctx.Done():
+		return "", false
+	case answer, ok := <-answers:
+		return answer, ok
+	}
+}
+
+// conflictPrompter asks, for --on-conflict=prompt, what to do with each
+// resource that already exists in the target. The capitalized answers apply
+// to every resource asked about after it too.
+type conflictPrompter struct {
+	ctx  context.Context
+	prog *output.ProgressReporter
+	out  io.Writer // stderr, through prog
+
+	all    *copier.Decision // answer given for the remaining resources
+	suffix string           // appended to the names for a rename of all
+}
+
+// resolve is the copier.Copier.Resolve function.
+func (p *conflictPrompter) resolve(r *copier.CopyResult) copier.Decision {
+	if p.all != nil {
+		d := *p.all
+		if d.Action == "rename" {
+			d.Name = sanitizer.GenerateName(r.Source.Kind, r.TargetName, p.suffix)
+		}
+		return d
+	}
+
+	// Workers still report steps while the user answers; they are shown
+	// once the prompt is done
+	p.prog.Clear()
+	resume := p.prog.Pause()
+	defer resume()
+	where := "the target cluster"
+	if r.Source.Namespaced {
+		where = "namespace " + r.TargetNS
+	}
+	fmt.Fprintf(p.out, "\n  %s/%s already exists in %s", r.Source.Kind, r.TargetName, where)
+	if len(r.Diff) > 0 {
+		fmt.Fprintf(p.out, " and differs from the copy in %d field(s):\n", len(r.Diff))
+		output.PrintDiff(p.out, r.Diff)
+	} else {
+		fmt.Fprintln(p.out, ".")
+	}
+
+	for {
+		fmt.Fprintf(p.out, "  [s]kip, [o]verwrite, [r]ename, [a]bort (S, O, R: for all remaining): ")
+		answer, ok := readAnswer(p.ctx)
+		if !ok {
+			return copier.Decision{Action: "abort"}
+		}
+		switch answer {
+		case "s", "skip":
+			return copier.Decision{Action: "skip"}
+		case "o", "overwrite":
+			return copier.Decision{Action: "overwrite"}
+		case "S", "O":
+			d := copier.Decision{Action: "skip"}
+			if answer == "O" {
+				d.Action = "overwrite"
+			}
+			p.all = &d
+			return d
+		case "r", "rename":
+			name, ok := p.ask("  New name", sanitizer.GenerateName(r.Source.Kind, r.TargetName, "-copy"), func(name string) error {
+				if name == r.TargetName {
+					return fmt.Errorf("must differ from the existing name")
+				}
+				return sanitizer.ValidateName(r.Source.Kind, name)
+			})
+			if ok {
+				return copier.Decision{Action: "rename", Name: name}
+			}
+		case "R":
+			suffix, ok := p.ask("  Suffix for every new name", "-copy", func(suffix string) error {
+				if suffix == "" {
+					return fmt.Errorf("must not be empty")
+				}
+				// Names are shortened to fit the suffix, so only its characters matter
+				return sanitizer.ValidateName(r.Source.Kind, sanitizer.GenerateName(r.Source.Kind, r.TargetName, suffix))
+			})
+			if ok {
+				p.suffix = suffix
+				p.all = &copier.Decision{Action: "rename"}
+				return copier.Decision{Action: "rename", Name: sanitizer.GenerateName(r.Source.Kind, r.TargetName, suffix)}
+			}
+		case "a", "abort", "A":
+			fmt.Fprintf(p.out, "  Aborted.\n\n")
+			return copier.Decision{Action: "abort"}
+		}
+	}
+}
+
+// ask asks question, offering def, and returns the answer when validate
+// finds nothing wrong with it. Otherwise it says why and returns false.
+func (p *conflictPrompter) ask(question, def string, validate func(string) error) (string, bool) {
+	fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+	answer, ok := readAnswer(p.ctx)
+	if !ok {
+		return "", false
+	}
+	if answer == "" {
+		answer = def
+	}
+	if err := validate(answer); err != nil {
+		fmt.Fprintf(p.out, "  invalid answer: %s\n", err)
+		return "", false
+	}
+	return answer, true
+}
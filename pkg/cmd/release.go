@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// NewReleaseCommand creates the release subcommand, which copies every
+// resource of a Helm release.
+func NewReleaseCommand() *cobra.Command {
+	o := &Options{Release: true}
+
+	cmd := &cobra.Command{
+		Use:   "release <release-name> [flags]",
+		Short: "Copy the resources of a Helm release",
+		Long: `Copy every resource of a Helm release in the source namespace, like
+kubectl copy does for a single resource.
+
+The release's resources are the objects Helm annotated as installed by it
+(meta.helm.sh/release-name) and the objects labeled
+app.kubernetes.io/instance=<release-name>; labeled objects without the Helm
+annotations are copied with a warning. The sh.helm.release.v1.* Secrets
+holding the release's revisions are copied too, with the namespace they
+record rewritten, so helm in the target recognizes the release. Objects
+maintained by controllers, such as the Pods of a Deployment, are left to
+them, and cluster-scoped objects of the release are not copied.`,
+		Example: `  # Copy the myapp release to the staging namespace
+  kubectl copy release myapp -n prod --to-namespace staging
+
+  # Preview the copy into another cluster
+  kubectl copy release myapp -n prod --to-context staging-cluster --dry-run`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Args:          cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return o.Complete(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run()
+		},
+	}
+
+	o.addFlags(cmd)
+
+	return cmd
+}
+
+// completeRelease takes the release argument of release, rejects the flags
+// that only apply to a single resource, and defaults the namespaces.
+func (o *Options) completeRelease(args []string) error {
+	o.ReleaseName = args[0]
+	switch {
+	case o.ToName != "":
+		return fmt.Errorf("--to-name cannot be used with release: its resources keep their names")
+	case o.Recursive || o.FollowOwner || o.IncludeGateways || o.IncludeSTSPVCs:
+		return fmt.Errorf("--recursive, --follow-owner, --include-gateways, and --include-sts-pvcs cannot be used with release, which copies the release's own resources")
+	case o.SkipSecrets:
+		return fmt.Errorf("--skip-secrets cannot be used with release: helm in the target would manage a release whose Secrets are missing")
+	}
+
+	if o.SourceNamespace == "" {
+		o.SourceNamespace = getDefaultNamespace(o.SourceKubeconfig, o.SourceContext)
+	}
+	o.defaultTargetNamespace()
+	if o.ToNamespace == o.SourceNamespace && o.ToContext == "" && o.ToKubeconfig == "" && o.ToCluster == "" {
+		return fmt.Errorf("copying a release within its own namespace would collide with it; pass --to-namespace or a target cluster")
+	}
+	return nil
+}
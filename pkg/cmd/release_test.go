@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReleaseRejectsSingleResourceFlags(t *testing.T) {
+	tests := []struct {
+		flag string
+		want string
+	}{
+		{"--skip-secrets", "--skip-secrets cannot be used with release"},
+		{"--to-name=other", "--to-name cannot be used with release"},
+		{"--recursive", "cannot be used with release"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.flag, func(t *testing.T) {
+			clients, cluster := fakeClients(t)
+			_, err := runCopy(t, clients, "release", "myapp", "-n", "prod", "--to-namespace", "staging", "-y", tt.flag)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("release %s: error %v, want %q", tt.flag, err, tt.want)
+			}
+			if actions := cluster.Actions(); len(actions) > 0 {
+				t.Errorf("release %s sent %d requests before failing", tt.flag, len(actions))
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package conflict
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var selfSubjectAccessReviewGVR = schema.GroupVersionResource{Group: "authorization.k8s.io", Version: "v1", Resource: "selfsubjectaccessreviews"}
+
+// DetectAdmission issues a server-side dry-run Create of the object against
+// the target and reports any rejection (admission webhooks, validation) as a
+// conflict. The dry-run is skipped when the caller is not allowed to create
+// the resource, so read-only planning keeps working.
+func DetectAdmission(ctx context.Context, targetClient dynamic.Interface, gvr schema.GroupVersionResource, obj *unstructured.Unstructured, targetNS string) []Conflict {
+	if !canCreate(ctx, targetClient, gvr, targetNS) {
+		return nil
+	}
+
+	_, err := targetClient.Resource(gvr).Namespace(targetNS).Create(ctx, obj, metav1.CreateOptions{
+		DryRun: []string{metav1.DryRunAll},
+	})
+	if err == nil || apierrors.IsAlreadyExists(err) || apierrors.IsNotFound(err) {
+		return nil
+	}
+	if _, ok := err.(apierrors.APIStatus); !ok {
+		// Transport problems are not admission decisions
+		return nil
+	}
+
+	return []Conflict{{
+		Type:     TypeAdmission,
+		Severity: SeverityError,
+		Resource: fmt.Sprintf("%s/%s", obj.GetKind(), obj.GetName()),
+		Message:  fmt.Sprintf("target rejected server-side dry-run create: %v", err),
+	}}
+}
+
+// canCreate asks the target API server whether the current user may create
+// the given resource in the namespace.
+func canCreate(ctx context.Context, client dynamic.Interface, gvr schema.GroupVersionResource, namespace string) bool {
+	review := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "authorization.k8s.io/v1",
+		"kind":       "SelfSubjectAccessReview",
+		"spec": map[string]interface{}{
+			"resourceAttributes": map[string]interface{}{
+				"verb":      "create",
+				"group":     gvr.Group,
+				"version":   gvr.Version,
+				"resource":  gvr.Resource,
+				"namespace": namespace,
+			},
+		},
+	}}
+	resp, err := client.Resource(selfSubjectAccessReviewGVR).Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return false
+	}
+	allowed, _, _ := unstructured.NestedBool(resp.Object, "status", "allowed")
+	return allowed
+}
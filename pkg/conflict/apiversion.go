@@ -0,0 +1,48 @@
+package conflict
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// DetectAPIAvailability checks that the target cluster serves the resource's
+// group/version. Version-skewed clusters otherwise fail only at create time
+// with "the server could not find the requested resource".
+func DetectAPIAvailability(mapper meta.RESTMapper, gvr schema.GroupVersionResource, kind, name string) []Conflict {
+	if mapper == nil || kind == "" {
+		return nil
+	}
+
+	gk := schema.GroupKind{Group: gvr.Group, Kind: kind}
+	if _, err := mapper.RESTMapping(gk, gvr.Version); err == nil {
+		return nil
+	}
+
+	identifier := fmt.Sprintf("%s/%s", kind, name)
+	objectGV := gvr.GroupVersion().String()
+
+	// Look for any other served version of the same group/kind
+	mappings, err := mapper.RESTMappings(gk)
+	if err != nil || len(mappings) == 0 {
+		return []Conflict{{
+			Type:     TypeAPIVersion,
+			Severity: SeverityError,
+			Resource: identifier,
+			Message:  fmt.Sprintf("target cluster does not serve %s (object is %s)", kind, objectGV),
+		}}
+	}
+
+	var served []string
+	for _, m := range mappings {
+		served = append(served, m.GroupVersionKind.GroupVersion().String())
+	}
+	return []Conflict{{
+		Type:     TypeAPIVersion,
+		Severity: SeverityError,
+		Resource: identifier,
+		Message:  fmt.Sprintf("target serves %s but object is %s", strings.Join(served, ", "), objectGV),
+	}}
+}
@@ -0,0 +1,63 @@
+package conflict
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var (
+	issuerGVR        = schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "issuers"}
+	clusterIssuerGVR = schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "clusterissuers"}
+)
+
+// detectIssuerConflicts checks that the Issuer or ClusterIssuer a copied
+// cert-manager Certificate references exists in the target. Issuers of
+// external groups are not checked. When cert-manager is not installed in the
+// target the lookup reports the issuer as missing, which is accurate enough:
+// the Certificate will never be issued either way.
+func detectIssuerConflicts(ctx context.Context, targetClient dynamic.Interface, obj *unstructured.Unstructured, targetNS string, batch Batch) []Conflict {
+	if obj.GetKind() != "Certificate" || obj.GroupVersionKind().Group != "cert-manager.io" {
+		return nil
+	}
+
+	name, _, _ := unstructured.NestedString(obj.Object, "spec", "issuerRef", "name")
+	kind, _, _ := unstructured.NestedString(obj.Object, "spec", "issuerRef", "kind")
+	group, _, _ := unstructured.NestedString(obj.Object, "spec", "issuerRef", "group")
+	if name == "" || (group != "" && group != "cert-manager.io") {
+		return nil
+	}
+
+	gvr, ns, where := issuerGVR, targetNS, fmt.Sprintf("target namespace %q", targetNS)
+	if kind == "ClusterIssuer" {
+		gvr, ns, where = clusterIssuerGVR, "", "the target cluster"
+	} else {
+		kind = "Issuer"
+	}
+	if batch.Contains(gvr.GroupResource(), ns, name) {
+		return nil
+	}
+
+	identifier := fmt.Sprintf("Certificate/%s", obj.GetName())
+	exists, err := resourceExists(ctx, targetClient, gvr, name, ns)
+	switch {
+	case err != nil:
+		return []Conflict{{
+			Type:     TypeUnverified,
+			Severity: SeverityWarning,
+			Resource: identifier,
+			Message:  fmt.Sprintf("unable to verify %s %q exists in %s (%s)", kind, name, where, errorReason(err)),
+		}}
+	case !exists:
+		return []Conflict{{
+			Type:     TypeReference,
+			Severity: SeverityWarning,
+			Resource: identifier,
+			Message:  fmt.Sprintf("references %s %q which does not exist in %s -- the certificate will not be issued", kind, name, where),
+		}}
+	}
+	return nil
+}
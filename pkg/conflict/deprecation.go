@@ -0,0 +1,74 @@
+package conflict
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// apiLifecycle records the Kubernetes minor versions in which a group/version
+// was deprecated and removed. An empty Kind applies to every kind in the group/version.
+type apiLifecycle struct {
+	GroupVersion schema.GroupVersion
+	Kind         string
+	Deprecated   uint // minor version, 0 if unknown
+	Removed      uint // minor version
+	Replacement  string
+}
+
+// deprecatedAPIs is a small static table of the well-known API removals.
+var deprecatedAPIs = []apiLifecycle{
+	{GroupVersion: schema.GroupVersion{Group: "extensions", Version: "v1beta1"}, Kind: "Ingress", Deprecated: 14, Removed: 22, Replacement: "networking.k8s.io/v1"},
+	{GroupVersion: schema.GroupVersion{Group: "extensions", Version: "v1beta1"}, Deprecated: 9, Removed: 16, Replacement: "apps/v1"},
+	{GroupVersion: schema.GroupVersion{Group: "apps", Version: "v1beta1"}, Deprecated: 9, Removed: 16, Replacement: "apps/v1"},
+	{GroupVersion: schema.GroupVersion{Group: "apps", Version: "v1beta2"}, Deprecated: 9, Removed: 16, Replacement: "apps/v1"},
+	{GroupVersion: schema.GroupVersion{Group: "networking.k8s.io", Version: "v1beta1"}, Deprecated: 19, Removed: 22, Replacement: "networking.k8s.io/v1"},
+	{GroupVersion: schema.GroupVersion{Group: "rbac.authorization.k8s.io", Version: "v1beta1"}, Deprecated: 17, Removed: 22, Replacement: "rbac.authorization.k8s.io/v1"},
+	{GroupVersion: schema.GroupVersion{Group: "batch", Version: "v1beta1"}, Kind: "CronJob", Deprecated: 21, Removed: 25, Replacement: "batch/v1"},
+	{GroupVersion: schema.GroupVersion{Group: "policy", Version: "v1beta1"}, Kind: "PodDisruptionBudget", Deprecated: 21, Removed: 25, Replacement: "policy/v1"},
+	{GroupVersion: schema.GroupVersion{Group: "policy", Version: "v1beta1"}, Kind: "PodSecurityPolicy", Deprecated: 21, Removed: 25, Replacement: "Pod Security admission"},
+	{GroupVersion: schema.GroupVersion{Group: "autoscaling", Version: "v2beta1"}, Deprecated: 22, Removed: 25, Replacement: "autoscaling/v2"},
+	{GroupVersion: schema.GroupVersion{Group: "autoscaling", Version: "v2beta2"}, Deprecated: 23, Removed: 26, Replacement: "autoscaling/v2"},
+	{GroupVersion: schema.GroupVersion{Group: "discovery.k8s.io", Version: "v1beta1"}, Deprecated: 21, Removed: 25, Replacement: "discovery.k8s.io/v1"},
+	{GroupVersion: schema.GroupVersion{Group: "storage.k8s.io", Version: "v1beta1"}, Kind: "CSIStorageCapacity", Deprecated: 24, Removed: 27, Replacement: "storage.k8s.io/v1"},
+	{GroupVersion: schema.GroupVersion{Group: "flowcontrol.apiserver.k8s.io", Version: "v1beta2"}, Deprecated: 26, Removed: 29, Replacement: "flowcontrol.apiserver.k8s.io/v1"},
+	{GroupVersion: schema.GroupVersion{Group: "flowcontrol.apiserver.k8s.io", Version: "v1beta3"}, Deprecated: 29, Removed: 32, Replacement: "flowcontrol.apiserver.k8s.io/v1"},
+}
+
+// DetectDeprecatedAPI reports when the object's group/version is deprecated or
+// removed in the target cluster's Kubernetes minor version. targetVersion may
+// be nil when the target version could not be determined.
+func DetectDeprecatedAPI(gvr schema.GroupVersionResource, kind, name string, targetVersion *version.Version) []Conflict {
+	if targetVersion == nil || targetVersion.Major() != 1 {
+		return nil
+	}
+	minor := targetVersion.Minor()
+	identifier := fmt.Sprintf("%s/%s", kind, name)
+
+	for _, api := range deprecatedAPIs {
+		if api.GroupVersion != gvr.GroupVersion() || (api.Kind != "" && api.Kind != kind) {
+			continue
+		}
+		switch {
+		case minor >= api.Removed:
+			return []Conflict{{
+				Type:     TypeDeprecatedAPI,
+				Severity: SeverityError,
+				Resource: identifier,
+				Message: fmt.Sprintf("%s %s was removed in Kubernetes 1.%d and the target runs %s (use %s)",
+					api.GroupVersion, kind, api.Removed, targetVersion, api.Replacement),
+			}}
+		case api.Deprecated > 0 && minor >= api.Deprecated:
+			return []Conflict{{
+				Type:     TypeDeprecatedAPI,
+				Severity: SeverityWarning,
+				Resource: identifier,
+				Message: fmt.Sprintf("%s %s is deprecated in the target's Kubernetes %s and removed in 1.%d (use %s)",
+					api.GroupVersion, kind, targetVersion, api.Removed, api.Replacement),
+			}}
+		}
+		return nil
+	}
+	return nil
+}
@@ -3,7 +3,9 @@ package conflict
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -14,62 +16,215 @@ import (
 type Type string
 
 const (
-	TypeExistence Type = "existence" // resource already exists in target
-	TypeAddress   Type = "address"   // hardcoded network address conflict
-	TypeReference Type = "reference" // missing referenced resource in target
+	TypeExistence       Type = "existence"        // resource already exists in target
+	TypeAddress         Type = "address"          // hardcoded network address conflict
+	TypeReference       Type = "reference"        // missing referenced resource in target
+	TypeIngressHost     Type = "ingress-host"     // host already claimed by another Ingress in target
+	TypeAPIVersion      Type = "api-version"      // resource group/version not served by target
+	TypeQuota           Type = "quota"            // target ResourceQuota would be exceeded
+	TypePodSecurity     Type = "pod-security"     // pod spec violates target namespace's Pod Security level
+	TypeAdmission       Type = "admission"        // target rejected a server-side dry-run create
+	TypeImmutable       Type = "immutable"        // existing target differs in a field that cannot be updated
+	TypeStorage         Type = "storage"          // PVC's StorageClass or its CSI driver missing in target
+	TypeDeprecatedAPI   Type = "deprecated-api"   // group/version deprecated or removed in target's Kubernetes version
+	TypeServiceSelector Type = "service-selector" // existing target Service already selects the copied pods
+	TypeUnverified      Type = "unverified"       // a lookup in the target failed for a reason other than NotFound
+	TypeGitOps          Type = "gitops"           // existing target is managed by Argo CD or Flux
+	TypeScheduling      Type = "scheduling"       // no target node satisfies the pod's node selector or required affinity
+	TypeLimitRange      Type = "limit-range"      // target LimitRange rejects the resources of a container
+)
+
+// Types lists every conflict type, in the order they are documented.
+var Types = []Type{
+	TypeExistence,
+	TypeAddress,
+	TypeReference,
+	TypeIngressHost,
+	TypeAPIVersion,
+	TypeQuota,
+	TypePodSecurity,
+	TypeAdmission,
+	TypeImmutable,
+	TypeStorage,
+	TypeDeprecatedAPI,
+	TypeServiceSelector,
+	TypeUnverified,
+	TypeGitOps,
+	TypeScheduling,
+	TypeLimitRange,
+}
+
+// ParseType converts a user-supplied conflict type name to a Type.
+func ParseType(s string) (Type, error) {
+	for _, t := range Types {
+		if string(t) == s {
+			return t, nil
+		}
+	}
+	names := make([]string, len(Types))
+	for i, t := range Types {
+		names[i] = string(t)
+	}
+	return "", fmt.Errorf("unknown conflict type %q: must be one of %s", s, strings.Join(names, ", "))
+}
+
+// Severity grades how serious a conflict is.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"   // creating the resource as planned would fail or clobber data
+	SeverityWarning Severity = "warning" // informational; the create can still proceed
+	SeverityNote    Severity = "note"    // expected by the user's own configuration; never blocks
 )
 
 // Conflict describes a single detected conflict.
 type Conflict struct {
 	Type     Type
+	Severity Severity
 	Resource string // e.g. "Service/my-svc"
 	Message  string
 }
 
+// BatchKey identifies a resource by its target namespace and name.
+type BatchKey struct {
+	Resource  schema.GroupResource
+	Namespace string
+	Name      string
+}
+
+// Batch is the set of resources created by the same copy run, which map to
+// an empty string. Resources deliberately excluded from the run map to why,
+// e.g. "kubecopy.io/ignore" for the label.
+type Batch map[BatchKey]string
+
+// Contains reports whether the batch will create the given resource.
+func (b Batch) Contains(resource schema.GroupResource, namespace, name string) bool {
+	excludedBy, ok := b[BatchKey{Resource: resource, Namespace: namespace, Name: name}]
+	return ok && excludedBy == ""
+}
+
+// ExcludedBy returns why the given resource was deliberately left out of the
+// batch, or an empty string when it was not.
+func (b Batch) ExcludedBy(resource schema.GroupResource, namespace, name string) string {
+	return b[BatchKey{Resource: resource, Namespace: namespace, Name: name}]
+}
+
 // Detect runs all pre-flight conflict checks for a resource about to be created.
-func Detect(ctx context.Context, targetClient dynamic.Interface, gvr schema.GroupVersionResource, obj *unstructured.Unstructured, targetNS string) []Conflict {
+// batch may be nil when the resource is copied on its own.
+func Detect(ctx context.Context, targetClient dynamic.Interface, gvr schema.GroupVersionResource, obj *unstructured.Unstructured, targetNS string, batch Batch) []Conflict {
 	var conflicts []Conflict
 
 	name := obj.GetName()
 	identifier := fmt.Sprintf("%s/%s", obj.GetKind(), name)
 
 	// 1. Existence check (targetNS is empty for cluster-scoped resources)
-	_, err := targetClient.Resource(gvr).Namespace(targetNS).Get(ctx, name, metav1.GetOptions{})
-	if err == nil {
+	exists, err := resourceExists(ctx, targetClient, gvr, name, targetNS)
+	if err != nil {
+		conflicts = append(conflicts, Conflict{
+			Type:     TypeUnverified,
+			Severity: SeverityWarning,
+			Resource: identifier,
+			Message:  fmt.Sprintf("unable to verify whether %s already exists in the target (%s)", identifier, errorReason(err)),
+		})
+	} else if exists {
 		msg := fmt.Sprintf("%s already exists in namespace %q", identifier, targetNS)
 		if targetNS == "" {
 			msg = fmt.Sprintf("%s already exists", identifier)
 		}
 		conflicts = append(conflicts, Conflict{
 			Type:     TypeExistence,
+			Severity: SeverityError,
 			Resource: identifier,
 			Message:  msg,
 		})
 	}
 
 	// 2. Address conflicts (resource-specific)
-	conflicts = append(conflicts, detectAddressConflicts(obj)...)
+	conflicts = append(conflicts, detectAddressConflicts(ctx, targetClient, obj, targetNS)...)
 
 	// 3. Reference conflicts
-	conflicts = append(conflicts, detectReferenceConflicts(ctx, targetClient, obj, targetNS)...)
+	conflicts = append(conflicts, detectReferenceConflicts(ctx, targetClient, obj, targetNS, batch)...)
+
+	// 4. Ingress host collisions
+	conflicts = append(conflicts, detectIngressHostConflicts(ctx, targetClient, obj, targetNS)...)
+
+	// 5. ResourceQuota capacity
+	conflicts = append(conflicts, detectQuotaConflicts(ctx, targetClient, obj, targetNS)...)
+	conflicts = append(conflicts, detectLimitRangeConflicts(ctx, targetClient, obj, targetNS)...)
+
+	// 6. Pod Security admission level
+	conflicts = append(conflicts, detectPodSecurityConflicts(ctx, targetClient, obj, targetNS)...)
+
+	// 7. Storage provisioning
+	conflicts = append(conflicts, detectStorageConflicts(ctx, targetClient, obj)...)
+
+	// 8. cert-manager issuer of a Certificate
+	conflicts = append(conflicts, detectIssuerConflicts(ctx, targetClient, obj, targetNS, batch)...)
+
+	// 9. Gateway API parent Gateways of an HTTPRoute
+	conflicts = append(conflicts, detectGatewayConflicts(ctx, targetClient, obj, targetNS, batch)...)
+
+	// 10. Role or ClusterRole granted by a RoleBinding or ClusterRoleBinding
+	conflicts = append(conflicts, detectRoleRefConflicts(ctx, targetClient, obj, targetNS, batch)...)
 
 	return conflicts
 }
 
 // detectAddressConflicts checks for hardcoded network addresses that would conflict.
-func detectAddressConflicts(obj *unstructured.Unstructured) []Conflict {
+func detectAddressConflicts(ctx context.Context, targetClient dynamic.Interface, obj *unstructured.Unstructured, targetNS string) []Conflict {
 	kind := obj.GetKind()
 	switch kind {
 	case "Service":
-		return detectServiceAddressConflicts(obj)
+		return detectServiceAddressConflicts(ctx, targetClient, obj, targetNS)
 	default:
+		return detectHostPortConflicts(obj)
+	}
+}
+
+// detectHostPortConflicts reports the hostPorts of a pod spec's containers:
+// a port already bound on a target node keeps the pod off it, and some CNI
+// plugins do not support hostPort at all.
+func detectHostPortConflicts(obj *unstructured.Unstructured) []Conflict {
+	podSpec := extractPodSpec(obj)
+	if podSpec == nil {
 		return nil
 	}
+	var conflicts []Conflict
+	identifier := fmt.Sprintf("%s/%s", obj.GetKind(), obj.GetName())
+	for _, field := range []string{"initContainers", "containers"} {
+		containers, _ := podSpec[field].([]interface{})
+		for _, c := range containers {
+			container, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _ := container["name"].(string)
+			ports, _ := container["ports"].([]interface{})
+			for _, p := range ports {
+				port, ok := p.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				if hostPort, ok, _ := unstructured.NestedInt64(port, "hostPort"); ok && hostPort != 0 {
+					conflicts = append(conflicts, Conflict{
+						Type:     TypeAddress,
+						Severity: SeverityWarning,
+						Resource: identifier,
+						Message:  fmt.Sprintf("%s container %q binds hostPort %d, which may already be taken on the target's nodes or unsupported by its CNI plugin", obj.GetKind(), name, hostPort),
+					})
+				}
+			}
+		}
+	}
+	return conflicts
 }
 
-// detectServiceAddressConflicts checks if a Service still has hardcoded addresses
-// after sanitization (which should have cleared them, but we double-check).
-func detectServiceAddressConflicts(obj *unstructured.Unstructured) []Conflict {
+// detectServiceAddressConflicts checks a Service that still has a hardcoded
+// clusterIP or nodePorts, as kept by --preserve-cluster-ip and
+// --preserve-nodeport, against the Services of the target cluster: an
+// address another Service holds fails the create, so it is an error. When
+// the Services cannot be listed, the addresses are reported as warnings.
+func detectServiceAddressConflicts(ctx context.Context, targetClient dynamic.Interface, obj *unstructured.Unstructured, targetNS string) []Conflict {
 	var conflicts []Conflict
 	identifier := fmt.Sprintf("Service/%s", obj.GetName())
 
@@ -78,31 +233,43 @@ func detectServiceAddressConflicts(obj *unstructured.Unstructured) []Conflict {
 		return nil
 	}
 
-	// Check for residual clusterIP (should have been cleared by sanitizer)
-	if clusterIP, ok := spec["clusterIP"].(string); ok && clusterIP != "" && clusterIP != "None" {
-		conflicts = append(conflicts, Conflict{
-			Type:     TypeAddress,
-			Resource: identifier,
-			Message:  fmt.Sprintf("Service has hardcoded clusterIP %s that may conflict", clusterIP),
-		})
-	}
-
-	// Check for hardcoded nodePorts
-	if ports, ok := spec["ports"].([]interface{}); ok {
-		for _, p := range ports {
-			port, ok := p.(map[string]interface{})
-			if !ok {
+	clusterIPs := serviceClusterIPs(spec)
+	nodePorts := serviceNodePorts(spec)
+	if len(clusterIPs) > 0 || len(nodePorts) > 0 {
+		allocated, err := allocatedServiceAddresses(ctx, targetClient, targetNS, obj.GetName())
+		for _, ip := range clusterIPs {
+			c := Conflict{
+				Type:     TypeAddress,
+				Severity: SeverityWarning,
+				Resource: identifier,
+				Message:  fmt.Sprintf("Service has hardcoded clusterIP %s that may conflict", ip),
+			}
+			owner, taken := allocated["ip/"+ip]
+			switch {
+			case err == nil && !taken:
 				continue
+			case taken:
+				c.Severity = SeverityError
+				c.Message = fmt.Sprintf("clusterIP %s is already allocated to Service %s in the target", ip, owner)
 			}
-			if np, ok := port["nodePort"]; ok {
-				if npVal, ok := toInt64(np); ok && npVal > 0 {
-					conflicts = append(conflicts, Conflict{
-						Type:     TypeAddress,
-						Resource: identifier,
-						Message:  fmt.Sprintf("Service has hardcoded nodePort %d that may conflict", npVal),
-					})
-				}
+			conflicts = append(conflicts, c)
+		}
+		for _, np := range nodePorts {
+			c := Conflict{
+				Type:     TypeAddress,
+				Severity: SeverityWarning,
+				Resource: identifier,
+				Message:  fmt.Sprintf("Service has hardcoded nodePort %d that may conflict", np),
+			}
+			owner, taken := allocated[fmt.Sprintf("port/%d", np)]
+			switch {
+			case err == nil && !taken:
+				continue
+			case taken:
+				c.Severity = SeverityError
+				c.Message = fmt.Sprintf("nodePort %d is already allocated to Service %s in the target", np, owner)
 			}
+			conflicts = append(conflicts, c)
 		}
 	}
 
@@ -110,6 +277,7 @@ func detectServiceAddressConflicts(obj *unstructured.Unstructured) []Conflict {
 	if lbIP, ok := spec["loadBalancerIP"].(string); ok && lbIP != "" {
 		conflicts = append(conflicts, Conflict{
 			Type:     TypeAddress,
+			Severity: SeverityWarning,
 			Resource: identifier,
 			Message:  fmt.Sprintf("Service has hardcoded loadBalancerIP %s that may conflict", lbIP),
 		})
@@ -118,60 +286,139 @@ func detectServiceAddressConflicts(obj *unstructured.Unstructured) []Conflict {
 	return conflicts
 }
 
+// serviceClusterIPs returns the clusterIPs a Service spec asks for, without
+// "None" for headless Services.
+func serviceClusterIPs(spec map[string]interface{}) []string {
+	var ips []string
+	seen := map[string]bool{}
+	add := func(ip string) {
+		if ip != "" && ip != "None" && !seen[ip] {
+			seen[ip] = true
+			ips = append(ips, ip)
+		}
+	}
+	if ip, ok := spec["clusterIP"].(string); ok {
+		add(ip)
+	}
+	list, _ := spec["clusterIPs"].([]interface{})
+	for _, v := range list {
+		if ip, ok := v.(string); ok {
+			add(ip)
+		}
+	}
+	return ips
+}
+
+// serviceNodePorts returns the nodePorts a Service spec asks for.
+func serviceNodePorts(spec map[string]interface{}) []int64 {
+	var nodePorts []int64
+	ports, _ := spec["ports"].([]interface{})
+	for _, p := range ports {
+		port, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if np, ok := toInt64(port["nodePort"]); ok && np > 0 {
+			nodePorts = append(nodePorts, np)
+		}
+	}
+	return nodePorts
+}
+
+// allocatedServiceAddresses maps the clusterIPs ("ip/<address>") and
+// nodePorts ("port/<number>") held by the Services of the target cluster to
+// the namespace/name of the Service holding them. The Service being copied
+// over, targetNS/name, is left out: overwriting it frees its addresses.
+func allocatedServiceAddresses(ctx context.Context, targetClient dynamic.Interface, targetNS, name string) (map[string]string, error) {
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "services"}
+	list, err := targetClient.Resource(gvr).Namespace("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	allocated := map[string]string{}
+	for _, svc := range list.Items {
+		if svc.GetNamespace() == targetNS && svc.GetName() == name {
+			continue
+		}
+		owner := svc.GetNamespace() + "/" + svc.GetName()
+		spec, ok := svc.Object["spec"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, ip := range serviceClusterIPs(spec) {
+			allocated["ip/"+ip] = owner
+		}
+		for _, np := range serviceNodePorts(spec) {
+			allocated[fmt.Sprintf("port/%d", np)] = owner
+		}
+	}
+	return allocated, nil
+}
+
 // detectReferenceConflicts checks whether resources referenced by the object
-// exist in the target namespace/cluster.
-func detectReferenceConflicts(ctx context.Context, targetClient dynamic.Interface, obj *unstructured.Unstructured, targetNS string) []Conflict {
+// exist in the target namespace/cluster. References satisfied by another
+// resource in the same copy batch are not reported.
+func detectReferenceConflicts(ctx context.Context, targetClient dynamic.Interface, obj *unstructured.Unstructured, targetNS string, batch Batch) []Conflict {
 	var conflicts []Conflict
 	identifier := fmt.Sprintf("%s/%s", obj.GetKind(), obj.GetName())
 
-	// Extract pod spec (works for Deployment, StatefulSet, DaemonSet, Job, Pod, etc.)
-	podSpec := extractPodSpec(obj)
-	if podSpec == nil {
-		return nil
-	}
-
-	// Check ConfigMap references
-	for _, cmName := range extractConfigMapRefs(podSpec) {
-		if !resourceExists(ctx, targetClient, schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}, cmName, targetNS) {
+	check := func(resource, label, name string) {
+		gvr := schema.GroupVersionResource{Version: "v1", Resource: resource}
+		if batch.Contains(gvr.GroupResource(), targetNS, name) {
+			return
+		}
+		exists, err := resourceExists(ctx, targetClient, gvr, name, targetNS)
+		switch {
+		case err != nil:
 			conflicts = append(conflicts, Conflict{
-				Type:     TypeReference,
+				Type:     TypeUnverified,
+				Severity: SeverityWarning,
 				Resource: identifier,
-				Message:  fmt.Sprintf("references ConfigMap %q which does not exist in target namespace %q (consider --recursive)", cmName, targetNS),
+				Message:  fmt.Sprintf("unable to verify %s %q exists in target namespace %q (%s)", label, name, targetNS, errorReason(err)),
 			})
-		}
-	}
-
-	// Check Secret references
-	for _, secretName := range extractSecretRefs(podSpec) {
-		if !resourceExists(ctx, targetClient, schema.GroupVersionResource{Version: "v1", Resource: "secrets"}, secretName, targetNS) {
+		case !exists && batch.ExcludedBy(gvr.GroupResource(), targetNS, name) != "":
 			conflicts = append(conflicts, Conflict{
 				Type:     TypeReference,
+				Severity: SeverityNote,
 				Resource: identifier,
-				Message:  fmt.Sprintf("references Secret %q which does not exist in target namespace %q (consider --recursive)", secretName, targetNS),
+				Message: fmt.Sprintf("references %s %q which was intentionally excluded from the copy (%s) and does not exist in target namespace %q",
+					label, name, batch.ExcludedBy(gvr.GroupResource(), targetNS, name), targetNS),
 			})
-		}
-	}
-
-	// Check PVC references
-	for _, pvcName := range extractPVCRefs(podSpec) {
-		if !resourceExists(ctx, targetClient, schema.GroupVersionResource{Version: "v1", Resource: "persistentvolumeclaims"}, pvcName, targetNS) {
+		case !exists:
 			conflicts = append(conflicts, Conflict{
 				Type:     TypeReference,
+				Severity: SeverityWarning,
 				Resource: identifier,
-				Message:  fmt.Sprintf("references PVC %q which does not exist in target namespace %q (consider --recursive)", pvcName, targetNS),
+				Message:  fmt.Sprintf("references %s %q which does not exist in target namespace %q (consider --recursive)", label, name, targetNS),
 			})
 		}
 	}
 
-	// Check ServiceAccount references
-	if saName := extractServiceAccountRef(podSpec); saName != "" && saName != "default" {
-		if !resourceExists(ctx, targetClient, schema.GroupVersionResource{Version: "v1", Resource: "serviceaccounts"}, saName, targetNS) {
-			conflicts = append(conflicts, Conflict{
-				Type:     TypeReference,
-				Resource: identifier,
-				Message:  fmt.Sprintf("references ServiceAccount %q which does not exist in target namespace %q (consider --recursive)", saName, targetNS),
-			})
+	// Ingress TLS secrets
+	if obj.GetKind() == "Ingress" {
+		for _, secretName := range extractIngressTLSSecretRefs(obj) {
+			check("secrets", "TLS Secret", secretName)
 		}
+		return conflicts
+	}
+
+	// Extract pod spec (works for Deployment, StatefulSet, DaemonSet, Job, Pod, etc.)
+	podSpec := extractPodSpec(obj)
+	if podSpec == nil {
+		return nil
+	}
+
+	for _, cmName := range extractConfigMapRefs(podSpec) {
+		check("configmaps", "ConfigMap", cmName)
+	}
+	for _, secretName := range extractSecretRefs(podSpec) {
+		check("secrets", "Secret", secretName)
+	}
+	for _, pvcName := range extractPVCRefs(podSpec) {
+		check("persistentvolumeclaims", "PVC", pvcName)
+	}
+	if saName := extractServiceAccountRef(podSpec); saName != "" && saName != "default" {
+		check("serviceaccounts", "ServiceAccount", saName)
 	}
 
 	return conflicts
@@ -333,6 +580,14 @@ func extractSecretRefs(podSpec map[string]interface{}) []string {
 					refs = append(refs, name)
 				}
 			}
+			if csi, ok := vol["csi"].(map[string]interface{}); ok {
+				if ref, ok := csi["nodePublishSecretRef"].(map[string]interface{}); ok {
+					if name, ok := ref["name"].(string); ok && !seen[name] {
+						seen[name] = true
+						refs = append(refs, name)
+					}
+				}
+			}
 			if projected, ok := vol["projected"].(map[string]interface{}); ok {
 				if sources, ok := projected["sources"].([]interface{}); ok {
 					for _, s := range sources {
@@ -352,6 +607,20 @@ func extractSecretRefs(podSpec map[string]interface{}) []string {
 		}
 	}
 
+	// From imagePullSecrets
+	if pullSecrets, ok := podSpec["imagePullSecrets"].([]interface{}); ok {
+		for _, ps := range pullSecrets {
+			entry, ok := ps.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if name, ok := entry["name"].(string); ok && !seen[name] {
+				seen[name] = true
+				refs = append(refs, name)
+			}
+		}
+	}
+
 	// From envFrom and env.valueFrom in containers
 	for _, containerField := range []string{"containers", "initContainers"} {
 		containers, ok := podSpec[containerField].([]interface{})
@@ -435,9 +704,32 @@ func extractServiceAccountRef(podSpec map[string]interface{}) string {
 }
 
 // resourceExists checks if a resource exists in the target namespace.
-func resourceExists(ctx context.Context, client dynamic.Interface, gvr schema.GroupVersionResource, name, namespace string) bool {
+// Only NotFound means the resource is missing; any other error (RBAC,
+// transport) is returned so callers don't claim the resource is absent.
+func resourceExists(ctx context.Context, client dynamic.Interface, gvr schema.GroupVersionResource, name, namespace string) (bool, error) {
 	_, err := client.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
-	return err == nil
+	switch {
+	case err == nil:
+		return true, nil
+	case apierrors.IsNotFound(err):
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// errorReason returns a short description of why a lookup failed.
+func errorReason(err error) string {
+	switch {
+	case apierrors.IsForbidden(err):
+		return "forbidden"
+	case apierrors.IsUnauthorized(err):
+		return "unauthorized"
+	case apierrors.IsTimeout(err) || apierrors.IsServerTimeout(err):
+		return "timeout"
+	default:
+		return err.Error()
+	}
 }
 
 // toInt64 converts a numeric interface to int64.
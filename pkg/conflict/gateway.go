@@ -0,0 +1,65 @@
+package conflict
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var gatewayGVR = schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "gateways"}
+
+// detectGatewayConflicts checks that every Gateway a copied HTTPRoute
+// attaches to exists in the target or is part of the copy batch. A route
+// without its parent Gateway is never programmed.
+func detectGatewayConflicts(ctx context.Context, targetClient dynamic.Interface, obj *unstructured.Unstructured, targetNS string, batch Batch) []Conflict {
+	if obj.GetKind() != "HTTPRoute" {
+		return nil
+	}
+
+	var conflicts []Conflict
+	identifier := fmt.Sprintf("HTTPRoute/%s", obj.GetName())
+
+	parents, _, _ := unstructured.NestedSlice(obj.Object, "spec", "parentRefs")
+	for _, p := range parents {
+		parent, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		group, _ := parent["group"].(string)
+		kind, _ := parent["kind"].(string)
+		name, _ := parent["name"].(string)
+		if (group != "" && group != gatewayGVR.Group) || (kind != "" && kind != "Gateway") || name == "" {
+			continue
+		}
+		ns, _ := parent["namespace"].(string)
+		if ns == "" {
+			ns = targetNS
+		}
+		if batch.Contains(gatewayGVR.GroupResource(), ns, name) {
+			continue
+		}
+
+		exists, err := resourceExists(ctx, targetClient, gatewayGVR, name, ns)
+		switch {
+		case err != nil:
+			conflicts = append(conflicts, Conflict{
+				Type:     TypeUnverified,
+				Severity: SeverityWarning,
+				Resource: identifier,
+				Message:  fmt.Sprintf("unable to verify Gateway %q exists in target namespace %q (%s)", name, ns, errorReason(err)),
+			})
+		case !exists:
+			conflicts = append(conflicts, Conflict{
+				Type:     TypeReference,
+				Severity: SeverityWarning,
+				Resource: identifier,
+				Message:  fmt.Sprintf("attaches to Gateway %q which does not exist in target namespace %q (consider --include-gateways)", name, ns),
+			})
+		}
+	}
+
+	return conflicts
+}
@@ -0,0 +1,66 @@
+package conflict
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Markers GitOps controllers leave on the objects they manage.
+const (
+	argoTrackingAnnotation = "argocd.argoproj.io/tracking-id" // "<app>:<group>/<kind>:<namespace>/<name>"
+	argoInstanceLabel      = "argocd.argoproj.io/instance"
+
+	fluxKustomizationNameLabel      = "kustomize.toolkit.fluxcd.io/name"
+	fluxKustomizationNamespaceLabel = "kustomize.toolkit.fluxcd.io/namespace"
+	fluxHelmReleaseNameLabel        = "helm.toolkit.fluxcd.io/name"
+	fluxHelmReleaseNamespaceLabel   = "helm.toolkit.fluxcd.io/namespace"
+)
+
+// DetectGitOps reports when the live object a copy would replace is managed
+// by Argo CD or Flux, which revert or prune the copy on their next sync.
+// The owning Application, Kustomization, or HelmRelease is named when the
+// markers tell it.
+func DetectGitOps(copied, live *unstructured.Unstructured) []Conflict {
+	if copied == nil || live == nil {
+		return nil
+	}
+	owner := gitOpsOwner(live)
+	if owner == "" {
+		return nil
+	}
+	return []Conflict{{
+		Type:     TypeGitOps,
+		Severity: SeverityWarning,
+		Resource: fmt.Sprintf("%s/%s", copied.GetKind(), copied.GetName()),
+		Message:  fmt.Sprintf("the existing target is managed by %s, which will likely revert or prune the copy", owner),
+	}}
+}
+
+// gitOpsOwner describes the GitOps controller managing obj, or returns an
+// empty string when there is none.
+func gitOpsOwner(obj *unstructured.Unstructured) string {
+	labels := obj.GetLabels()
+	qualified := func(namespace, name string) string {
+		if namespace == "" {
+			return fmt.Sprintf("%q", name)
+		}
+		return fmt.Sprintf("%q", namespace+"/"+name)
+	}
+	if name := labels[fluxKustomizationNameLabel]; name != "" {
+		return "Flux Kustomization " + qualified(labels[fluxKustomizationNamespaceLabel], name)
+	}
+	if name := labels[fluxHelmReleaseNameLabel]; name != "" {
+		return "Flux HelmRelease " + qualified(labels[fluxHelmReleaseNamespaceLabel], name)
+	}
+	if id := obj.GetAnnotations()[argoTrackingAnnotation]; id != "" {
+		// Applications outside Argo CD's namespace are tracked as "<namespace>_<app>"
+		app, _, _ := strings.Cut(id, ":")
+		return "Argo CD Application " + qualified("", strings.Replace(app, "_", "/", 1))
+	}
+	if app := labels[argoInstanceLabel]; app != "" {
+		return "Argo CD Application " + qualified("", app)
+	}
+	return ""
+}
@@ -0,0 +1,71 @@
+package conflict
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// immutableFields lists, per kind, the field paths the API server refuses to
+// change on update. Paths are dot-separated from the object root.
+var immutableFields = map[string][]string{
+	"Service":               {"spec.clusterIP"},
+	"PersistentVolumeClaim": {"spec.accessModes", "spec.storageClassName", "spec.volumeMode", "spec.selector", "spec.dataSource"},
+	"Deployment":            {"spec.selector"},
+	"ReplicaSet":            {"spec.selector"},
+	"DaemonSet":             {"spec.selector"},
+	"StatefulSet":           {"spec.selector", "spec.serviceName", "spec.volumeClaimTemplates", "spec.podManagementPolicy"},
+	"Job":                   {"spec.selector", "spec.template", "spec.completions"},
+	"RoleBinding":           {"roleRef"},
+	"ClusterRoleBinding":    {"roleRef"},
+}
+
+// DetectImmutable compares the sanitized copy against the live object in the
+// target and reports known-immutable fields whose values differ. Such
+// differences mean the target cannot be updated in place and requires
+// delete+recreate. Fields left empty by sanitization are not compared.
+func DetectImmutable(copied, live *unstructured.Unstructured) []Conflict {
+	if copied == nil || live == nil {
+		return nil
+	}
+
+	var conflicts []Conflict
+	identifier := fmt.Sprintf("%s/%s", copied.GetKind(), copied.GetName())
+
+	for _, path := range immutableFields[copied.GetKind()] {
+		fields := strings.Split(path, ".")
+		want, found, _ := unstructured.NestedFieldNoCopy(copied.Object, fields...)
+		if !found || want == "" {
+			continue
+		}
+		have, _, _ := unstructured.NestedFieldNoCopy(live.Object, fields...)
+		if !reflect.DeepEqual(want, have) {
+			conflicts = append(conflicts, Conflict{
+				Type:     TypeImmutable,
+				Severity: SeverityWarning,
+				Resource: identifier,
+				Message:  fmt.Sprintf("immutable field %s differs from the target -- requires delete+recreate", path),
+			})
+		}
+	}
+
+	// Immutable ConfigMaps and Secrets reject any data change
+	if copied.GetKind() == "ConfigMap" || copied.GetKind() == "Secret" {
+		if immutable, _, _ := unstructured.NestedBool(live.Object, "immutable"); immutable {
+			for _, field := range []string{"data", "binaryData"} {
+				if !reflect.DeepEqual(copied.Object[field], live.Object[field]) {
+					conflicts = append(conflicts, Conflict{
+						Type:     TypeImmutable,
+						Severity: SeverityWarning,
+						Resource: identifier,
+						Message:  fmt.Sprintf("target %s is immutable and its %s differs -- requires delete+recreate", copied.GetKind(), field),
+					})
+				}
+			}
+		}
+	}
+
+	return conflicts
+}
@@ -0,0 +1,150 @@
+package conflict
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var ingressGVR = schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"}
+
+// detectIngressHostConflicts checks whether any host claimed by the copied
+// Ingress is already claimed by another Ingress anywhere in the target cluster.
+// Some controllers merge rules for the same host, so this is informational.
+func detectIngressHostConflicts(ctx context.Context, targetClient dynamic.Interface, obj *unstructured.Unstructured, targetNS string) []Conflict {
+	if obj.GetKind() != "Ingress" {
+		return nil
+	}
+
+	copiedRules := ingressHostPaths(obj)
+	if len(copiedRules) == 0 {
+		return nil
+	}
+
+	list, err := targetClient.Resource(ingressGVR).Namespace("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil
+	}
+
+	hosts := make([]string, 0, len(copiedRules))
+	for host := range copiedRules {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	var conflicts []Conflict
+	identifier := fmt.Sprintf("Ingress/%s", obj.GetName())
+
+	for i := range list.Items {
+		existing := &list.Items[i]
+		// The object itself is covered by the existence check
+		if existing.GetNamespace() == targetNS && existing.GetName() == obj.GetName() {
+			continue
+		}
+		existingRules := ingressHostPaths(existing)
+		for _, host := range hosts {
+			claimedPaths, ok := existingRules[host]
+			if !ok {
+				continue
+			}
+			overlap := "paths do not overlap"
+			if pathsOverlap(copiedRules[host], claimedPaths) {
+				overlap = "paths overlap"
+			}
+			conflicts = append(conflicts, Conflict{
+				Type:     TypeIngressHost,
+				Severity: SeverityWarning,
+				Resource: identifier,
+				Message: fmt.Sprintf("host %q is already claimed by Ingress %s/%s in the target (%s)",
+					host, existing.GetNamespace(), existing.GetName(), overlap),
+			})
+		}
+	}
+
+	return conflicts
+}
+
+// ingressHostPaths returns the paths declared for each host in an Ingress's rules.
+// A rule without an http block claims the whole host and is recorded as "/".
+func ingressHostPaths(ing *unstructured.Unstructured) map[string][]string {
+	spec, ok := ing.Object["spec"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	rules, ok := spec["rules"].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	result := map[string][]string{}
+	for _, r := range rules {
+		rule, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		host, _ := rule["host"].(string)
+		if host == "" {
+			continue
+		}
+		http, ok := rule["http"].(map[string]interface{})
+		if !ok {
+			result[host] = append(result[host], "/")
+			continue
+		}
+		paths, _ := http["paths"].([]interface{})
+		for _, p := range paths {
+			path, ok := p.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			value, _ := path["path"].(string)
+			if value == "" {
+				value = "/"
+			}
+			result[host] = append(result[host], value)
+		}
+	}
+	return result
+}
+
+// pathsOverlap reports whether any path in a is equal to or a prefix of a path
+// in b (or vice versa). Prefix matching is deliberately conservative since
+// pathType semantics vary across controllers.
+func pathsOverlap(a, b []string) bool {
+	for _, pa := range a {
+		for _, pb := range b {
+			if strings.HasPrefix(pa, pb) || strings.HasPrefix(pb, pa) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// extractIngressTLSSecretRefs returns the secretName of each spec.tls entry.
+func extractIngressTLSSecretRefs(ing *unstructured.Unstructured) []string {
+	tls, found, err := unstructured.NestedSlice(ing.Object, "spec", "tls")
+	if err != nil || !found {
+		return nil
+	}
+
+	seen := map[string]bool{}
+	var refs []string
+	for _, t := range tls {
+		entry, ok := t.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if name, ok := entry["secretName"].(string); ok && name != "" && !seen[name] {
+			seen[name] = true
+			refs = append(refs, name)
+		}
+	}
+	return refs
+}
@@ -0,0 +1,46 @@
+package conflict
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func ingress(namespace, name string, hosts ...string) *unstructured.Unstructured {
+	var rules []interface{}
+	for _, host := range hosts {
+		rules = append(rules, map[string]interface{}{"host": host})
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "networking.k8s.io/v1",
+		"kind":       "Ingress",
+		"metadata":   map[string]interface{}{"namespace": namespace, "name": name},
+		"spec":       map[string]interface{}{"rules": rules},
+	}}
+}
+
+func TestDetectIngressHostConflictsInHostOrder(t *testing.T) {
+	hosts := []string{"e.example.com", "b.example.com", "d.example.com", "a.example.com", "c.example.com"}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{ingressGVR: "IngressList"},
+		ingress("prod", "web", hosts...))
+
+	copied := ingress("prod", "web", hosts...)
+	copied.SetName("web-copy")
+	want := slices.Sorted(slices.Values(hosts))
+	for range 10 {
+		var got []string
+		for _, c := range detectIngressHostConflicts(context.Background(), client, copied, "prod") {
+			got = append(got, strings.Split(c.Message, `"`)[1])
+		}
+		if !slices.Equal(got, want) {
+			t.Fatalf("conflicts for hosts %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package conflict
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var limitRangeGVR = schema.GroupVersionResource{Version: "v1", Resource: "limitranges"}
+
+// detectLimitRangeConflicts evaluates each container of the copied pod spec
+// against the Container limits of the LimitRanges in the target namespace,
+// as the LimitRanger admission plugin would: requests and limits the
+// container omits take the LimitRange's defaults, then both must lie
+// between min and max and their ratio within maxLimitRequestRatio. A
+// violation makes the target reject the pods, which for a workload only
+// shows once it tries to create them, so each is reported.
+func detectLimitRangeConflicts(ctx context.Context, targetClient dynamic.Interface, obj *unstructured.Unstructured, targetNS string) []Conflict {
+	podSpec := extractPodSpec(obj)
+	if podSpec == nil || targetNS == "" {
+		return nil
+	}
+
+	list, err := targetClient.Resource(limitRangeGVR).Namespace(targetNS).List(ctx, metav1.ListOptions{})
+	if err != nil || len(list.Items) == 0 {
+		return nil
+	}
+
+	var conflicts []Conflict
+	identifier := fmt.Sprintf("%s/%s", obj.GetKind(), obj.GetName())
+	for _, lr := range list.Items {
+		limits, _, _ := unstructured.NestedSlice(lr.Object, "spec", "limits")
+		for _, l := range limits {
+			item, ok := l.(map[string]interface{})
+			if !ok || item["type"] != "Container" {
+				continue
+			}
+			for _, field := range []string{"initContainers", "containers"} {
+				containers, _ := podSpec[field].([]interface{})
+				for _, c := range containers {
+					container, ok := c.(map[string]interface{})
+					if !ok {
+						continue
+					}
+					name, _ := container["name"].(string)
+					for _, problem := range limitRangeProblems(item, container) {
+						conflicts = append(conflicts, Conflict{
+							Type:     TypeLimitRange,
+							Severity: SeverityWarning,
+							Resource: identifier,
+							Message:  fmt.Sprintf("container %q %s, so LimitRange %q in target namespace %q rejects its pods", name, problem, lr.GetName(), targetNS),
+						})
+					}
+				}
+			}
+		}
+	}
+	return conflicts
+}
+
+// limitRangeProblems describes how a container violates one Container item
+// of a LimitRange, after the item's defaults are applied.
+func limitRangeProblems(item, container map[string]interface{}) []string {
+	requests := quantityStrings(container, "resources", "requests")
+	limits := quantityStrings(container, "resources", "limits")
+	defaults := quantityStrings(item, "default")
+	defaultRequests := quantityStrings(item, "defaultRequest")
+	min := quantityStrings(item, "min")
+	max := quantityStrings(item, "max")
+	ratios := quantityStrings(item, "maxLimitRequestRatio")
+
+	// effective returns a container's value of resource name and where it
+	// came from, or false when it has none
+	effective := func(name string, own, defaults map[string]string, what string) (resource.Quantity, string, bool) {
+		if s, ok := own[name]; ok {
+			if q, err := resource.ParseQuantity(s); err == nil {
+				return q, what, true
+			}
+		}
+		if s, ok := defaults[name]; ok {
+			if q, err := resource.ParseQuantity(s); err == nil {
+				return q, "default " + what, true
+			}
+		}
+		return resource.Quantity{}, "", false
+	}
+
+	var problems []string
+	for _, name := range sortedKeys(min, max, ratios) {
+		limit, limitWhat, hasLimit := effective(name, limits, defaults, "limit")
+		request, requestWhat, hasRequest := effective(name, requests, defaultRequests, "request")
+		if !hasRequest && hasLimit {
+			// The API server defaults a missing request to the limit
+			request, requestWhat, hasRequest = limit, limitWhat, true
+		}
+
+		if s, ok := min[name]; ok {
+			if bound, err := resource.ParseQuantity(s); err == nil {
+				switch {
+				case !hasRequest:
+					problems = append(problems, fmt.Sprintf("has no %s request, below the minimum of %s", name, bound.String()))
+				case request.Cmp(bound) < 0:
+					problems = append(problems, fmt.Sprintf("has a %s %s of %s, below the minimum of %s", name, requestWhat, request.String(), bound.String()))
+				case hasLimit && limit.Cmp(bound) < 0:
+					problems = append(problems, fmt.Sprintf("has a %s %s of %s, below the minimum of %s", name, limitWhat, limit.String(), bound.String()))
+				}
+			}
+		}
+		if s, ok := max[name]; ok {
+			if bound, err := resource.ParseQuantity(s); err == nil {
+				switch {
+				case !hasLimit:
+					problems = append(problems, fmt.Sprintf("has no %s limit, but the maximum is %s", name, bound.String()))
+				case limit.Cmp(bound) > 0:
+					problems = append(problems, fmt.Sprintf("has a %s %s of %s, above the maximum of %s", name, limitWhat, limit.String(), bound.String()))
+				case hasRequest && request.Cmp(bound) > 0:
+					problems = append(problems, fmt.Sprintf("has a %s %s of %s, above the maximum of %s", name, requestWhat, request.String(), bound.String()))
+				}
+			}
+		}
+		if s, ok := ratios[name]; ok && hasLimit && hasRequest && !request.IsZero() {
+			if ratio, err := resource.ParseQuantity(s); err == nil {
+				if float64(limit.MilliValue())/float64(request.MilliValue()) > ratio.AsApproximateFloat64() {
+					problems = append(problems, fmt.Sprintf("has a %s %s of %s for a %s of %s, above the maximum limit/request ratio of %s", name, limitWhat, limit.String(), requestWhat, request.String(), ratio.String()))
+				}
+			}
+		}
+	}
+	return problems
+}
+
+// sortedKeys returns the keys of the maps, without duplicates, in order.
+func sortedKeys(maps ...map[string]string) []string {
+	seen := map[string]bool{}
+	var keys []string
+	for _, m := range maps {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,106 @@
+package conflict
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var namespaceGVR = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+
+// Pod Security admission label and levels.
+const (
+	podSecurityEnforceLabel = "pod-security.kubernetes.io/enforce"
+	podSecurityBaseline     = "baseline"
+	podSecurityRestricted   = "restricted"
+)
+
+// detectPodSecurityConflicts reads the Pod Security admission level enforced
+// on the target namespace and statically checks the copied pod spec for the
+// most common violations. It does not aim for parity with the upstream policy
+// library, only for catching pods that would obviously be rejected.
+func detectPodSecurityConflicts(ctx context.Context, targetClient dynamic.Interface, obj *unstructured.Unstructured, targetNS string) []Conflict {
+	podSpec := extractPodSpec(obj)
+	if podSpec == nil || targetNS == "" {
+		return nil
+	}
+
+	ns, err := targetClient.Resource(namespaceGVR).Get(ctx, targetNS, metav1.GetOptions{})
+	if err != nil {
+		return nil
+	}
+	level := ns.GetLabels()[podSecurityEnforceLabel]
+	if level != podSecurityBaseline && level != podSecurityRestricted {
+		return nil
+	}
+
+	var conflicts []Conflict
+	identifier := fmt.Sprintf("%s/%s", obj.GetKind(), obj.GetName())
+	violation := func(field, problem string) {
+		conflicts = append(conflicts, Conflict{
+			Type:     TypePodSecurity,
+			Severity: SeverityWarning,
+			Resource: identifier,
+			Message:  fmt.Sprintf("%s %s, which target namespace %q rejects (enforce=%s)", field, problem, targetNS, level),
+		})
+	}
+
+	// Baseline checks (also part of restricted)
+	for _, field := range []string{"hostNetwork", "hostPID", "hostIPC"} {
+		if v, _ := podSpec[field].(bool); v {
+			violation("spec."+field, "is true")
+		}
+	}
+	if volumes, ok := podSpec["volumes"].([]interface{}); ok {
+		for _, v := range volumes {
+			vol, ok := v.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if _, ok := vol["hostPath"]; ok {
+				name, _ := vol["name"].(string)
+				violation(fmt.Sprintf("volume %q", name), "uses hostPath")
+			}
+		}
+	}
+
+	podRunAsNonRoot, _, _ := unstructured.NestedBool(podSpec, "securityContext", "runAsNonRoot")
+
+	for _, containerField := range []string{"initContainers", "containers"} {
+		containers, ok := podSpec[containerField].([]interface{})
+		if !ok {
+			continue
+		}
+		for _, c := range containers {
+			container, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _ := container["name"].(string)
+			field := fmt.Sprintf("container %q", name)
+
+			if privileged, _, _ := unstructured.NestedBool(container, "securityContext", "privileged"); privileged {
+				violation(field+" securityContext.privileged", "is true")
+			}
+
+			if level != podSecurityRestricted {
+				continue
+			}
+
+			ape, found, _ := unstructured.NestedBool(container, "securityContext", "allowPrivilegeEscalation")
+			if !found || ape {
+				violation(field+" securityContext.allowPrivilegeEscalation", "is not false")
+			}
+			runAsNonRoot, found, _ := unstructured.NestedBool(container, "securityContext", "runAsNonRoot")
+			if (found && !runAsNonRoot) || (!found && !podRunAsNonRoot) {
+				violation(field+" securityContext.runAsNonRoot", "is not true")
+			}
+		}
+	}
+
+	return conflicts
+}
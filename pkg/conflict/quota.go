@@ -0,0 +1,181 @@
+package conflict
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var resourceQuotaGVR = schema.GroupVersionResource{Version: "v1", Resource: "resourcequotas"}
+
+// quotaResources maps the quota keys we evaluate to the pod-level resource
+// they constrain. Both the "requests."-prefixed and bare forms are honored.
+var quotaResources = []struct {
+	keys     []string
+	resource string
+}{
+	{keys: []string{"requests.cpu", "cpu"}, resource: "cpu"},
+	{keys: []string{"requests.memory", "memory"}, resource: "memory"},
+	{keys: []string{"pods"}, resource: "pods"},
+}
+
+// detectQuotaConflicts checks whether the copied workload's pod-template
+// requests (multiplied by replicas) fit in the remaining ResourceQuota of
+// the target namespace. Quota math is approximate, so this is informational.
+func detectQuotaConflicts(ctx context.Context, targetClient dynamic.Interface, obj *unstructured.Unstructured, targetNS string) []Conflict {
+	podSpec := extractPodSpec(obj)
+	if podSpec == nil || targetNS == "" {
+		return nil
+	}
+
+	list, err := targetClient.Resource(resourceQuotaGVR).Namespace(targetNS).List(ctx, metav1.ListOptions{})
+	if err != nil || len(list.Items) == 0 {
+		return nil
+	}
+
+	replicas := workloadReplicas(obj)
+	requested := podRequests(podSpec)
+	requested["pods"] = *resource.NewQuantity(replicas, resource.DecimalSI)
+	for name, q := range requested {
+		if name == "pods" {
+			continue
+		}
+		total := resource.NewMilliQuantity(q.MilliValue()*replicas, q.Format)
+		requested[name] = *total
+	}
+
+	var conflicts []Conflict
+	identifier := fmt.Sprintf("%s/%s", obj.GetKind(), obj.GetName())
+
+	for _, rq := range list.Items {
+		hard := quantityStrings(rq.Object, "status", "hard")
+		if len(hard) == 0 {
+			hard = quantityStrings(rq.Object, "spec", "hard")
+		}
+		used := quantityStrings(rq.Object, "status", "used")
+
+		for _, qr := range quotaResources {
+			want, ok := requested[qr.resource]
+			if !ok || want.IsZero() {
+				continue
+			}
+			for _, key := range qr.keys {
+				hardStr, ok := hard[key]
+				if !ok {
+					continue
+				}
+				hardQty, err := resource.ParseQuantity(hardStr)
+				if err != nil {
+					continue
+				}
+				available := hardQty.DeepCopy()
+				if usedQty, err := resource.ParseQuantity(used[key]); err == nil {
+					available.Sub(usedQty)
+				}
+				if want.Cmp(available) > 0 {
+					conflicts = append(conflicts, Conflict{
+						Type:     TypeQuota,
+						Severity: SeverityWarning,
+						Resource: identifier,
+						Message: fmt.Sprintf("needs %s %s but ResourceQuota %q in target namespace %q has only %s available (hard %s)",
+							want.String(), key, rq.GetName(), targetNS, available.String(), hardQty.String()),
+					})
+				}
+			}
+		}
+	}
+
+	return conflicts
+}
+
+// workloadReplicas returns how many pods the workload asks for at once.
+// DaemonSets scale with node count, which is not known here, so they count as one.
+func workloadReplicas(obj *unstructured.Unstructured) int64 {
+	var (
+		n     int64
+		found bool
+	)
+	switch obj.GetKind() {
+	case "Deployment", "StatefulSet", "ReplicaSet":
+		n, found, _ = unstructured.NestedInt64(obj.Object, "spec", "replicas")
+	case "Job":
+		n, found, _ = unstructured.NestedInt64(obj.Object, "spec", "parallelism")
+	case "CronJob":
+		n, found, _ = unstructured.NestedInt64(obj.Object, "spec", "jobTemplate", "spec", "parallelism")
+	}
+	if !found {
+		return 1
+	}
+	return n
+}
+
+// podRequests returns the effective cpu/memory requests of a single pod:
+// the sum over containers, or the largest init container if that is bigger.
+// Containers without requests contribute zero.
+func podRequests(podSpec map[string]interface{}) map[string]resource.Quantity {
+	sum := map[string]resource.Quantity{}
+	for name, q := range containerRequests(podSpec, "containers", true) {
+		sum[name] = q
+	}
+	for name, q := range containerRequests(podSpec, "initContainers", false) {
+		if cur, ok := sum[name]; !ok || q.Cmp(cur) > 0 {
+			sum[name] = q
+		}
+	}
+	return sum
+}
+
+// containerRequests aggregates cpu/memory requests for the given container
+// list, either summing them or taking the maximum.
+func containerRequests(podSpec map[string]interface{}, field string, add bool) map[string]resource.Quantity {
+	result := map[string]resource.Quantity{}
+	containers, ok := podSpec[field].([]interface{})
+	if !ok {
+		return result
+	}
+	for _, c := range containers {
+		container, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		requests := quantityStrings(container, "resources", "requests")
+		for _, name := range []string{"cpu", "memory"} {
+			q, err := resource.ParseQuantity(requests[name])
+			if err != nil {
+				continue
+			}
+			cur := result[name]
+			if add {
+				cur.Add(q)
+			} else if q.Cmp(cur) > 0 {
+				cur = q
+			}
+			result[name] = cur
+		}
+	}
+	return result
+}
+
+// quantityStrings reads a resource list at the given path. Quantities may be
+// decoded as strings or bare numbers, so both are normalized to strings.
+func quantityStrings(obj map[string]interface{}, fields ...string) map[string]string {
+	raw, found, err := unstructured.NestedMap(obj, fields...)
+	if err != nil || !found {
+		return nil
+	}
+	result := make(map[string]string, len(raw))
+	for k, v := range raw {
+		switch n := v.(type) {
+		case string:
+			result[k] = n
+		case int64, float64:
+			result[k] = fmt.Sprint(n)
+		}
+	}
+	return result
+}
@@ -0,0 +1,72 @@
+package conflict
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var (
+	roleGVR        = schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "roles"}
+	clusterRoleGVR = schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterroles"}
+)
+
+// detectRoleRefConflicts checks that the Role or ClusterRole a copied
+// RoleBinding or ClusterRoleBinding grants exists in the target or is part
+// of the batch. A binding to a missing role grants nothing, and since
+// roleRef is immutable it cannot be pointed elsewhere once created.
+func detectRoleRefConflicts(ctx context.Context, targetClient dynamic.Interface, obj *unstructured.Unstructured, targetNS string, batch Batch) []Conflict {
+	kind := obj.GetKind()
+	if (kind != "RoleBinding" && kind != "ClusterRoleBinding") || obj.GroupVersionKind().Group != "rbac.authorization.k8s.io" {
+		return nil
+	}
+
+	name, _, _ := unstructured.NestedString(obj.Object, "roleRef", "name")
+	roleKind, _, _ := unstructured.NestedString(obj.Object, "roleRef", "kind")
+	if name == "" {
+		return nil
+	}
+
+	gvr, ns, where := roleGVR, targetNS, fmt.Sprintf("target namespace %q", targetNS)
+	hint := " (consider --recursive)"
+	switch {
+	case roleKind == "ClusterRole":
+		gvr, ns, where, hint = clusterRoleGVR, "", "the target cluster", ""
+	case roleKind != "Role" || kind == "ClusterRoleBinding":
+		return nil // a ClusterRoleBinding can only grant a ClusterRole
+	}
+	if batch.Contains(gvr.GroupResource(), ns, name) {
+		return nil
+	}
+
+	identifier := fmt.Sprintf("%s/%s", kind, obj.GetName())
+	exists, err := resourceExists(ctx, targetClient, gvr, name, ns)
+	switch {
+	case err != nil:
+		return []Conflict{{
+			Type:     TypeUnverified,
+			Severity: SeverityWarning,
+			Resource: identifier,
+			Message:  fmt.Sprintf("unable to verify %s %q exists in %s (%s)", roleKind, name, where, errorReason(err)),
+		}}
+	case !exists && batch.ExcludedBy(gvr.GroupResource(), ns, name) != "":
+		return []Conflict{{
+			Type:     TypeReference,
+			Severity: SeverityNote,
+			Resource: identifier,
+			Message: fmt.Sprintf("grants %s %q which was intentionally excluded from the copy (%s) and does not exist in %s",
+				roleKind, name, batch.ExcludedBy(gvr.GroupResource(), ns, name), where),
+		}}
+	case !exists:
+		return []Conflict{{
+			Type:     TypeReference,
+			Severity: SeverityWarning,
+			Resource: identifier,
+			Message:  fmt.Sprintf("grants %s %q which does not exist in %s -- the binding grants nothing, and its roleRef cannot be changed later%s", roleKind, name, where, hint),
+		}}
+	}
+	return nil
+}
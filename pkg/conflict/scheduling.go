@@ -0,0 +1,351 @@
+package conflict
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var nodeGVR = schema.GroupVersionResource{Version: "v1", Resource: "nodes"}
+
+// Nodes lists the nodes of the target cluster once, on first use, for the
+// scheduling checks of every resource in a copy. It is safe for concurrent
+// use.
+type Nodes struct {
+	Client dynamic.Interface
+
+	once  sync.Once
+	nodes []node
+	err   error
+}
+
+// node is what the scheduling checks need to know about a node.
+type node struct {
+	name   string
+	labels map[string]string
+	taints []taint // NoSchedule and NoExecute only
+	ready  bool
+}
+
+type taint struct {
+	key, value, effect string
+}
+
+func (t taint) String() string {
+	if t.value == "" {
+		return t.key + ":" + t.effect
+	}
+	return t.key + "=" + t.value + ":" + t.effect
+}
+
+// list returns the nodes, or an error when they cannot be listed.
+func (n *Nodes) list(ctx context.Context) ([]node, error) {
+	n.once.Do(func() {
+		list, err := n.Client.Resource(nodeGVR).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			n.err = err
+			return
+		}
+		for _, item := range list.Items {
+			nd := node{name: item.GetName(), labels: item.GetLabels()}
+			taints, _, _ := unstructured.NestedSlice(item.Object, "spec", "taints")
+			for _, t := range taints {
+				m, ok := t.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				key, _ := m["key"].(string)
+				value, _ := m["value"].(string)
+				effect, _ := m["effect"].(string)
+				// Taints of node conditions come and go, and are
+				// tolerated by default for a while
+				if (effect == "NoSchedule" || effect == "NoExecute") && !strings.HasPrefix(key, "node.kubernetes.io/") {
+					nd.taints = append(nd.taints, taint{key, value, effect})
+				}
+			}
+			conditions, _, _ := unstructured.NestedSlice(item.Object, "status", "conditions")
+			for _, c := range conditions {
+				if m, ok := c.(map[string]interface{}); ok && m["type"] == "Ready" {
+					nd.ready = m["status"] == "True"
+				}
+			}
+			n.nodes = append(n.nodes, nd)
+		}
+	})
+	return n.nodes, n.err
+}
+
+// DetectScheduling reports why the pods of a copied pod spec could not be
+// scheduled on any node of the target cluster, so they would stay Pending:
+// nodeSelector entries and required node affinity that no node satisfies,
+// and taints on every node that the pods do not tolerate. Preferred
+// affinity is ignored. A pod spec bound to a node with nodeName bypasses
+// the scheduler, so only that node is checked: a node missing from the
+// target is an error, one that is not Ready a warning. Nothing is reported
+// when the nodes cannot be listed, e.g. without permission, or there are
+// none to check against.
+func DetectScheduling(ctx context.Context, nodes *Nodes, obj *unstructured.Unstructured) []Conflict {
+	podSpec := extractPodSpec(obj)
+	if podSpec == nil || nodes == nil {
+		return nil
+	}
+	list, err := nodes.list(ctx)
+	if err != nil || len(list) == 0 {
+		return nil
+	}
+
+	var conflicts []Conflict
+	identifier := fmt.Sprintf("%s/%s", obj.GetKind(), obj.GetName())
+	if nodeName, _ := podSpec["nodeName"].(string); nodeName != "" {
+		if c, ok := nodeNameProblem(list, nodeName); ok {
+			c.Resource = identifier
+			conflicts = append(conflicts, c)
+		}
+		return conflicts
+	}
+	unschedulable := func(msg string) {
+		conflicts = append(conflicts, Conflict{
+			Type:     TypeScheduling,
+			Severity: SeverityWarning,
+			Resource: identifier,
+			Message:  msg + ", so its pods will not schedule",
+		})
+	}
+	for _, msg := range nodeSelectorProblems(list, podSpec) {
+		unschedulable(msg)
+	}
+	if msg := taintProblem(list, podSpec); msg != "" {
+		unschedulable(msg)
+	}
+	return conflicts
+}
+
+// nodeNameProblem reports when the node a pod spec is bound to is missing
+// from list or not Ready.
+func nodeNameProblem(list []node, nodeName string) (Conflict, bool) {
+	for _, n := range list {
+		if n.name != nodeName {
+			continue
+		}
+		if n.ready {
+			return Conflict{}, false
+		}
+		return Conflict{
+			Type:     TypeScheduling,
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf("node %q is not Ready in the target cluster, so its pods may not start", nodeName),
+		}, true
+	}
+	return Conflict{
+		Type:     TypeScheduling,
+		Severity: SeverityError,
+		Message:  fmt.Sprintf("node %q does not exist in the target cluster, so its pods cannot run", nodeName),
+	}, true
+}
+
+// nodeSelectorProblems describes the nodeSelector entries and required
+// node affinity of podSpec that no node satisfies.
+func nodeSelectorProblems(list []node, podSpec map[string]interface{}) []string {
+	selector, _, _ := unstructured.NestedStringMap(podSpec, "nodeSelector")
+	terms, _, _ := unstructured.NestedSlice(podSpec, "affinity", "nodeAffinity", "requiredDuringSchedulingIgnoredDuringExecution", "nodeSelectorTerms")
+
+	var problems []string
+	keys := make([]string, 0, len(selector))
+	for key := range selector {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !anyNode(list, func(n node) bool { return hasLabel(n, key, selector[key]) }) {
+			problems = append(problems, fmt.Sprintf("no node in the target cluster is labeled %s=%s (nodeSelector)", key, selector[key]))
+		}
+	}
+	if len(problems) == 0 && len(keys) > 1 && !anyNode(list, func(n node) bool {
+		for _, key := range keys {
+			if !hasLabel(n, key, selector[key]) {
+				return false
+			}
+		}
+		return true
+	}) {
+		problems = append(problems, fmt.Sprintf("no node in the target cluster has all the nodeSelector labels %s", formatSelector(selector, keys)))
+	}
+
+	// Terms are alternatives: the pods schedule when any of them matches
+	var required []map[string]interface{}
+	for _, t := range terms {
+		if term, ok := t.(map[string]interface{}); ok {
+			required = append(required, term)
+		}
+	}
+	matched := false
+	for _, term := range required {
+		if anyNode(list, func(n node) bool { return matchesTerm(n, term) }) {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		for _, term := range required {
+			problems = append(problems, fmt.Sprintf("no node in the target cluster matches the required node affinity term %s", formatTerm(term)))
+		}
+	}
+	return problems
+}
+
+// taintProblem describes the taints that keep podSpec off every node, or
+// returns an empty string when some node has no taint it does not
+// tolerate. Only NoSchedule and NoExecute taints count, so the pods
+// provably cannot be scheduled when it reports anything.
+func taintProblem(list []node, podSpec map[string]interface{}) string {
+	tolerations, _, _ := unstructured.NestedSlice(podSpec, "tolerations")
+	var untolerated []string
+	seen := map[string]bool{}
+	for _, n := range list {
+		blocked := false
+		for _, t := range n.taints {
+			if !tolerated(t, tolerations) {
+				blocked = true
+				if !seen[t.String()] {
+					seen[t.String()] = true
+					untolerated = append(untolerated, t.String())
+				}
+			}
+		}
+		if !blocked {
+			return ""
+		}
+	}
+	sort.Strings(untolerated)
+	return fmt.Sprintf("every node in the target cluster has a taint the pod spec does not tolerate (%s)", strings.Join(untolerated, ", "))
+}
+
+// tolerated reports whether any of tolerations tolerates t.
+func tolerated(t taint, tolerations []interface{}) bool {
+	for _, tol := range tolerations {
+		m, ok := tol.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		key, _ := m["key"].(string)
+		operator, _ := m["operator"].(string)
+		value, _ := m["value"].(string)
+		effect, _ := m["effect"].(string)
+		if effect != "" && effect != t.effect {
+			continue
+		}
+		switch {
+		case operator == "Exists" && (key == "" || key == t.key):
+			return true
+		case operator != "Exists" && key == t.key && value == t.value:
+			return true
+		}
+	}
+	return false
+}
+
+func anyNode(nodes []node, match func(node) bool) bool {
+	for _, n := range nodes {
+		if match(n) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasLabel(n node, key, value string) bool {
+	v, ok := n.labels[key]
+	return ok && v == value
+}
+
+// matchesTerm reports whether n satisfies every requirement of a
+// nodeSelectorTerm.
+func matchesTerm(n node, term map[string]interface{}) bool {
+	for _, field := range []string{"matchExpressions", "matchFields"} {
+		requirements, _, _ := unstructured.NestedSlice(term, field)
+		for _, r := range requirements {
+			req, ok := r.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			key, _ := req["key"].(string)
+			value, ok := n.labels[key]
+			if field == "matchFields" {
+				// metadata.name is the only field selectable
+				value, ok = n.name, key == "metadata.name"
+			}
+			if !matchesRequirement(req, value, ok) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// matchesRequirement evaluates a node selector requirement against the
+// value of its key on a node, and whether the node has the key at all.
+func matchesRequirement(req map[string]interface{}, value string, ok bool) bool {
+	operator, _ := req["operator"].(string)
+	values, _, _ := unstructured.NestedStringSlice(req, "values")
+	switch operator {
+	case "In":
+		return ok && slices.Contains(values, value)
+	case "NotIn":
+		return !ok || !slices.Contains(values, value)
+	case "Exists":
+		return ok
+	case "DoesNotExist":
+		return !ok
+	case "Gt", "Lt":
+		if !ok || len(values) != 1 {
+			return false
+		}
+		have, err1 := strconv.ParseInt(value, 10, 64)
+		want, err2 := strconv.ParseInt(values[0], 10, 64)
+		if err1 != nil || err2 != nil {
+			return false
+		}
+		return operator == "Gt" && have > want || operator == "Lt" && have < want
+	}
+	// Unknown operators are rejected by the API server; do not guess
+	return true
+}
+
+func formatSelector(selector map[string]string, keys []string) string {
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key + "=" + selector[key]
+	}
+	return strings.Join(pairs, ",")
+}
+
+// formatTerm renders a nodeSelectorTerm like "zone In (a, b), gpu Exists".
+func formatTerm(term map[string]interface{}) string {
+	var parts []string
+	for _, field := range []string{"matchExpressions", "matchFields"} {
+		requirements, _, _ := unstructured.NestedSlice(term, field)
+		for _, r := range requirements {
+			req, ok := r.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			key, _ := req["key"].(string)
+			operator, _ := req["operator"].(string)
+			part := key + " " + operator
+			if values, _, _ := unstructured.NestedStringSlice(req, "values"); len(values) > 0 {
+				part += " (" + strings.Join(values, ", ") + ")"
+			}
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
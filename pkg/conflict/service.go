@@ -0,0 +1,113 @@
+package conflict
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/a13x22/kube-copy/pkg/selector"
+)
+
+var serviceGVR = schema.GroupVersionResource{Version: "v1", Resource: "services"}
+
+// Services lists the Services of each target namespace once, on first use,
+// for the selector checks of every workload in a copy. It is safe for
+// concurrent use.
+type Services struct {
+	Client dynamic.Interface
+
+	mu         sync.Mutex
+	namespaces map[string]*serviceList
+}
+
+type serviceList struct {
+	once  sync.Once
+	items []unstructured.Unstructured
+	err   error
+}
+
+// list returns the Services of namespace, or an error when they cannot be
+// listed.
+func (s *Services) list(ctx context.Context, namespace string) ([]unstructured.Unstructured, error) {
+	s.mu.Lock()
+	if s.namespaces == nil {
+		s.namespaces = map[string]*serviceList{}
+	}
+	l, ok := s.namespaces[namespace]
+	if !ok {
+		l = &serviceList{}
+		s.namespaces[namespace] = l
+	}
+	s.mu.Unlock()
+
+	l.once.Do(func() {
+		list, err := s.Client.Resource(serviceGVR).Namespace(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			l.err = err
+			return
+		}
+		l.items = list.Items
+	})
+	return l.items, l.err
+}
+
+// DetectServiceSelectors reports existing Services in the target namespace
+// whose selector already matches the copied workload's pod labels. The new
+// pods would immediately receive that Service's traffic. Services that are
+// part of the copy batch are expected to select the pods and are skipped.
+// Nothing is reported when the Services cannot be listed.
+func DetectServiceSelectors(ctx context.Context, services *Services, obj *unstructured.Unstructured, targetNS string, batch Batch) []Conflict {
+	switch obj.GetKind() {
+	case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "Pod":
+	default:
+		return nil
+	}
+
+	podLabels := extractPodTemplateLabels(obj)
+	if len(podLabels) == 0 {
+		return nil
+	}
+
+	items, err := services.list(ctx, targetNS)
+	if err != nil {
+		return nil
+	}
+
+	var conflicts []Conflict
+	identifier := fmt.Sprintf("%s/%s", obj.GetKind(), obj.GetName())
+
+	for i := range items {
+		svc := &items[i]
+		if batch.Contains(serviceGVR.GroupResource(), targetNS, svc.GetName()) {
+			continue
+		}
+		sel, _, _ := unstructured.NestedMap(svc.Object, "spec", "selector")
+		if !selector.MatchesMap(sel, podLabels) {
+			continue
+		}
+		conflicts = append(conflicts, Conflict{
+			Type:     TypeServiceSelector,
+			Severity: SeverityWarning,
+			Resource: identifier,
+			Message: fmt.Sprintf("existing Service %q in target namespace %q already selects these pods (%s) -- they will receive its traffic",
+				svc.GetName(), targetNS, strings.Join(selector.MapKeys(sel), ",")),
+		})
+	}
+
+	return conflicts
+}
+
+// extractPodTemplateLabels returns the labels pods of the workload will carry.
+func extractPodTemplateLabels(obj *unstructured.Unstructured) map[string]string {
+	if obj.GetKind() == "Pod" {
+		return obj.GetLabels()
+	}
+	labels, _, _ := unstructured.NestedStringMap(obj.Object, "spec", "template", "metadata", "labels")
+	return labels
+}
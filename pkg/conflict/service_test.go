@@ -0,0 +1,68 @@
+package conflict
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func service(namespace, name string, selector map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Service",
+		"metadata":   map[string]interface{}{"namespace": namespace, "name": name},
+		"spec":       map[string]interface{}{"selector": selector},
+	}}
+}
+
+func deployment(name string, labels map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": name},
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{"metadata": map[string]interface{}{"labels": labels}},
+		},
+	}}
+}
+
+func TestDetectServiceSelectorsListsOncePerNamespace(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{serviceGVR: "ServiceList"},
+		service("staging", "web", map[string]interface{}{"app": "web"}),
+		service("staging", "api", map[string]interface{}{"app": "api"}),
+		service("qa", "web", map[string]interface{}{"app": "web"}))
+	lists := map[string]int{}
+	client.PrependReactor("list", "services", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		lists[action.GetNamespace()]++
+		return false, nil, nil
+	})
+
+	services := &Services{Client: client}
+	batch := Batch{{Resource: serviceGVR.GroupResource(), Namespace: "staging", Name: "api"}: ""}
+
+	for _, tt := range []struct {
+		workload  *unstructured.Unstructured
+		namespace string
+		want      int
+	}{
+		{deployment("web", map[string]interface{}{"app": "web"}), "staging", 1},
+		{deployment("api", map[string]interface{}{"app": "api"}), "staging", 0}, // its Service is copied with it
+		{deployment("worker", map[string]interface{}{"app": "worker"}), "staging", 0},
+		{deployment("web", map[string]interface{}{"app": "web"}), "qa", 1},
+	} {
+		got := DetectServiceSelectors(context.Background(), services, tt.workload, tt.namespace, batch)
+		if len(got) != tt.want {
+			t.Errorf("%s in %s: %d conflicts %v, want %d", tt.workload.GetName(), tt.namespace, len(got), got, tt.want)
+		}
+	}
+
+	if lists["staging"] != 1 || lists["qa"] != 1 || len(lists) != 2 {
+		t.Errorf("Services listed %v, want once per namespace", lists)
+	}
+}
@@ -0,0 +1,91 @@
+package conflict
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var (
+	storageClassGVR = schema.GroupVersionResource{Group: "storage.k8s.io", Version: "v1", Resource: "storageclasses"}
+	csiDriverGVR    = schema.GroupVersionResource{Group: "storage.k8s.io", Version: "v1", Resource: "csidrivers"}
+)
+
+const defaultStorageClassAnnotation = "storageclass.kubernetes.io/is-default-class"
+
+// detectStorageConflicts checks that the StorageClass a copied PVC asks for
+// exists in the target and that its provisioner's CSI driver is registered
+// there. A class with an uninstalled driver leaves the PVC Pending forever.
+// Lookups the user is not permitted to make are skipped silently.
+func detectStorageConflicts(ctx context.Context, targetClient dynamic.Interface, obj *unstructured.Unstructured) []Conflict {
+	if obj.GetKind() != "PersistentVolumeClaim" {
+		return nil
+	}
+	identifier := fmt.Sprintf("PersistentVolumeClaim/%s", obj.GetName())
+
+	className, found, _ := unstructured.NestedString(obj.Object, "spec", "storageClassName")
+	if found && className == "" {
+		// Explicitly requests no class (static binding)
+		return nil
+	}
+
+	var class *unstructured.Unstructured
+	if found {
+		sc, err := targetClient.Resource(storageClassGVR).Get(ctx, className, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return []Conflict{{
+				Type:     TypeStorage,
+				Severity: SeverityWarning,
+				Resource: identifier,
+				Message:  fmt.Sprintf("StorageClass %q does not exist in the target cluster", className),
+			}}
+		}
+		if err != nil {
+			return nil
+		}
+		class = sc
+	} else {
+		class = defaultStorageClass(ctx, targetClient)
+		if class == nil {
+			return nil
+		}
+		className = class.GetName()
+	}
+
+	provisioner, _, _ := unstructured.NestedString(class.Object, "provisioner")
+	// In-tree provisioners are not CSI drivers
+	if provisioner == "" || strings.HasPrefix(provisioner, "kubernetes.io/") {
+		return nil
+	}
+
+	_, err := targetClient.Resource(csiDriverGVR).Get(ctx, provisioner, metav1.GetOptions{})
+	if !apierrors.IsNotFound(err) {
+		return nil
+	}
+	return []Conflict{{
+		Type:     TypeStorage,
+		Severity: SeverityWarning,
+		Resource: identifier,
+		Message:  fmt.Sprintf("StorageClass %q uses provisioner %q but no such CSIDriver is registered in the target -- the PVC may stay Pending", className, provisioner),
+	}}
+}
+
+// defaultStorageClass returns the target's default StorageClass, if any.
+func defaultStorageClass(ctx context.Context, targetClient dynamic.Interface) *unstructured.Unstructured {
+	list, err := targetClient.Resource(storageClassGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil
+	}
+	for i := range list.Items {
+		if list.Items[i].GetAnnotations()[defaultStorageClassAnnotation] == "true" {
+			return &list.Items[i]
+		}
+	}
+	return nil
+}
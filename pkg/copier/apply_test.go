@@ -0,0 +1,162 @@
+package copier
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var (
+	serviceAccountGVR = schema.GroupVersionResource{Version: "v1", Resource: "serviceaccounts"}
+	roleBindingGVR    = schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "rolebindings"}
+)
+
+func plannedCreate(gvr schema.GroupVersionResource, kind, name string) CopyResult {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(gvr.GroupVersion().String())
+	obj.SetKind(kind)
+	obj.SetName(name)
+	obj.SetNamespace("staging")
+	return CopyResult{
+		Source:     ResourceRef{GVR: gvr, Kind: kind, Name: name, Namespace: "prod", Namespaced: true},
+		TargetNS:   "staging",
+		TargetName: name,
+		TargetGVR:  gvr,
+		Action:     "create",
+		Sanitized:  obj,
+	}
+}
+
+// slowCreates delays the creates of one resource type and records when
+// the first finished.
+type slowCreates struct {
+	dynamic.Interface
+	resource string
+	delay    time.Duration
+	created  *atomic.Bool
+}
+
+func (s slowCreates) Resource(gvr schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	r := s.Interface.Resource(gvr)
+	if gvr.Resource != s.resource {
+		return r
+	}
+	return slowResource{NamespaceableResourceInterface: r, slow: s}
+}
+
+type slowResource struct {
+	dynamic.NamespaceableResourceInterface
+	slow slowCreates
+}
+
+func (r slowResource) Namespace(ns string) dynamic.ResourceInterface {
+	return slowNamespacedResource{ResourceInterface: r.NamespaceableResourceInterface.Namespace(ns), slow: r.slow}
+}
+
+type slowNamespacedResource struct {
+	dynamic.ResourceInterface
+	slow slowCreates
+}
+
+func (r slowNamespacedResource) Create(ctx context.Context, obj *unstructured.Unstructured, opts metav1.CreateOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	time.Sleep(r.slow.delay)
+	created, err := r.ResourceInterface.Create(ctx, obj, opts, subresources...)
+	r.slow.created.Store(true)
+	return created, err
+}
+
+func TestApplyAllParallelWaitsForSamePhaseDependencies(t *testing.T) {
+	// A ServiceAccount and the RoleBinding granting to it share a priority;
+	// the RoleBinding must not be created before the ServiceAccount is
+	sa := plannedCreate(serviceAccountGVR, "ServiceAccount", "worker")
+	rb := plannedCreate(roleBindingGVR, "RoleBinding", "worker")
+	other := plannedCreate(roleBindingGVR, "RoleBinding", "unrelated")
+	planned := orderForApply([]CopyResult{sa, rb, other}, []Dependency{{Dependent: rb.Source, Dependency: sa.Source}})
+
+	var saCreated, rbEarly atomic.Bool
+	fake := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+	fake.PrependReactor("create", "rolebindings", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		obj := action.(k8stesting.CreateAction).GetObject().(*unstructured.Unstructured)
+		if obj.GetName() == "worker" && !saCreated.Load() {
+			rbEarly.Store(true)
+		}
+		return false, nil, nil
+	})
+	// The fake serializes its reactors, so the ServiceAccount is slowed
+	// down outside of them
+	target := slowCreates{Interface: fake, resource: "serviceaccounts", delay: 50 * time.Millisecond, created: &saCreated}
+
+	c := &Copier{TargetClient: target, Parallelism: 3}
+	c.ApplyAll(context.Background(), planned)
+
+	if rbEarly.Load() {
+		t.Error("RoleBinding/worker was created before the ServiceAccount it depends on")
+	}
+	for _, r := range planned {
+		if r.Error != nil || r.Action != "created" {
+			t.Errorf("%s: action %q, error %v; want created", r.Source.DisplayName(), r.Action, r.Error)
+		}
+	}
+}
+
+func TestApplyAllParallelBreaksDependencyCycles(t *testing.T) {
+	a := plannedCreate(serviceAccountGVR, "ServiceAccount", "a")
+	b := plannedCreate(serviceAccountGVR, "ServiceAccount", "b")
+	planned := orderForApply([]CopyResult{a, b}, []Dependency{
+		{Dependent: a.Source, Dependency: b.Source},
+		{Dependent: b.Source, Dependency: a.Source},
+	})
+
+	c := &Copier{TargetClient: dynamicfake.NewSimpleDynamicClient(runtime.NewScheme()), Parallelism: 2}
+	finished := make(chan struct{})
+	go func() {
+		c.ApplyAll(context.Background(), planned)
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("ApplyAll did not finish with a dependency cycle")
+	}
+	for _, r := range planned {
+		if r.Action != "created" {
+			t.Errorf("%s: action %q, want created", r.Source.DisplayName(), r.Action)
+		}
+	}
+}
+
+func TestApplyAllReportsOverwriteDeletedButNotRecreated(t *testing.T) {
+	existing := &unstructured.Unstructured{}
+	existing.SetAPIVersion("v1")
+	existing.SetKind("ServiceAccount")
+	existing.SetName("worker")
+	existing.SetNamespace("staging")
+	target := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), existing)
+	target.PrependReactor("create", "serviceaccounts", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("admission webhook denied the request")
+	})
+
+	sa := plannedCreate(serviceAccountGVR, "ServiceAccount", "worker")
+	sa.Action = "overwrite"
+	planned := []CopyResult{sa}
+	c := &Copier{TargetClient: target}
+	c.ApplyAll(context.Background(), planned)
+
+	if planned[0].Action != "deleted" || planned[0].Error == nil {
+		t.Fatalf("action %q, error %v; want deleted with an error", planned[0].Action, planned[0].Error)
+	}
+	if !strings.Contains(planned[0].Error.Error(), "not recreated") {
+		t.Errorf("error %q does not say the resource was not recreated", planned[0].Error)
+	}
+}
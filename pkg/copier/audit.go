@@ -0,0 +1,29 @@
+package copier
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// AuditEvent describes one write request sent to a cluster, for Copier.Audit.
+type AuditEvent struct {
+	// Source is set for writes to the source cluster, which only moves and
+	// volume transfers make.
+	Source bool
+
+	Verb   string // "create" or "delete"
+	Action string // what the write was for: "create", "overwrite", "rollback", "delete", "prune", "move", or "transfer"
+
+	GVR       schema.GroupVersionResource
+	Namespace string
+	Name      string
+
+	// Err is the request's error, nil when it succeeded.
+	Err error
+}
+
+// audit reports a write request to Audit.
+func (c *Copier) audit(e AuditEvent) {
+	if c.Audit != nil {
+		c.Audit(e)
+	}
+}
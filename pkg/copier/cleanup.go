@@ -0,0 +1,116 @@
+package copier
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/a13x22/kube-copy/pkg/provenance"
+)
+
+// CleanupFilter narrows which copies PlanCleanup selects. Empty fields match
+// anything.
+type CleanupFilter struct {
+	SourceCluster   string
+	SourceNamespace string
+}
+
+// PlanCleanup finds the resources in the target namespace that a copy
+// created -- those carrying the provenance label and source annotations --
+// among the given resource types, and plans each as "delete". Results are in
+// deletion order: the reverse of creation order, so Ingresses go before
+// Services, Services before workloads, and workloads before their config.
+// Resources without provenance are never selected.
+func (c *Copier) PlanCleanup(ctx context.Context, types []ResourceRef, namespace string, filter CleanupFilter) ([]CopyResult, error) {
+	var results []CopyResult
+	for _, t := range types {
+		copies, err := c.listCopies(ctx, t, namespace, filter)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, copies...)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return applyPriority(results[i].Source.Kind) > applyPriority(results[j].Source.Kind)
+	})
+	return results, nil
+}
+
+// listCopies lists the copies of type t in namespace that match filter, each
+// planned as "delete".
+func (c *Copier) listCopies(ctx context.Context, t ResourceRef, namespace string, filter CleanupFilter) ([]CopyResult, error) {
+	list, err := c.TargetClient.Resource(t.GVR).Namespace(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: provenance.LabelManaged + "=true",
+	})
+	if apierrors.IsNotFound(err) || apierrors.IsMethodNotSupported(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("list %s in %s: %w", t.GVR.Resource, namespace, err)
+	}
+
+	var results []CopyResult
+	for _, obj := range list.Items {
+		a := obj.GetAnnotations()
+		sourceName := a[provenance.AnnotationSourceName]
+		if sourceName == "" {
+			continue
+		}
+		if filter.SourceCluster != "" && a[provenance.AnnotationSourceCluster] != filter.SourceCluster {
+			continue
+		}
+		if filter.SourceNamespace != "" && a[provenance.AnnotationSourceNamespace] != filter.SourceNamespace {
+			continue
+		}
+		results = append(results, CopyResult{
+			Source: ResourceRef{
+				GVR:        t.GVR,
+				Kind:       t.Kind,
+				Name:       sourceName,
+				Namespace:  a[provenance.AnnotationSourceNamespace],
+				Namespaced: true,
+			},
+			TargetNS:   namespace,
+			TargetName: obj.GetName(),
+			TargetGVR:  t.GVR,
+			Action:     "delete",
+		})
+	}
+	return results, nil
+}
+
+// ApplyCleanup deletes the resources planned by PlanCleanup or PlanPrune, in
+// order, and marks each "deleted" or "pruned" or records its error. Other
+// results are left alone. Once ctx is canceled the rest are marked
+// "canceled".
+func (c *Copier) ApplyCleanup(ctx context.Context, planned []CopyResult) {
+	for i := range planned {
+		r := &planned[i]
+		if r.Error != nil || (r.Action != "delete" && r.Action != "prune") {
+			continue
+		}
+		if ctx.Err() != nil {
+			r.Action = "canceled"
+			continue
+		}
+
+		err := c.TargetClient.Resource(r.TargetGVR).Namespace(r.TargetNS).Delete(ctx, r.TargetName, metav1.DeleteOptions{})
+		c.audit(AuditEvent{Verb: "delete", Action: r.Action, GVR: r.TargetGVR, Namespace: r.TargetNS, Name: r.TargetName, Err: err})
+		if err != nil && !apierrors.IsNotFound(err) {
+			r.Error = fmt.Errorf("delete %s/%s from %s: %w", r.Source.Kind, r.TargetName, r.TargetNS, err)
+			r.ErrorClass = Classify(err)
+			c.failed(r.Source, r.Error)
+			continue
+		}
+		if r.Action == "prune" {
+			r.Action = "pruned"
+		} else {
+			r.Action = "deleted"
+		}
+		c.completed(r.Source, r.Action)
+	}
+}
@@ -3,14 +3,23 @@ package copier
 import (
 	"context"
 	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"time"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/version"
 	"k8s.io/client-go/dynamic"
 
 	"github.com/a13x22/kube-copy/pkg/conflict"
+	"github.com/a13x22/kube-copy/pkg/provenance"
 	"github.com/a13x22/kube-copy/pkg/sanitizer"
+	"github.com/a13x22/kube-copy/pkg/transfer"
 )
 
 // ResourceRef uniquely identifies a Kubernetes resource to be copied.
@@ -32,14 +41,46 @@ func (r ResourceRef) DisplayName() string {
 
 // CopyResult records what happened with a single resource copy operation.
 type CopyResult struct {
-	Source    ResourceRef
+	Source     ResourceRef
 	TargetName string
 	TargetNS   string
-	Action     string // "create", "skip", "overwrite" (plan); "created", "skipped", "overwritten" (done)
+	Action     string // "create", "skip", "overwrite", "unchanged", "exists", "prune", "done", "aborted" (plan); "created", "skipped", "overwritten", "unchanged", "exists", "moved", "pruned", "rolled back", "aborted", "canceled", "deleted" (done: overwrite deleted the existing object but failed to create)
 	Warnings   []sanitizer.Warning
 	Conflicts  []conflict.Conflict
 	Error      error
+	ErrorClass ErrorClass                 // what kind of failure Error is, for branching without parsing messages
 	Sanitized  *unstructured.Unstructured // the sanitized object
+	Target     *unstructured.Unstructured // the live target object, when it already exists
+	Retries    int                        // transient API errors retried while fetching and applying
+	Diff       []FieldDiff                // differences from the live target object, when it already exists
+
+	// DeletionTime is how long an overwrite took to delete the existing
+	// object, and with foreground propagation its dependents.
+	DeletionTime time.Duration
+
+	// Verification is "verified", "modified", or "unverified" for resources
+	// read back after creation (see Copier.Verify), and empty otherwise.
+	// ReadbackDiff lists what the target changed.
+	Verification string
+	ReadbackDiff []FieldDiff
+
+	// TargetGVR is the resource created in the target. It differs from
+	// Source.GVR when the object was converted to a version the target serves.
+	TargetGVR schema.GroupVersionResource
+
+	// Decision is what Resolve chose for a resource that already existed,
+	// under the ConflictPrompt strategy.
+	Decision *Decision
+
+	preCreated    bool     // PreCreate already ran during planning
+	undecided     bool     // waits for Resolve to decide its existence conflict
+	imagesMatched []string // keys of Copier.Images that matched a container
+	resumedAction string   // the action of a "done" result in the resumed run
+	namespace     bool     // creates a target namespace for the others (see Copier.CreateNamespace)
+	volumeName    string   // the PersistentVolume a claim stays bound to (see Copier.CopyVolumes)
+	volume        bool     // the PersistentVolume of a copied claim (see Copier.CopyVolumes)
+	dataCopied    bool     // CopyData filled the copied claim from its source
+	dependencies  []refID  // the results to apply first (see Copier.Dependencies)
 }
 
 // Progress reports real-time status during copy operations.
@@ -50,24 +91,287 @@ type Progress interface {
 	Checking(displayName string)
 	Creating(displayName, namespace string)
 	Discovered(count int)
+	Transferring(displayName string, bytes int64)
 }
 
 // noopProgress is used when no progress reporter is set.
 type noopProgress struct{}
 
-func (noopProgress) Connecting()                         {}
-func (noopProgress) Fetching(string, string)             {}
-func (noopProgress) Sanitizing(string)                   {}
-func (noopProgress) Checking(string)                     {}
-func (noopProgress) Creating(string, string)             {}
-func (noopProgress) Discovered(int)                      {}
+func (noopProgress) Connecting()                {}
+func (noopProgress) Fetching(string, string)    {}
+func (noopProgress) Sanitizing(string)          {}
+func (noopProgress) Checking(string)            {}
+func (noopProgress) Creating(string, string)    {}
+func (noopProgress) Discovered(int)             {}
+func (noopProgress) Transferring(string, int64) {}
 
-// Copier performs the fetch-sanitize-detect-create pipeline.
+// OutcomeReporter is optionally implemented by a Progress to be told how each
+// resource ended up as soon as that is known: Completed with the final action
+// ("created", "skipped", "rolled back", ...), or Failed. Progress
+// implementations without these methods keep working unchanged.
+type OutcomeReporter interface {
+	Completed(ref ResourceRef, action string)
+	Failed(ref ResourceRef, err error)
+}
+
+// completed reports a resource's final action to the Progress, if it wants it.
+func (c *Copier) completed(ref ResourceRef, action string) {
+	if r, ok := c.progress().(OutcomeReporter); ok {
+		r.Completed(ref, action)
+	}
+}
+
+// failed reports a resource's error to the Progress, if it wants it.
+func (c *Copier) failed(ref ResourceRef, err error) {
+	if r, ok := c.progress().(OutcomeReporter); ok {
+		r.Failed(ref, err)
+	}
+}
+
+// Copier performs the fetch-sanitize-detect-create pipeline. Build one with
+// New; setting the exported fields directly still works but is not validated.
 type Copier struct {
 	SourceClient dynamic.Interface
 	TargetClient dynamic.Interface
-	OnConflict   string // "skip", "warn", "overwrite"
-	Progress     Progress
+	TargetMapper meta.RESTMapper // optional; enables the API version availability check
+	// TargetVersion is the target cluster's Kubernetes version; optional,
+	// enables the deprecated/removed API check.
+	TargetVersion *version.Version
+	// OnConflict is a ConflictStrategy.
+	//
+	// Deprecated: use WithConflictStrategy.
+	OnConflict string
+	Progress   Progress
+
+	// DeletePropagation is how an overwrite deletes the existing object;
+	// empty means foreground, so the old object's dependents, such as a
+	// Deployment's ReplicaSets and Pods, are gone before the copy is created.
+	DeletePropagation metav1.DeletionPropagation
+	// DeletionTimeout is how long an overwrite waits for the existing object
+	// to be gone; zero derives it from the propagation policy and the grace
+	// period of the object's pods.
+	DeletionTimeout time.Duration
+
+	// ValidateWithServer runs a server-side dry-run create during Plan so
+	// admission rejections surface as conflicts.
+	ValidateWithServer bool
+
+	// FailOn is the lowest conflict severity that blocks a resource from
+	// being created. Defaults to conflict.SeverityError; set it to
+	// conflict.SeverityWarning to treat warnings as blocking too, including
+	// sanitizer warnings of sanitizer.SeverityWarning that are not suppressed.
+	FailOn conflict.Severity
+
+	// SuppressWarnings lists warning codes, or path.Match globs of them
+	// ("KC-POD-*"), whose warnings are marked Suppressed and never block.
+	SuppressWarnings []string
+
+	// Force creates resources even when blocking conflicts were found.
+	// Existence conflicts are still governed by OnConflict.
+	Force bool
+
+	// SkipExisting plans resources whose only conflict is that they already
+	// exist as "exists" rather than "skip": left alone like skipped ones, but
+	// reported as expected. It requires the ConflictSkip strategy.
+	SkipExisting bool
+
+	// IgnoreConflicts lists conflict types dropped from results and from the
+	// action decision. Ignoring TypeExistence plans every resource as "create".
+	IgnoreConflicts []conflict.Type
+
+	// Excluded lists resources deliberately left out of the copy (labeled
+	// kubecopy.io/ignore, or skipped Secrets). Missing references to them are
+	// reported as notes.
+	Excluded []ResourceRef
+
+	// SkipSecrets tells that every Secret in Excluded was left out because
+	// Secrets were not to be copied (--skip-secrets), rather than because of
+	// its label, which the notes about references to them say.
+	SkipSecrets bool
+
+	// NamespaceMap maps source namespaces to target namespaces for graphs
+	// that span several namespaces. Namespaces not in the map are copied to
+	// the target namespace passed to PlanAll.
+	NamespaceMap map[string]string
+
+	// Parallelism is the maximum number of resources ApplyAll creates
+	// concurrently. Values below 2 apply sequentially. Progress must be safe
+	// for concurrent use when it is above 1.
+	Parallelism int
+
+	// Dependencies between the planned resources, typically from discovery.
+	// PlanAll orders its results by kind and then by these.
+	Dependencies []Dependency
+
+	// Atomic makes ApplyAll all-or-nothing: on the first failed create, or
+	// once ctx is canceled, it stops and deletes everything the run created,
+	// in reverse order.
+	Atomic bool
+
+	// Provenance, when set, is stamped on every created resource (see
+	// package provenance).
+	Provenance *provenance.Info
+
+	// DryRun makes CopyAll stop after planning.
+	DryRun bool
+
+	// Resumed holds the results of an earlier, interrupted run of the same
+	// copy. PlanAll plans the resources it created, overwrote, or found
+	// unchanged as "done" without fetching them again, as long as they are
+	// still in the target, and applying them restores the earlier action.
+	Resumed []CopyResult
+
+	// PruneTypes, when set, makes CopyAll also plan the copies of these
+	// resource types that earlier runs left behind (see PlanPrune) and,
+	// once everything else was applied without errors, delete them. It
+	// needs Provenance, which identifies the copies of this source.
+	PruneTypes []ResourceRef
+
+	// Confirm, when set, is called by CopyAll with the plan before anything
+	// is applied, typically to show it and prompt. Returning false stops
+	// CopyAll without applying.
+	Confirm func(planned []CopyResult) bool
+
+	// SkipConflictCheck plans every resource as "create" without looking at
+	// the target at all. TargetClient and TargetMapper may then be nil as long
+	// as nothing is applied, e.g. when only exporting sanitized manifests.
+	SkipConflictCheck bool
+
+	// Stats, when set, records the time and API requests CopyAll spends
+	// planning, applying, and copying data.
+	Stats *Stats
+
+	// Verify reads every created resource back from the target and reports
+	// the fields the target cluster changed (see CopyResult.Verification).
+	Verify bool
+
+	// DataMover, when set, makes CopyData copy the contents of every
+	// PersistentVolumeClaim the run created into its copy.
+	DataMover *transfer.Mover
+
+	// Sanitizers to apply; nil means the package-level default registry.
+	Sanitizers *sanitizer.Registry
+
+	// Metadata, when set, is stamped on every planned resource after
+	// sanitization (see sanitizer.StampMetadata).
+	Metadata *sanitizer.Metadata
+
+	// Images replaces container images after sanitization (see
+	// sanitizer.SetImages). A container name that matches no container in
+	// the batch fails every workload in it, so a typo does not copy the
+	// source images unnoticed.
+	Images map[string]string
+
+	// Env sets environment variables after sanitization (see
+	// sanitizer.SetEnv).
+	Env []sanitizer.EnvVar
+
+	// ClearHostPorts removes the hostPorts of copied pod specs, which are
+	// otherwise kept with a warning (see sanitizer.ClearHostPorts).
+	ClearHostPorts bool
+
+	// PreserveNodePorts and PreserveClusterIP keep the nodePorts and
+	// clusterIP of copied Services instead of resetting them (see
+	// sanitizer.ServiceSanitizer). The address conflict check then fails
+	// Services whose addresses are taken in the target.
+	PreserveNodePorts bool
+	PreserveClusterIP bool
+
+	// PreserveNodeName keeps the nodeName of copied Pods, or sets it to
+	// NodeName when that is not empty, instead of removing it (see
+	// sanitizer.PodSanitizer). The scheduling check then verifies the node
+	// exists and is Ready in the target.
+	PreserveNodeName bool
+	NodeName         string
+
+	// KeepHelmMetadata keeps the release annotations and managed-by label of
+	// objects installed by Helm, which are otherwise stripped with a warning
+	// (see sanitizer.StripHelmMetadata). Copies of a whole release keep them.
+	KeepHelmMetadata bool
+
+	// PreCreate, when set, is called by Apply with each resource about to be
+	// created or overwritten, after sanitization, reference rewriting,
+	// version conversion, and the provenance stamp, and before an
+	// overwritten target is deleted. It may modify r.Sanitized, which is
+	// what gets created. An error fails the resource without touching the
+	// target.
+	PreCreate func(ctx context.Context, r *CopyResult) error
+
+	// PreCreateOnPlan calls PreCreate at the end of planning instead, so a
+	// dry run shows its changes and reports its errors. Provenance is then
+	// stamped after it, and Apply does not call it again.
+	PreCreateOnPlan bool
+
+	// PostCreate, when set, is called by Apply with each resource it
+	// created or overwrote, after any verification.
+	PostCreate func(ctx context.Context, r *CopyResult)
+
+	// Resolve decides each resource that already exists in the target under
+	// the ConflictPrompt strategy, typically by asking the user. It is
+	// called during planning with the planned result, whose Diff compares
+	// it with the live object, and is required by that strategy.
+	Resolve func(r *CopyResult) Decision
+
+	// CopyVolumes copies the PersistentVolume each copied claim is bound to
+	// along with it and keeps the claim bound to the copy, for storage that
+	// can be reached from the target, such as NFS. The copied volume
+	// references the same storage as the original.
+	CopyVolumes bool
+
+	// CreateNamespace plans the target namespaces that do not exist yet as
+	// created from their source namespaces, with the labels and
+	// annotations users set on them. Namespaces that exist are left alone.
+	// It has no effect with SkipConflictCheck, which never looks at the
+	// target.
+	CreateNamespace bool
+
+	// Audit, when set, is called after every create and delete request the
+	// Copier sends, including each retry, with its outcome. It must be safe
+	// for concurrent use when Parallelism is above 1.
+	Audit func(AuditEvent)
+
+	fieldManager string
+	retryPolicy  *RetryPolicy
+	nodes        *conflict.Nodes    // of the target, listed once for the scheduling checks
+	services     *conflict.Services // of the target, listed once per namespace for the selector checks
+}
+
+func (c *Copier) sanitize(obj *unstructured.Unstructured, targetNS, targetName string) []sanitizer.Warning {
+	lookup, run := sanitizer.Lookup, sanitizer.Run
+	if c.Sanitizers != nil {
+		lookup, run = c.Sanitizers.Lookup, c.Sanitizers.Run
+	}
+	if s, ok := lookup(obj.GroupVersionKind()); ok {
+		if configured := c.configure(s); configured != nil {
+			warnings := sanitizer.SanitizeCommon(obj, targetNS, targetName)
+			return append(warnings, configured.Sanitize(obj)...)
+		}
+	}
+	return run(obj, targetNS, targetName)
+}
+
+// configure returns the built-in sanitizer s as configured by the Preserve
+// options and CopyVolumes, or nil when the options leave it as it is. Sanitizers registered
+// in place of the built-in ones are left alone.
+func (c *Copier) configure(s sanitizer.Sanitizer) sanitizer.Sanitizer {
+	switch s.(type) {
+	case sanitizer.ServiceSanitizer:
+		if c.PreserveNodePorts || c.PreserveClusterIP {
+			return sanitizer.ServiceSanitizer{
+				PreserveNodePorts: c.PreserveNodePorts,
+				PreserveClusterIP: c.PreserveClusterIP,
+			}
+		}
+	case sanitizer.PodSanitizer:
+		if c.PreserveNodeName {
+			return sanitizer.PodSanitizer{PreserveNodeName: true, NodeName: c.NodeName}
+		}
+	case sanitizer.PVCSanitizer:
+		if c.CopyVolumes {
+			return sanitizer.PVCSanitizer{PreserveVolumeName: true}
+		}
+	}
+	return nil
 }
 
 func (c *Copier) progress() Progress {
@@ -80,10 +384,25 @@ func (c *Copier) progress() Progress {
 // Plan fetches a single resource, sanitizes it, checks for conflicts,
 // but does NOT create it. Returns the planned result.
 func (c *Copier) Plan(ctx context.Context, ref ResourceRef, targetNS, targetName string) CopyResult {
+	results := []CopyResult{c.plan(ctx, ref, targetNS, targetName, nil, c.namespaceMapper([]ResourceRef{ref}, targetNS), nil)}
+	if c.conflictStrategy() == ConflictPrompt {
+		names := []string{results[0].TargetName}
+		results = c.resolveConflicts(ctx, []ResourceRef{ref}, targetNS, names, results)
+	}
+	c.checkImages(results)
+	return results[0]
+}
+
+// plan is Plan with knowledge of the other resources in the same copy batch,
+// so references between them are not reported as missing, of where each
+// source namespace in the batch is copied to, and of which resources in it
+// are renamed, so references to them follow.
+func (c *Copier) plan(ctx context.Context, ref ResourceRef, targetNS, targetName string, batch conflict.Batch, mapNS sanitizer.NamespaceMapper, mapName sanitizer.NameMapper) CopyResult {
 	result := CopyResult{
 		Source:     ref,
 		TargetName: targetName,
 		TargetNS:   targetNS,
+		TargetGVR:  ref.GVR,
 	}
 
 	if targetName == "" {
@@ -93,52 +412,183 @@ func (c *Copier) Plan(ctx context.Context, ref ResourceRef, targetNS, targetName
 
 	p := c.progress()
 
+	// A new name is checked before anything is fetched, so it fails here
+	// rather than at create
+	if targetName != ref.Name {
+		if err := sanitizer.ValidateName(ref.Kind, targetName); err != nil {
+			result.Error = fmt.Errorf("cannot copy %s to a new name: %w", ref.DisplayName(), err)
+			result.ErrorClass = ErrorClassOther
+			c.failed(ref, result.Error)
+			return result
+		}
+	}
+
 	// 1. Fetch from source (use empty namespace for cluster-scoped resources)
 	srcNS := ref.Namespace
 	if !ref.Namespaced {
 		srcNS = ""
 	}
 	p.Fetching(ref.DisplayName(), ref.Namespace)
-	obj, err := c.SourceClient.Resource(ref.GVR).Namespace(srcNS).Get(ctx, ref.Name, metav1.GetOptions{})
+	var obj *unstructured.Unstructured
+	retries, err := c.retry(ctx, func() (err error) {
+		obj, err = c.SourceClient.Resource(ref.GVR).Namespace(srcNS).Get(ctx, ref.Name, metav1.GetOptions{})
+		return err
+	})
+	result.Retries += retries
 	if err != nil {
-		result.Error = FormatFetchError(err, ref)
+		result.Error = withRetries(FormatFetchError(err, ref), retries)
+		result.ErrorClass = Classify(err)
+		c.failed(ref, result.Error)
 		return result
 	}
 
 	// 2. Deep copy and sanitize
 	p.Sanitizing(ref.DisplayName())
 	copied := obj.DeepCopy()
-	warnings := sanitizer.Run(copied, targetNS, targetName)
-	result.Warnings = warnings
+	var warnings []sanitizer.Warning
+	if !c.KeepHelmMetadata {
+		warnings = sanitizer.StripHelmMetadata(copied)
+	}
+	warnings = append(warnings, sanitizer.RewriteNamespaceRefs(copied, mapNS)...)
+	if mapName != nil {
+		warnings = append(warnings, sanitizer.RewriteNameRefs(copied, mapName)...)
+	}
+	warnings = append(warnings, sanitizer.RenamedStatefulSetClaims(copied, targetName)...)
+	if c.ClearHostPorts {
+		warnings = append(warnings, sanitizer.ClearHostPorts(copied)...)
+	}
+	warnings = append(warnings, c.sanitize(copied, targetNS, targetName)...)
+	if c.CopyVolumes && copied.GetKind() == "PersistentVolumeClaim" {
+		result.volumeName, _, _ = unstructured.NestedString(copied.Object, "spec", "volumeName")
+	}
+	if c.Metadata != nil {
+		if err := sanitizer.StampMetadata(copied, *c.Metadata); err != nil {
+			result.Error = fmt.Errorf("%s: %w", ref.DisplayName(), err)
+			result.ErrorClass = ErrorClassOther
+			c.failed(ref, result.Error)
+			return result
+		}
+	}
+	if len(c.Images) > 0 {
+		replaced, matched := sanitizer.SetImages(copied, c.Images)
+		warnings = append(warnings, replaced...)
+		result.imagesMatched = matched
+	}
+	if len(c.Env) > 0 {
+		warnings = append(warnings, sanitizer.SetEnv(copied, c.Env)...)
+	}
+	if mapping, converted := sanitizer.ConvertToServed(copied, c.TargetMapper); mapping != nil {
+		result.TargetGVR = mapping.Resource
+		warnings = append(warnings, converted...)
+	}
+	result.Warnings = c.markSuppressed(warnings)
 	result.Sanitized = copied
+	gvr := result.TargetGVR
+
+	if c.SkipConflictCheck {
+		result.Action = "create"
+		return result
+	}
 
 	// 3. Conflict detection
 	p.Checking(ref.DisplayName())
-	conflicts := conflict.Detect(ctx, c.TargetClient, ref.GVR, copied, targetNS)
-	result.Conflicts = conflicts
+	conflicts := conflict.Detect(ctx, c.TargetClient, gvr, copied, targetNS, batch)
+	if !batch.Contains(crdGVR.GroupResource(), "", crdName(gvr)) {
+		// A CRD created by the same run serves the resource once applied
+		conflicts = append(conflicts, conflict.DetectAPIAvailability(c.TargetMapper, gvr, copied.GetKind(), targetName)...)
+	}
+	conflicts = append(conflicts, conflict.DetectDeprecatedAPI(gvr, copied.GetKind(), targetName, c.TargetVersion)...)
+	if c.nodes == nil {
+		c.nodes = &conflict.Nodes{Client: c.TargetClient}
+	}
+	conflicts = append(conflicts, conflict.DetectScheduling(ctx, c.nodes, copied)...)
+	if c.services == nil {
+		c.services = &conflict.Services{Client: c.TargetClient}
+	}
+	conflicts = append(conflicts, conflict.DetectServiceSelectors(ctx, c.services, copied, targetNS, batch)...)
 
-	// Determine planned action
-	if conflictHasType(conflicts, conflict.TypeExistence) {
-		switch c.OnConflict {
-		case "skip":
-			result.Action = "skip"
-		case "warn", "overwrite":
-			result.Action = "overwrite"
+	exists := conflictHasType(conflicts, conflict.TypeExistence)
+	if exists {
+		if live, err := c.TargetClient.Resource(gvr).Namespace(targetNS).Get(ctx, targetName, metav1.GetOptions{}); err == nil {
+			result.Target = live
 		}
-	} else {
-		result.Action = "create"
+		if s := c.conflictStrategy(); s == ConflictWarn || s == ConflictOverwrite || s == ConflictPrompt {
+			conflicts = append(conflicts, conflict.DetectImmutable(copied, result.Target)...)
+			conflicts = append(conflicts, conflict.DetectGitOps(copied, result.Target)...)
+		}
+	}
+
+	// 4. Optional server-side validation of resources we intend to create
+	if c.ValidateWithServer && !exists {
+		conflicts = append(conflicts, conflict.DetectAdmission(ctx, c.TargetClient, gvr, copied, targetNS)...)
+	}
+
+	if exists && result.Target != nil {
+		result.Diff = Diff(copied.Object, c.normalizeTarget(result.Target, targetNS, targetName).Object)
+		if len(result.Diff) == 0 {
+			// Re-running the same copy: nothing to create, nothing to report
+			result.Conflicts = withoutType(c.filterIgnored(conflicts), conflict.TypeExistence)
+			result.Action = "unchanged"
+			return result
+		}
+	}
+
+	conflicts = c.filterIgnored(conflicts)
+	result.Conflicts = conflicts
+	result.Action = c.planAction(conflicts)
+	if result.Action == "exists" {
+		result.Conflicts = withoutType(conflicts, conflict.TypeExistence)
+		return result
+	}
+	if result.Action != "skip" && !c.Force && c.warningsBlock(result.Warnings) {
+		result.Action = "skip"
 	}
+	// Only the existence conflict keeps it from being created
+	result.undecided = exists && c.conflictStrategy() == ConflictPrompt && result.Action == "skip" &&
+		c.planAction(withoutType(conflicts, conflict.TypeExistence)) == "create" && (c.Force || !c.warningsBlock(result.Warnings))
 
 	return result
 }
 
+// normalizeTarget sanitizes a copy of the live target object through the same
+// pipeline as the copy, so the two compare without server-populated fields.
+func (c *Copier) normalizeTarget(live *unstructured.Unstructured, targetNS, targetName string) *unstructured.Unstructured {
+	current := live.DeepCopy()
+	c.sanitize(current, targetNS, targetName)
+	return current
+}
+
 // Apply executes a planned result -- creates the resource in the target cluster.
-// Only call this after Plan. Skipped resources are left alone.
+// Only call this after Plan. Skipped resources are left alone, and once ctx
+// is canceled the resource is marked "canceled" instead of created.
 func (c *Copier) Apply(ctx context.Context, planned *CopyResult) {
-	if planned.Error != nil || planned.Action == "skip" {
-		if planned.Action == "skip" {
-			planned.Action = "skipped"
-		}
+	if planned.Error != nil {
+		// Already reported as failed by Plan
+		return
+	}
+	if planned.Action == "done" {
+		// Applied by the resumed run
+		planned.Action = planned.resumedAction
+		return
+	}
+	c.apply(ctx, planned)
+	planned.Warnings = c.markSuppressed(planned.Warnings)
+	if planned.Error != nil {
+		c.failed(planned.Source, planned.Error)
+	} else {
+		c.completed(planned.Source, planned.Action)
+	}
+}
+
+func (c *Copier) apply(ctx context.Context, planned *CopyResult) {
+	switch {
+	case planned.Action == "skip":
+		planned.Action = "skipped"
+		return
+	case planned.Action == "unchanged", planned.Action == "exists":
+		return
+	case ctx.Err() != nil:
+		planned.Action = "canceled"
 		return
 	}
 
@@ -153,101 +603,592 @@ func (c *Copier) Apply(ctx context.Context, planned *CopyResult) {
 	p := c.progress()
 	p.Creating(ref.DisplayName(), targetNS)
 
+	if c.Provenance != nil {
+		provenance.Stamp(copied, *c.Provenance, ref.Namespace, ref.Name)
+	}
+	if c.PreCreate != nil && !planned.preCreated {
+		if err := c.preCreate(ctx, planned); err != nil {
+			return
+		}
+		copied = planned.Sanitized
+	}
+
+	resource := c.TargetClient.Resource(planned.TargetGVR).Namespace(targetNS)
+	action := planned.Action
+	create := func() error {
+		_, err := resource.Create(ctx, copied, metav1.CreateOptions{FieldManager: c.fieldManager})
+		c.audit(AuditEvent{Verb: "create", Action: action, GVR: planned.TargetGVR, Namespace: targetNS, Name: targetName, Err: err})
+		return err
+	}
+
+	var retries int
 	var err error
 	if planned.Action == "overwrite" {
-		_ = c.TargetClient.Resource(ref.GVR).Namespace(targetNS).Delete(ctx, targetName, metav1.DeleteOptions{})
-		_, err = c.TargetClient.Resource(ref.GVR).Namespace(targetNS).Create(ctx, copied, metav1.CreateOptions{})
-		planned.Action = "overwritten"
+		propagation := c.DeletePropagation
+		if propagation == "" {
+			propagation = metav1.DeletePropagationForeground
+		}
+		deleteStart := time.Now()
+		retries, err = c.retry(ctx, func() error {
+			err := resource.Delete(ctx, targetName, metav1.DeleteOptions{PropagationPolicy: &propagation})
+			c.audit(AuditEvent{Verb: "delete", Action: action, GVR: planned.TargetGVR, Namespace: targetNS, Name: targetName, Err: err})
+			return err
+		})
+		if apierrors.IsNotFound(err) {
+			err = nil
+		}
+		if err != nil {
+			planned.Retries += retries
+			planned.Error = fmt.Errorf("overwrite %s in %s: %w", ref.DisplayName(), targetNS, err)
+			planned.ErrorClass = Classify(err)
+			return
+		}
+		// From here on the existing object is gone: a failure leaves the
+		// target without it, which is reported as "deleted"
+		planned.Action = "deleted"
+		err = waitForDeletion(ctx, resource, targetName, c.DeletionTimeout, propagation)
+		planned.DeletionTime = time.Since(deleteStart)
+		if err != nil {
+			planned.Retries += retries
+			planned.Error = fmt.Errorf("overwrite %s in %s: deleted, not recreated: %w", ref.DisplayName(), targetNS, err)
+			planned.ErrorClass = Classify(err)
+			return
+		}
+		var createRetries int
+		createRetries, err = c.retry(ctx, create)
+		retries += createRetries
 	} else {
-		_, err = c.TargetClient.Resource(ref.GVR).Namespace(targetNS).Create(ctx, copied, metav1.CreateOptions{})
+		retries, err = c.retry(ctx, create)
 		planned.Action = "created"
 	}
+	if err != nil && c.awaitCRD(ctx, planned.TargetGVR, err) {
+		// Retried once: the CRD exists now, so a second failure is final
+		var more int
+		more, err = c.retry(ctx, create)
+		retries += more
+	}
+	planned.Retries += retries
 
 	if err != nil {
-		planned.Error = FormatCreateError(err, ref, targetNS)
+		planned.Error = withRetries(FormatCreateError(err, ref, targetNS), retries)
+		if planned.Action == "deleted" {
+			planned.Error = fmt.Errorf("deleted, not recreated: %w", planned.Error)
+		}
+		planned.ErrorClass = Classify(err)
+		return
+	}
+	if planned.Action == "deleted" {
+		planned.Action = "overwritten"
+	}
+	if isCRD(planned.TargetGVR) {
+		// Resources of the new type are planned and created next
+		c.resetMapper()
+	}
+	if c.Verify {
+		c.verify(ctx, planned, resource, copied)
+	}
+	if c.PostCreate != nil {
+		c.PostCreate(ctx, planned)
 	}
 }
 
-// PlanAll plans all resources in the list without creating anything.
+// preCreate runs the PreCreate hook on a resource and records its error.
+func (c *Copier) preCreate(ctx context.Context, r *CopyResult) error {
+	r.preCreated = true
+	err := c.PreCreate(ctx, r)
+	if err != nil {
+		r.Error = fmt.Errorf("pre-create hook for %s: %w", r.Source.DisplayName(), err)
+		r.ErrorClass = Classify(err)
+	}
+	return err
+}
+
+// PlanAll plans all resources in the list without creating anything. Each
+// resource is copied to the namespace its source namespace maps to (see
+// NamespaceMap); targetNS is the default. Results are returned in the order
+// they will be applied (see orderForApply).
 func (c *Copier) PlanAll(ctx context.Context, refs []ResourceRef, targetNS, primaryTargetName string) []CopyResult {
-	var results []CopyResult
+	names := make([]string, len(refs))
 	for i, ref := range refs {
-		name := ref.Name
+		names[i] = ref.Name
 		if i == 0 && primaryTargetName != "" {
-			name = primaryTargetName
+			names[i] = primaryTargetName
+		}
+	}
+	results := c.planBatch(ctx, refs, targetNS, names)
+	if c.conflictStrategy() == ConflictPrompt {
+		results = c.resolveConflicts(ctx, refs, targetNS, names, results)
+	}
+	deps := c.Dependencies
+	if c.CopyVolumes {
+		volumes, volumeDeps := c.planVolumes(ctx, results, c.namespaceMapper(refs, targetNS))
+		results = append(results, volumes...)
+		deps = append(slices.Clip(deps), volumeDeps...)
+	}
+	if c.CreateNamespace && !c.SkipConflictCheck {
+		results = append(c.planNamespaces(ctx, results), results...)
+	}
+	results = orderForApply(results, deps)
+	c.checkImages(results)
+	if c.PreCreate != nil && c.PreCreateOnPlan {
+		for i := range results {
+			r := &results[i]
+			if r.Error == nil && (r.Action == "create" || r.Action == "overwrite") {
+				if err := c.preCreate(ctx, r); err != nil {
+					c.failed(r.Source, r.Error)
+				}
+			}
+		}
+	}
+	return results
+}
+
+// planBatch plans refs, copied under names, in their order.
+func (c *Copier) planBatch(ctx context.Context, refs []ResourceRef, targetNS string, names []string) []CopyResult {
+	namespaces := make([]string, len(refs))
+	batch := conflict.Batch{}
+	for i, ref := range refs {
+		namespaces[i] = c.targetNamespace(ref, targetNS)
+		batch[conflict.BatchKey{Resource: ref.GVR.GroupResource(), Namespace: namespaces[i], Name: names[i]}] = ""
+	}
+	for _, ref := range c.Excluded {
+		key := conflict.BatchKey{Resource: ref.GVR.GroupResource(), Namespace: c.targetNamespace(ref, targetNS), Name: ref.Name}
+		if _, ok := batch[key]; !ok {
+			batch[key] = "kubecopy.io/ignore"
+			if c.SkipSecrets && isSecret(ref) {
+				batch[key] = "--skip-secrets"
+			}
 		}
-		result := c.Plan(ctx, ref, targetNS, name)
+	}
+	mapNS := c.namespaceMapper(refs, targetNS)
+	mapName := nameMapper(refs, names)
+
+	var results []CopyResult
+	for i, ref := range refs {
+		if result, ok := c.resumedResult(ctx, ref, namespaces[i], names[i]); ok {
+			results = append(results, result)
+			continue
+		}
+		result := c.plan(ctx, ref, namespaces[i], names[i], batch, mapNS, mapName)
 		results = append(results, result)
 	}
 	return results
 }
 
-// ApplyAll executes all planned results.
+// checkImages fails the workloads of a batch, or its first resource when
+// there are none, when a container name in Images matched no container. A
+// resumed run is not checked, since the workloads done by the earlier run
+// are not planned again.
+func (c *Copier) checkImages(results []CopyResult) {
+	matched := map[string]bool{}
+	for _, r := range results {
+		if r.Action == "done" {
+			return
+		}
+		for _, key := range r.imagesMatched {
+			matched[key] = true
+		}
+	}
+	var missing []string
+	for name := range c.Images {
+		if !matched[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) == 0 || len(results) == 0 {
+		return
+	}
+	slices.Sort(missing)
+	err := fmt.Errorf("image override for container(s) %s matched nothing in the copy; refusing to copy workloads with their source images",
+		strings.Join(missing, ", "))
+
+	failed := false
+	for i := range results {
+		r := &results[i]
+		if r.Error == nil && r.Sanitized != nil && hasPodSpec(r.Sanitized) {
+			r.Error = err
+			r.ErrorClass = ErrorClassOther
+			c.failed(r.Source, err)
+			failed = true
+		}
+	}
+	if !failed && results[0].Error == nil {
+		results[0].Error = err
+		results[0].ErrorClass = ErrorClassOther
+		c.failed(results[0].Source, err)
+	}
+}
+
+// isSecret reports whether ref is a core Secret.
+func isSecret(ref ResourceRef) bool {
+	return ref.GVR.Group == "" && ref.GVR.Resource == "secrets"
+}
+
+// hasPodSpec reports whether obj is a pod or has a pod template.
+func hasPodSpec(obj *unstructured.Unstructured) bool {
+	switch obj.GetKind() {
+	case "Pod", "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "Job", "CronJob":
+		return true
+	}
+	return false
+}
+
+// nameMapper maps the resources of a batch that are copied under another
+// name to that name. References to anything else are left alone.
+func nameMapper(refs []ResourceRef, names []string) sanitizer.NameMapper {
+	type key struct{ kind, namespace, name string }
+	renamed := map[key]string{}
+	for i, ref := range refs {
+		if names[i] != ref.Name && ref.Kind != "" {
+			renamed[key{ref.Kind, ref.Namespace, ref.Name}] = names[i]
+		}
+	}
+	return func(kind, sourceNS, name string) (string, bool) {
+		target, ok := renamed[key{kind, sourceNS, name}]
+		return target, ok
+	}
+}
+
+// CopyAll runs a whole copy: it plans refs (see PlanAll), stops there when
+// DryRun is set, ctx is canceled, Resolve aborts, or Confirm declines, and otherwise applies
+// the plan (see ApplyAll), copies volume data (see CopyData), and prunes
+// leftovers of earlier runs (see PruneTypes). It returns
+// the results and whether they were applied; unapplied results hold plan
+// actions ("create"), applied ones final actions ("created").
+func (c *Copier) CopyAll(ctx context.Context, refs []ResourceRef, targetNS, primaryTargetName string) ([]CopyResult, bool) {
+	stop := c.Stats.Start("planning")
+	planned := c.PlanAll(ctx, refs, targetNS, primaryTargetName)
+	copies := len(planned)
+	if aborted(planned) {
+		stop()
+		return planned, false
+	}
+	if len(c.PruneTypes) > 0 && ctx.Err() == nil {
+		planned = append(planned, c.PlanPrune(ctx, c.PruneTypes, planned)...)
+	}
+	stop()
+	if c.DryRun || ctx.Err() != nil {
+		return planned, false
+	}
+	if c.Confirm != nil && !c.Confirm(planned) {
+		return planned, false
+	}
+	if ctx.Err() != nil {
+		return planned, false
+	}
+
+	stop = c.Stats.Start("apply")
+	c.ApplyAll(ctx, planned[:copies])
+	stop()
+	if c.DataMover != nil {
+		stop = c.Stats.Start("data")
+		c.CopyData(ctx, planned[:copies])
+		stop()
+	}
+	if len(planned) > copies {
+		stop = c.Stats.Start("prune")
+		c.prune(ctx, planned[:copies], planned[copies:])
+		stop()
+	}
+	return planned, true
+}
+
+// targetNamespace returns the namespace ref is copied to: empty for
+// cluster-scoped resources, the NamespaceMap entry for its source namespace,
+// or defaultNS.
+func (c *Copier) targetNamespace(ref ResourceRef, defaultNS string) string {
+	if !ref.Namespaced {
+		return ""
+	}
+	if ns, ok := c.NamespaceMap[ref.Namespace]; ok {
+		return ns
+	}
+	return defaultNS
+}
+
+// namespaceMapper maps the source namespaces of the copied resources, and any
+// explicitly mapped namespace, to their target namespaces. References into
+// other namespaces are left alone.
+func (c *Copier) namespaceMapper(refs []ResourceRef, defaultNS string) sanitizer.NamespaceMapper {
+	copied := map[string]bool{}
+	for _, ref := range refs {
+		if ref.Namespaced {
+			copied[ref.Namespace] = true
+		}
+	}
+	return func(ns string) (string, bool) {
+		if target, ok := c.NamespaceMap[ns]; ok {
+			return target, true
+		}
+		if copied[ns] {
+			return defaultNS, true
+		}
+		return "", false
+	}
+}
+
+// ApplyAll executes all planned results in order. With Parallelism above 1,
+// resources are applied by a bounded worker pool one priority at a time (see
+// applyPriority), so configuration exists before the workloads that mount it,
+// and within a priority each starts once the resources it depends on (see
+// Dependencies) were applied. Results are updated in place and keep their
+// order; when ctx is canceled, those not yet applied are marked "canceled".
 func (c *Copier) ApplyAll(ctx context.Context, planned []CopyResult) {
+	if c.Parallelism < 2 {
+		for i := range planned {
+			failedBefore := planned[i].Error != nil
+			c.Apply(ctx, &planned[i])
+			if c.Atomic && failsAtomic(planned[i], failedBefore) {
+				c.rollback(ctx, planned)
+				return
+			}
+		}
+		return
+	}
+
+	phases := map[int][]int{}
+	maxPhase := 0
 	for i := range planned {
-		c.Apply(ctx, &planned[i])
+		phase := applyPriority(planned[i].Source.Kind)
+		phases[phase] = append(phases[phase], i)
+		if phase > maxPhase {
+			maxPhase = phase
+		}
+	}
+
+	for phase := 0; phase <= maxPhase; phase++ {
+		indexes := phases[phase]
+		failedBefore := map[int]bool{}
+		for _, i := range indexes {
+			failedBefore[i] = planned[i].Error != nil
+		}
+		c.applyPhase(ctx, planned, indexes)
+
+		if c.Atomic {
+			for _, i := range indexes {
+				if failsAtomic(planned[i], failedBefore[i]) {
+					c.rollback(ctx, planned)
+					return
+				}
+			}
+		}
 	}
 }
 
-func conflictHasType(conflicts []conflict.Conflict, t conflict.Type) bool {
-	for _, c := range conflicts {
-		if c.Type == t {
+// applyPhase applies the results at indexes, up to Parallelism at a time,
+// starting each once the results among them it depends on were applied,
+// whether or not that succeeded. A dependency cycle is broken in planned
+// order.
+func (c *Copier) applyPhase(ctx context.Context, planned []CopyResult, indexes []int) {
+	phase := map[refID]int{}
+	for _, i := range indexes {
+		phase[idOf(planned[i].Source)] = i
+	}
+	waiting := map[int]int{}      // unapplied dependencies of each result
+	dependents := map[int][]int{} // results waiting on each result
+	for _, i := range indexes {
+		for _, id := range planned[i].dependencies {
+			if j, ok := phase[id]; ok && j != i {
+				waiting[i]++
+				dependents[j] = append(dependents[j], i)
+			}
+		}
+	}
+
+	done := make(chan int)
+	pending := slices.Clone(indexes)
+	running := 0
+	for len(pending) > 0 || running > 0 {
+		for j := 0; j < len(pending) && running < c.Parallelism; {
+			i := pending[j]
+			if waiting[i] > 0 {
+				j++
+				continue
+			}
+			pending = slices.Delete(pending, j, j+1)
+			running++
+			go func() {
+				c.Apply(ctx, &planned[i])
+				done <- i
+			}()
+		}
+		if running == 0 {
+			// Only a cycle is left
+			waiting[pending[0]] = 0
+			continue
+		}
+		i := <-done
+		running--
+		for _, d := range dependents[i] {
+			waiting[d]--
+		}
+	}
+}
+
+// rollbackTimeout bounds the deletes of a rollback, which run even when the
+// run's context was canceled.
+var rollbackTimeout = time.Minute
+
+// failsAtomic reports whether an applied result ends an atomic run: it
+// failed to apply, having been planned without an error, or was canceled.
+func failsAtomic(r CopyResult, failedBefore bool) bool {
+	return !failedBefore && r.Error != nil || r.Action == "canceled"
+}
+
+// rollback undoes an atomic run after a failed or canceled create:
+// resources not yet applied are marked "aborted", and those the run created
+// are deleted in reverse order and marked "rolled back". The deletes run on
+// their own context, since a canceled run must still be undone. A failed
+// delete is recorded on the result and not retried. Overwritten resources,
+// including those deleted but not recreated, cannot be restored and are
+// reported as such.
+func (c *Copier) rollback(ctx context.Context, planned []CopyResult) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), rollbackTimeout)
+	defer cancel()
+
+	for i := range planned {
+		switch planned[i].Action {
+		case "create", "overwrite":
+			planned[i].Action = "aborted"
+		case "skip":
+			planned[i].Action = "skipped"
+		case "done":
+			planned[i].Action = planned[i].resumedAction
+		}
+	}
+
+	for i := len(planned) - 1; i >= 0; i-- {
+		r := &planned[i]
+		switch {
+		case r.Action == "overwritten" || r.Action == "deleted":
+			r.Warnings = c.markSuppressed(append(r.Warnings, sanitizer.Warning{
+				Resource: r.Source.DisplayName(),
+				Code:     sanitizer.CodeNotRolledBack,
+				Severity: sanitizer.SeverityWarning,
+				Message:  "not rolled back: the previous object in the target was already replaced",
+			}))
+		case r.Action == "created" && r.Error == nil && r.resumedAction == "":
+			targetNS := r.TargetNS
+			if !r.Source.Namespaced {
+				targetNS = ""
+			}
+			err := c.TargetClient.Resource(r.TargetGVR).Namespace(targetNS).Delete(ctx, r.TargetName, metav1.DeleteOptions{})
+			c.audit(AuditEvent{Verb: "delete", Action: "rollback", GVR: r.TargetGVR, Namespace: targetNS, Name: r.TargetName, Err: err})
+			if err != nil && !apierrors.IsNotFound(err) {
+				r.Error = fmt.Errorf("rollback: deleting %s from %s failed: %w", r.Source.DisplayName(), targetNS, err)
+				r.ErrorClass = Classify(err)
+				c.failed(r.Source, r.Error)
+				continue
+			}
+			r.Action = "rolled back"
+			c.completed(r.Source, r.Action)
+		}
+	}
+}
+
+// planAction decides what to do with a resource given its conflicts. Existence
+// conflicts follow the conflict strategy, or plan "exists" with SkipExisting
+// when they are the only conflict; any other blocking conflict plans the
+// resource as "skip" unless Force is set.
+func (c *Copier) planAction(conflicts []conflict.Conflict) string {
+	if c.SkipExisting && len(conflicts) > 0 && len(withoutType(conflicts, conflict.TypeExistence)) == 0 {
+		return "exists"
+	}
+	if !c.Force {
+		for _, cf := range conflicts {
+			if cf.Type != conflict.TypeExistence && c.blocks(cf) {
+				return "skip"
+			}
+		}
+	}
+
+	if conflictHasType(conflicts, conflict.TypeExistence) {
+		switch c.conflictStrategy() {
+		case ConflictWarn, ConflictOverwrite:
+			return "overwrite"
+		default:
+			return "skip"
+		}
+	}
+	return "create"
+}
+
+// warningsBlock reports whether FailOn makes any of warnings block a create.
+func (c *Copier) warningsBlock(warnings []sanitizer.Warning) bool {
+	if c.FailOn != conflict.SeverityWarning {
+		return false
+	}
+	for _, w := range warnings {
+		if w.Severity == sanitizer.SeverityWarning && !w.Suppressed {
 			return true
 		}
 	}
 	return false
 }
 
-// FormatFetchError wraps a fetch error with a human-friendly message.
-func FormatFetchError(err error, ref ResourceRef) error {
-	raw := err.Error()
-	switch {
-	case contains(raw, "the server could not find the requested resource"):
-		return fmt.Errorf("%s: resource type not recognized by the cluster API server.\n"+
-			"    Verify the resource exists: kubectl api-resources | grep %s",
-			ref.DisplayName(), ref.GVR.Resource)
-	case contains(raw, "not found"):
-		return fmt.Errorf("%s not found in namespace %q.\n"+
-			"    Run: kubectl get %s -n %s",
-			ref.DisplayName(), ref.Namespace, ref.GVR.Resource, ref.Namespace)
-	case contains(raw, "Unauthorized") || contains(raw, "forbidden"):
-		return fmt.Errorf("%s: permission denied in namespace %q.\n"+
-			"    Check your RBAC roles and kubeconfig context.",
-			ref.DisplayName(), ref.Namespace)
-	case contains(raw, "dial tcp") || contains(raw, "connection refused") || contains(raw, "no such host"):
-		return fmt.Errorf("cannot reach cluster: %w\n"+
-			"    Check your kubeconfig context and network connectivity.", err)
-	default:
-		return fmt.Errorf("fetch %s in %s: %w", ref.DisplayName(), ref.Namespace, err)
+// markSuppressed flags the warnings whose code matches SuppressWarnings.
+func (c *Copier) markSuppressed(warnings []sanitizer.Warning) []sanitizer.Warning {
+	for i := range warnings {
+		for _, pattern := range c.SuppressWarnings {
+			if ok, _ := path.Match(pattern, warnings[i].Code); ok {
+				warnings[i].Suppressed = true
+				break
+			}
+		}
 	}
+	return warnings
 }
 
-// FormatCreateError wraps a create error with a human-friendly message.
-func FormatCreateError(err error, ref ResourceRef, targetNS string) error {
-	raw := err.Error()
-	switch {
-	case contains(raw, "already exists"):
-		return fmt.Errorf("%s already exists in namespace %q.\n"+
-			"    Use --on-conflict=overwrite to replace it.",
-			ref.DisplayName(), targetNS)
-	case contains(raw, "Unauthorized") || contains(raw, "forbidden"):
-		return fmt.Errorf("%s: permission denied creating in namespace %q.\n"+
-			"    Check your RBAC roles for the target cluster/namespace.",
-			ref.DisplayName(), targetNS)
+// filterIgnored drops conflicts whose type the user asked to ignore.
+func (c *Copier) filterIgnored(conflicts []conflict.Conflict) []conflict.Conflict {
+	if len(c.IgnoreConflicts) == 0 {
+		return conflicts
+	}
+	var kept []conflict.Conflict
+	for _, cf := range conflicts {
+		if !slices.Contains(c.IgnoreConflicts, cf.Type) {
+			kept = append(kept, cf)
+		}
+	}
+	return kept
+}
+
+// blocks reports whether a conflict is severe enough to stop a create.
+func (c *Copier) blocks(cf conflict.Conflict) bool {
+	switch cf.Severity {
+	case conflict.SeverityError:
+		return true
+	case conflict.SeverityWarning:
+		return c.FailOn == conflict.SeverityWarning
 	default:
-		return fmt.Errorf("create %s in %s: %w", ref.DisplayName(), targetNS, err)
+		return false
 	}
 }
 
-func contains(s, substr string) bool {
-	return len(s) >= len(substr) && searchString(s, substr)
+// withoutType drops all conflicts of type t.
+func withoutType(conflicts []conflict.Conflict, t conflict.Type) []conflict.Conflict {
+	var kept []conflict.Conflict
+	for _, cf := range conflicts {
+		if cf.Type != t {
+			kept = append(kept, cf)
+		}
+	}
+	return kept
 }
 
-func searchString(s, substr string) bool {
-	for i := 0; i <= len(s)-len(substr); i++ {
-		if s[i:i+len(substr)] == substr {
+func conflictHasType(conflicts []conflict.Conflict, t conflict.Type) bool {
+	for _, c := range conflicts {
+		if c.Type == t {
 			return true
 		}
 	}
 	return false
 }
+
+// withRetries notes on a final error that the call was already retried.
+func withRetries(err error, retries int) error {
+	if retries == 0 {
+		return err
+	}
+	return fmt.Errorf("%w (gave up after %d retries)", err, retries)
+}
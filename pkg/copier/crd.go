@@ -0,0 +1,79 @@
+package copier
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var crdGVR = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+
+// establishTimeout bounds how long the create of a custom resource waits
+// for its CRD to become Established; establishPollInterval is how often it
+// checks.
+var (
+	establishTimeout      = 10 * time.Second
+	establishPollInterval = 500 * time.Millisecond
+)
+
+func isCRD(gvr schema.GroupVersionResource) bool {
+	return gvr.Group == crdGVR.Group && gvr.Resource == crdGVR.Resource
+}
+
+// crdName returns the name of the CustomResourceDefinition that defines
+// gvr, <plural>.<group>.
+func crdName(gvr schema.GroupVersionResource) string {
+	return gvr.Resource + "." + gvr.Group
+}
+
+// resetMapper drops the discovery the target mapper cached, when it caches
+// any, so the kinds of CRDs created since are found.
+func (c *Copier) resetMapper() {
+	if m, ok := c.TargetMapper.(meta.ResettableRESTMapper); ok {
+		m.Reset()
+	}
+}
+
+// awaitCRD tells whether a create that failed with err is worth retrying
+// because the target did not know the custom resource type yet: typically
+// its CRD was created moments before in the same run. It then resets the
+// mapper and waits for the CRD to become Established, and returns false
+// when the error is another one or the CRD does not exist or become
+// Established within establishTimeout.
+func (c *Copier) awaitCRD(ctx context.Context, gvr schema.GroupVersionResource, err error) bool {
+	if gvr.Group == "" || !meta.IsNoMatchError(err) && Classify(err) != ErrorClassUnknownResource {
+		return false
+	}
+	c.resetMapper()
+	deadline := time.Now().Add(establishTimeout)
+	for {
+		crd, err := c.TargetClient.Resource(crdGVR).Get(ctx, crdName(gvr), metav1.GetOptions{})
+		if err == nil && established(crd) {
+			return true
+		}
+		if err != nil && !isTransient(err) || time.Now().After(deadline) {
+			return false
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(establishPollInterval):
+		}
+	}
+}
+
+// established reports whether a CRD's Established condition is true, so
+// the API server serves its resources.
+func established(crd *unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(crd.Object, "status", "conditions")
+	for _, c := range conditions {
+		if m, ok := c.(map[string]interface{}); ok && m["type"] == "Established" {
+			return m["status"] == "True"
+		}
+	}
+	return false
+}
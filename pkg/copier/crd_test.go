@@ -0,0 +1,92 @@
+package copier
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery/cached/memory"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/restmapper"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var widgetGVR = schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+
+// establishedCRD returns the CRD for widgets, Established.
+func establishedCRD() *unstructured.Unstructured {
+	crd := &unstructured.Unstructured{}
+	crd.SetAPIVersion("apiextensions.k8s.io/v1")
+	crd.SetKind("CustomResourceDefinition")
+	crd.SetName(crdName(widgetGVR))
+	_ = unstructured.SetNestedSlice(crd.Object, []interface{}{
+		map[string]interface{}{"type": "Established", "status": "True"},
+	}, "status", "conditions")
+	return crd
+}
+
+func TestAwaitCRDResetsTheMapper(t *testing.T) {
+	// The target learns the example.com group between discovery calls,
+	// as it does once a copied CRD is established
+	discovery := &discoveryfake.FakeDiscovery{Fake: &k8stesting.Fake{}}
+	discovery.Resources = []*metav1.APIResourceList{{
+		GroupVersion: "v1",
+		APIResources: []metav1.APIResource{{Name: "configmaps", Kind: "ConfigMap", Namespaced: true}},
+	}}
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discovery))
+	widget := schema.GroupKind{Group: "example.com", Kind: "Widget"}
+
+	_, err := mapper.RESTMapping(widget, "v1")
+	if !meta.IsNoMatchError(err) {
+		t.Fatalf("RESTMapping before the CRD exists: %v, want a no-match error", err)
+	}
+	discovery.Resources = append(discovery.Resources, &metav1.APIResourceList{
+		GroupVersion: "example.com/v1",
+		APIResources: []metav1.APIResource{{Name: "widgets", Kind: "Widget", Namespaced: true}},
+	})
+	if _, err := mapper.RESTMapping(widget, "v1"); !meta.IsNoMatchError(err) {
+		t.Fatalf("RESTMapping served from the cache: %v, want a no-match error", err)
+	}
+
+	c := &Copier{
+		TargetClient: dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), establishedCRD()),
+		TargetMapper: mapper,
+	}
+	if !c.awaitCRD(context.Background(), widgetGVR, err) {
+		t.Fatal("awaitCRD = false for an established CRD, want a retry")
+	}
+	mapping, err := mapper.RESTMapping(widget, "v1")
+	if err != nil {
+		t.Fatalf("RESTMapping after awaitCRD: %v", err)
+	}
+	if mapping.Resource != widgetGVR {
+		t.Errorf("RESTMapping = %s, want %s", mapping.Resource, widgetGVR)
+	}
+}
+
+func TestAwaitCRDIgnoresOtherErrors(t *testing.T) {
+	c := &Copier{TargetClient: dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), establishedCRD())}
+	gone := &meta.NoKindMatchError{GroupKind: schema.GroupKind{Group: "example.com", Kind: "Widget"}}
+
+	tests := []struct {
+		name string
+		gvr  schema.GroupVersionResource
+		err  error
+	}{
+		{"other error", widgetGVR, context.DeadlineExceeded},
+		{"core type", schema.GroupVersionResource{Version: "v1", Resource: "widgets"}, gone},
+		{"no CRD", schema.GroupVersionResource{Group: "other.io", Version: "v1", Resource: "widgets"}, gone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if c.awaitCRD(context.Background(), tt.gvr, tt.err) {
+				t.Error("awaitCRD = true, want no retry")
+			}
+		})
+	}
+}
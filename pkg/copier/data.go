@@ -0,0 +1,46 @@
+package copier
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/a13x22/kube-copy/pkg/transfer"
+)
+
+// CopyData copies the contents of every PersistentVolumeClaim that ApplyAll
+// created or overwrote into the new claim, using DataMover. A failed
+// transfer is recorded as the result's error; the claim itself is kept.
+func (c *Copier) CopyData(ctx context.Context, results []CopyResult) {
+	if c.DataMover == nil {
+		return
+	}
+	p := c.progress()
+
+	for i := range results {
+		r := &results[i]
+		if ctx.Err() != nil {
+			return
+		}
+		if r.Source.Kind != "PersistentVolumeClaim" || r.Error != nil {
+			continue
+		}
+		if r.Action != "created" && r.Action != "overwritten" {
+			continue
+		}
+
+		mover := *c.DataMover
+		mover.Progress = func(bytes int64) {
+			p.Transferring(r.Source.DisplayName(), bytes)
+		}
+		src := transfer.Volume{Namespace: r.Source.Namespace, Claim: r.Source.Name}
+		dst := transfer.Volume{Namespace: r.TargetNS, Claim: r.TargetName}
+		if err := mover.Copy(ctx, src, dst); err != nil {
+			r.Error = fmt.Errorf("copy data of %s: %w", r.Source.DisplayName(), err)
+			r.ErrorClass = Classify(err)
+			c.failed(r.Source, r.Error)
+			continue
+		}
+		r.dataCopied = true
+		c.completed(r.Source, "data copied")
+	}
+}
@@ -0,0 +1,90 @@
+package copier
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+// deletionTimeout is how long an overwrite waits for the old object to
+// disappear, on top of the grace period of pods the deletion waits for;
+// deletionPollInterval is how often it checks.
+var (
+	deletionTimeout      = 60 * time.Second
+	deletionPollInterval = time.Second
+)
+
+// waitForDeletion polls until the named object is gone, so a following
+// Create does not race grace periods and finalizers. A timeout of zero is
+// derived from the object, see deletionTimeoutFor. When the object is still
+// terminating after the timeout, the error lists its finalizers.
+func waitForDeletion(ctx context.Context, resource dynamic.ResourceInterface, name string, timeout time.Duration, propagation metav1.DeletionPropagation) error {
+	start := time.Now()
+	for {
+		obj, err := resource.Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil && !isTransient(err) {
+			return fmt.Errorf("wait for deletion of %s: %w", name, err)
+		}
+		if timeout == 0 && obj != nil {
+			timeout = deletionTimeoutFor(obj, propagation)
+		}
+
+		limit := timeout
+		if limit == 0 {
+			limit = deletionTimeout
+		}
+		if time.Since(start) > limit {
+			if obj == nil {
+				return fmt.Errorf("%s still present after %s", name, limit)
+			}
+			finalizers := obj.GetFinalizers()
+			if len(finalizers) == 0 {
+				return fmt.Errorf("%s stuck terminating after %s", name, limit)
+			}
+			return fmt.Errorf("%s stuck terminating after %s, blocked by finalizers: %s",
+				name, limit, strings.Join(finalizers, ", "))
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(deletionPollInterval):
+		}
+	}
+}
+
+// deletionTimeoutFor is how long deleting obj with propagation may take:
+// deletionTimeout, plus the termination grace period of its pods when the
+// deletion waits for them. A Pod always waits for its own; a workload only
+// under foreground propagation, which deletes the pods first.
+func deletionTimeoutFor(obj *unstructured.Unstructured, propagation metav1.DeletionPropagation) time.Duration {
+	var path []string
+	switch {
+	case obj.GetKind() == "Pod":
+		path = []string{"spec"}
+	case propagation != metav1.DeletePropagationForeground:
+		return deletionTimeout
+	case obj.GetKind() == "CronJob":
+		path = []string{"spec", "jobTemplate", "spec", "template", "spec"}
+	default:
+		path = []string{"spec", "template", "spec"}
+	}
+	spec, found, _ := unstructured.NestedMap(obj.Object, path...)
+	if !found {
+		return deletionTimeout // no pods
+	}
+	grace, found, _ := unstructured.NestedInt64(spec, "terminationGracePeriodSeconds")
+	if !found {
+		grace = 30 // the API server's default
+	}
+	return deletionTimeout + time.Duration(grace)*time.Second
+}
@@ -0,0 +1,105 @@
+package copier
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// terminating returns a fake target whose ConfigMap "config" is still
+// terminating, with a finalizer, for the first gets; later gets find it gone.
+func terminating(gets int) *dynamicfake.FakeDynamicClient {
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+	client.PrependReactor("get", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if gets == 0 {
+			return true, nil, apierrors.NewNotFound(configMapGVR.GroupResource(), "config")
+		}
+		gets--
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion("v1")
+		obj.SetKind("ConfigMap")
+		obj.SetName("config")
+		obj.SetFinalizers([]string{"example.com/cleanup"})
+		return true, obj, nil
+	})
+	return client
+}
+
+func TestWaitForDeletion(t *testing.T) {
+	interval := deletionPollInterval
+	deletionPollInterval = time.Millisecond
+	t.Cleanup(func() { deletionPollInterval = interval })
+
+	t.Run("gone after polling", func(t *testing.T) {
+		client := terminating(3)
+		err := waitForDeletion(context.Background(), client.Resource(configMapGVR).Namespace("staging"), "config", time.Minute, metav1.DeletePropagationForeground)
+		if err != nil {
+			t.Fatalf("waitForDeletion: %v", err)
+		}
+		if gets := len(client.Actions()); gets != 4 {
+			t.Errorf("%d gets, want 4", gets)
+		}
+	})
+
+	t.Run("stuck on a finalizer", func(t *testing.T) {
+		client := terminating(-1)
+		err := waitForDeletion(context.Background(), client.Resource(configMapGVR).Namespace("staging"), "config", 20*time.Millisecond, metav1.DeletePropagationForeground)
+		if err == nil || !strings.Contains(err.Error(), "blocked by finalizers: example.com/cleanup") {
+			t.Errorf("waitForDeletion = %v, want an error naming the finalizer", err)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		client := terminating(-1)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := waitForDeletion(ctx, client.Resource(configMapGVR).Namespace("staging"), "config", time.Minute, metav1.DeletePropagationForeground)
+		if err != context.Canceled {
+			t.Errorf("waitForDeletion = %v, want context.Canceled", err)
+		}
+	})
+}
+
+func TestDeletionTimeoutFor(t *testing.T) {
+	withGrace := func(kind string, grace int64, path ...string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		obj.SetKind(kind)
+		if grace >= 0 {
+			_ = unstructured.SetNestedField(obj.Object, grace, append(path, "terminationGracePeriodSeconds")...)
+		} else {
+			_ = unstructured.SetNestedMap(obj.Object, map[string]interface{}{}, path...)
+		}
+		return obj
+	}
+	configMap := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	configMap.SetKind("ConfigMap")
+
+	tests := []struct {
+		name        string
+		obj         *unstructured.Unstructured
+		propagation metav1.DeletionPropagation
+		want        time.Duration
+	}{
+		{"deployment, foreground", withGrace("Deployment", 120, "spec", "template", "spec"), metav1.DeletePropagationForeground, 180 * time.Second},
+		{"deployment, background", withGrace("Deployment", 120, "spec", "template", "spec"), metav1.DeletePropagationBackground, 60 * time.Second},
+		{"deployment, default grace period", withGrace("Deployment", -1, "spec", "template", "spec"), metav1.DeletePropagationForeground, 90 * time.Second},
+		{"cronjob, foreground", withGrace("CronJob", 10, "spec", "jobTemplate", "spec", "template", "spec"), metav1.DeletePropagationForeground, 70 * time.Second},
+		{"pod, orphan", withGrace("Pod", 300, "spec"), metav1.DeletePropagationOrphan, 360 * time.Second},
+		{"no pods", configMap, metav1.DeletePropagationForeground, 60 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := deletionTimeoutFor(tt.obj, tt.propagation); got != tt.want {
+				t.Errorf("deletionTimeoutFor = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package copier
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+)
+
+// FieldDiff is a single difference between the sanitized copy and the live
+// target object. Added fields exist only in the copy, removed fields only in
+// the target.
+type FieldDiff struct {
+	Path   string      `json:"path"`
+	Op     string      `json:"op"` // "added", "removed", "changed"
+	Source interface{} `json:"source,omitempty"`
+	Target interface{} `json:"target,omitempty"`
+}
+
+// maskedValue replaces Secret data in diffs.
+const maskedValue = "<masked>"
+
+// Diff computes the field-level differences between the sanitized copy and
+// the (equally sanitized) live target object. Secret data values are masked.
+func Diff(copied, target map[string]interface{}) []FieldDiff {
+	var diffs []FieldDiff
+	diffValues("", copied, target, &diffs)
+
+	if kind, _ := copied["kind"].(string); kind == "Secret" {
+		for i := range diffs {
+			if isSecretDataPath(diffs[i].Path) {
+				if diffs[i].Source != nil {
+					diffs[i].Source = maskedValue
+				}
+				if diffs[i].Target != nil {
+					diffs[i].Target = maskedValue
+				}
+			}
+		}
+	}
+	return diffs
+}
+
+func isSecretDataPath(path string) bool {
+	for _, field := range []string{"data", "stringData"} {
+		if path == field || strings.HasPrefix(path, field+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func diffValues(path string, source, target interface{}, diffs *[]FieldDiff) {
+	switch s := source.(type) {
+	case map[string]interface{}:
+		t, ok := target.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := map[string]bool{}
+		for k := range s {
+			keys[k] = true
+		}
+		for k := range t {
+			keys[k] = true
+		}
+		sorted := make([]string, 0, len(keys))
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+
+		for _, k := range sorted {
+			child := k
+			if path != "" {
+				child = path + "." + k
+			}
+			sv, inSource := s[k]
+			tv, inTarget := t[k]
+			switch {
+			case !inTarget:
+				*diffs = append(*diffs, FieldDiff{Path: child, Op: "added", Source: sv})
+			case !inSource:
+				*diffs = append(*diffs, FieldDiff{Path: child, Op: "removed", Target: tv})
+			default:
+				diffValues(child, sv, tv, diffs)
+			}
+		}
+		return
+
+	case []interface{}:
+		t, ok := target.([]interface{})
+		if !ok {
+			break
+		}
+		for i := 0; i < len(s) || i < len(t); i++ {
+			child := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(t):
+				*diffs = append(*diffs, FieldDiff{Path: child, Op: "added", Source: s[i]})
+			case i >= len(s):
+				*diffs = append(*diffs, FieldDiff{Path: child, Op: "removed", Target: t[i]})
+			default:
+				diffValues(child, s[i], t[i], diffs)
+			}
+		}
+		return
+	}
+
+	if !equality.Semantic.DeepEqual(source, target) {
+		*diffs = append(*diffs, FieldDiff{Path: path, Op: "changed", Source: source, Target: target})
+	}
+}
@@ -0,0 +1,101 @@
+package copier
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// ErrorClass classifies why a resource failed, so callers can branch on it
+// without parsing error messages.
+type ErrorClass string
+
+const (
+	ErrorClassNone            ErrorClass = ""
+	ErrorClassNotFound        ErrorClass = "not-found"        // the object does not exist
+	ErrorClassUnknownResource ErrorClass = "unknown-resource" // the API server does not serve the resource type
+	ErrorClassForbidden       ErrorClass = "forbidden"        // RBAC denied the request
+	ErrorClassUnauthorized    ErrorClass = "unauthorized"     // the credentials were rejected
+	ErrorClassAlreadyExists   ErrorClass = "already-exists"
+	ErrorClassUnavailable     ErrorClass = "unavailable" // the API server could not be reached or is unavailable
+	ErrorClassOther           ErrorClass = "other"
+)
+
+// Classify returns the ErrorClass of an API or transport error. Wrapped
+// errors are unwrapped.
+func Classify(err error) ErrorClass {
+	switch {
+	case err == nil:
+		return ErrorClassNone
+	case apierrors.IsNotFound(err):
+		if isUnknownResource(err) {
+			return ErrorClassUnknownResource
+		}
+		return ErrorClassNotFound
+	case apierrors.IsForbidden(err):
+		return ErrorClassForbidden
+	case apierrors.IsUnauthorized(err):
+		return ErrorClassUnauthorized
+	case apierrors.IsAlreadyExists(err):
+		return ErrorClassAlreadyExists
+	case apierrors.IsServiceUnavailable(err):
+		return ErrorClassUnavailable
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return ErrorClassUnavailable
+	}
+	return ErrorClassOther
+}
+
+// isUnknownResource tells "the server could not find the requested resource"
+// apart from a missing object: only the latter names the object.
+func isUnknownResource(err error) bool {
+	var status apierrors.APIStatus
+	if !errors.As(err, &status) {
+		return false
+	}
+	details := status.Status().Details
+	return details == nil || details.Name == ""
+}
+
+// FormatFetchError wraps a fetch error with a human-friendly message.
+func FormatFetchError(err error, ref ResourceRef) error {
+	switch Classify(err) {
+	case ErrorClassUnknownResource:
+		return fmt.Errorf("%s: resource type not recognized by the cluster API server.\n"+
+			"    Verify the resource exists: kubectl api-resources | grep %s",
+			ref.DisplayName(), ref.GVR.Resource)
+	case ErrorClassNotFound:
+		return fmt.Errorf("%s not found in namespace %q.\n"+
+			"    Run: kubectl get %s -n %s",
+			ref.DisplayName(), ref.Namespace, ref.GVR.Resource, ref.Namespace)
+	case ErrorClassForbidden, ErrorClassUnauthorized:
+		return fmt.Errorf("%s: permission denied in namespace %q.\n"+
+			"    Check your RBAC roles and kubeconfig context.",
+			ref.DisplayName(), ref.Namespace)
+	case ErrorClassUnavailable:
+		return fmt.Errorf("cannot reach cluster: %w\n"+
+			"    Check your kubeconfig context and network connectivity.", err)
+	default:
+		return fmt.Errorf("fetch %s in %s: %w", ref.DisplayName(), ref.Namespace, err)
+	}
+}
+
+// FormatCreateError wraps a create error with a human-friendly message.
+func FormatCreateError(err error, ref ResourceRef, targetNS string) error {
+	switch Classify(err) {
+	case ErrorClassAlreadyExists:
+		return fmt.Errorf("%s already exists in namespace %q.\n"+
+			"    Use --on-conflict=overwrite to replace it.",
+			ref.DisplayName(), targetNS)
+	case ErrorClassForbidden, ErrorClassUnauthorized:
+		return fmt.Errorf("%s: permission denied creating in namespace %q.\n"+
+			"    Check your RBAC roles for the target cluster/namespace.",
+			ref.DisplayName(), targetNS)
+	default:
+		return fmt.Errorf("create %s in %s: %w", ref.DisplayName(), targetNS, err)
+	}
+}
@@ -0,0 +1,198 @@
+package copier
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// MoveOptions configure MoveSources.
+type MoveOptions struct {
+	// KeepSecrets leaves the source Secrets in place.
+	KeepSecrets bool
+
+	// WaitTimeout, when set, waits up to this long for the copied workloads
+	// to become ready before any source is deleted.
+	WaitTimeout time.Duration
+
+	// SameCluster is set when the target is the source cluster. A source
+	// that is its own target, such as a dependency left under its name by a
+	// rename within its namespace, is then kept: the copy still uses it.
+	SameCluster bool
+}
+
+// readyPollInterval is how often MoveSources checks readiness.
+var readyPollInterval = 2 * time.Second
+
+// MoveSources turns an applied copy into a move: once every resource is in
+// the target, and ready when opts.WaitTimeout is set, it deletes their
+// sources in reverse apply order and marks them "moved". When any resource
+// failed or was not created, or a PersistentVolumeClaim's data would be
+// lost (see keepsData), nothing is deleted and an error says why. Sources
+// that are their own target are never deleted (see MoveOptions.SameCluster).
+// Failures to delete a source are recorded on its result.
+func (c *Copier) MoveSources(ctx context.Context, results []CopyResult, opts MoveOptions) error {
+	for _, r := range results {
+		if r.Error != nil {
+			return fmt.Errorf("not deleting any source: %s failed", r.Source.DisplayName())
+		}
+		switch r.Action {
+		case "created", "overwritten", "unchanged":
+		default:
+			return fmt.Errorf("not deleting any source: %s was %s", r.Source.DisplayName(), r.Action)
+		}
+		if r.Source.Kind == "PersistentVolumeClaim" && !r.volume && !keepsData(r, results) {
+			return fmt.Errorf("not deleting any source: deleting %s could destroy its data, which was not copied.\n"+
+				"    Pass --with-data, or --with-pv for a volume whose reclaim policy is Retain", r.Source.DisplayName())
+		}
+	}
+
+	if opts.WaitTimeout > 0 {
+		if err := c.waitReady(ctx, results, opts.WaitTimeout); err != nil {
+			return fmt.Errorf("not deleting any source: %w", err)
+		}
+	}
+
+	p := c.progress()
+	for i := len(results) - 1; i >= 0; i-- {
+		r := &results[i]
+		// A namespace created for the copy came from a source namespace
+		// that may hold more than the copied resources; the storage of a
+		// copied PersistentVolume is what the copy now uses
+		if r.namespace || r.volume || opts.KeepSecrets && r.Source.Kind == "Secret" {
+			continue
+		}
+		if opts.SameCluster && isOwnTarget(r) {
+			continue
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		srcNS := r.Source.Namespace
+		if !r.Source.Namespaced {
+			srcNS = ""
+		}
+		if d, ok := p.(interface{ Deleting(string, string) }); ok {
+			d.Deleting(r.Source.DisplayName(), srcNS)
+		}
+		_, err := c.retry(ctx, func() error {
+			err := c.SourceClient.Resource(r.Source.GVR).Namespace(srcNS).Delete(ctx, r.Source.Name, metav1.DeleteOptions{})
+			c.audit(AuditEvent{Source: true, Verb: "delete", Action: "move", GVR: r.Source.GVR, Namespace: srcNS, Name: r.Source.Name, Err: err})
+			return err
+		})
+		if err != nil && !apierrors.IsNotFound(err) {
+			r.Error = fmt.Errorf("copied, but deleting the source %s from %s failed: %w", r.Source.DisplayName(), srcNS, err)
+			r.ErrorClass = Classify(err)
+			c.failed(r.Source, r.Error)
+			continue
+		}
+		r.Action = "moved"
+		c.completed(r.Source, r.Action)
+	}
+	return nil
+}
+
+// isOwnTarget reports whether a result's target names its source object,
+// assuming both are in the same cluster.
+func isOwnTarget(r *CopyResult) bool {
+	if r.TargetGVR.GroupResource() != r.Source.GVR.GroupResource() || r.TargetName != r.Source.Name {
+		return false
+	}
+	return !r.Source.Namespaced || r.TargetNS == r.Source.Namespace
+}
+
+// keepsData reports whether deleting the source of a copied claim leaves
+// its data available: CopyData filled the copy, or the copy stays bound to
+// the claim's PersistentVolume, copied with CopyVolumes, whose reclaim
+// policy keeps the storage when the source claim goes. A claim that was
+// only re-created is empty, and its source's volume is usually deleted
+// with the source claim.
+func keepsData(claim CopyResult, results []CopyResult) bool {
+	if claim.dataCopied {
+		return true
+	}
+	if claim.volumeName == "" {
+		return false
+	}
+	for _, r := range results {
+		if !r.volume || r.Source.Name != claim.volumeName || r.Sanitized == nil {
+			continue
+		}
+		policy, _, _ := unstructured.NestedString(r.Sanitized.Object, "spec", "persistentVolumeReclaimPolicy")
+		return policy == "Retain"
+	}
+	return false
+}
+
+// waitReady polls the copied workloads until all are ready or timeout
+// passes.
+func (c *Copier) waitReady(ctx context.Context, results []CopyResult, timeout time.Duration) error {
+	p := c.progress()
+	for _, r := range results {
+		switch r.Source.Kind {
+		case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "Pod":
+		default:
+			continue
+		}
+		targetNS := r.TargetNS
+		if !r.Source.Namespaced {
+			targetNS = ""
+		}
+		if w, ok := p.(interface{ Waiting(string) }); ok {
+			w.Waiting(r.Source.DisplayName())
+		}
+		resource := c.TargetClient.Resource(r.TargetGVR).Namespace(targetNS)
+		err := wait.PollUntilContextTimeout(ctx, readyPollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+			obj, err := resource.Get(ctx, r.TargetName, metav1.GetOptions{})
+			if err != nil {
+				return false, nil
+			}
+			return isReady(obj), nil
+		})
+		if err != nil {
+			return fmt.Errorf("%s in %s did not become ready within %s", r.Source.DisplayName(), targetNS, timeout)
+		}
+	}
+	return nil
+}
+
+// isReady reports whether a workload has all its replicas ready on its
+// current spec, or a pod is Ready.
+func isReady(obj *unstructured.Unstructured) bool {
+	status := func(field string) int64 {
+		n, _, _ := unstructured.NestedInt64(obj.Object, "status", field)
+		return n
+	}
+	replicas, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+	if !found {
+		replicas = 1
+	}
+	observed := status("observedGeneration") >= obj.GetGeneration()
+
+	switch obj.GetKind() {
+	case "Deployment":
+		return observed && status("updatedReplicas") == replicas && status("availableReplicas") == replicas
+	case "StatefulSet":
+		return observed && status("updatedReplicas") == replicas && status("readyReplicas") == replicas
+	case "ReplicaSet":
+		return observed && status("readyReplicas") == replicas
+	case "DaemonSet":
+		return observed && status("numberReady") == status("desiredNumberScheduled") &&
+			status("updatedNumberScheduled") == status("desiredNumberScheduled")
+	case "Pod":
+		conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+		for _, c := range conditions {
+			condition, _ := c.(map[string]interface{})
+			if condition["type"] == "Ready" {
+				return condition["status"] == "True"
+			}
+		}
+		return false
+	}
+	return true
+}
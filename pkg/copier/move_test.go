@@ -0,0 +1,150 @@
+package copier
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var (
+	deploymentGVR = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	configMapGVR  = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	pvcGVR        = schema.GroupVersionResource{Version: "v1", Resource: "persistentvolumeclaims"}
+)
+
+func movedResult(gvr schema.GroupVersionResource, kind, name, action string) CopyResult {
+	return CopyResult{
+		Source:     ResourceRef{GVR: gvr, Kind: kind, Name: name, Namespace: "prod", Namespaced: true},
+		TargetNS:   "staging",
+		TargetName: name,
+		TargetGVR:  gvr,
+		Action:     action,
+	}
+}
+
+func volumeResult(name, policy string) CopyResult {
+	return CopyResult{
+		Source:     ResourceRef{GVR: persistentVolumeGVR, Kind: "PersistentVolume", Name: name},
+		TargetName: name,
+		TargetGVR:  persistentVolumeGVR,
+		Action:     "created",
+		Sanitized:  &unstructured.Unstructured{Object: map[string]interface{}{"spec": map[string]interface{}{"persistentVolumeReclaimPolicy": policy}}},
+		volume:     true,
+	}
+}
+
+func TestMoveSourcesDeletesNothingUnlessSafe(t *testing.T) {
+	copiedClaim := movedResult(pvcGVR, "PersistentVolumeClaim", "data", "created")
+	copiedClaim.dataCopied = true
+	boundClaim := movedResult(pvcGVR, "PersistentVolumeClaim", "data", "created")
+	boundClaim.volumeName = "pv-data"
+
+	failed := movedResult(deploymentGVR, "Deployment", "web", "create")
+	failed.Error = errors.New("admission webhook denied the request")
+
+	tests := []struct {
+		name    string
+		results []CopyResult
+		deletes int
+	}{
+		{
+			name:    "failed apply",
+			results: []CopyResult{movedResult(configMapGVR, "ConfigMap", "config", "created"), failed},
+		},
+		{
+			name:    "skipped resource",
+			results: []CopyResult{movedResult(configMapGVR, "ConfigMap", "config", "created"), movedResult(deploymentGVR, "Deployment", "web", "skipped")},
+		},
+		{
+			name:    "claim without its data",
+			results: []CopyResult{movedResult(configMapGVR, "ConfigMap", "config", "created"), movedResult(pvcGVR, "PersistentVolumeClaim", "data", "created")},
+		},
+		{
+			name:    "claim bound to a Delete volume",
+			results: []CopyResult{volumeResult("pv-data", "Delete"), boundClaim},
+		},
+		{
+			name:    "claim bound to a Retain volume",
+			results: []CopyResult{volumeResult("pv-data", "Retain"), boundClaim},
+			deletes: 1,
+		},
+		{
+			name:    "claim with its data",
+			results: []CopyResult{movedResult(configMapGVR, "ConfigMap", "config", "created"), copiedClaim},
+			deletes: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+			c := &Copier{SourceClient: source}
+
+			err := c.MoveSources(context.Background(), tt.results, MoveOptions{})
+			if tt.deletes == 0 && err == nil {
+				t.Fatal("MoveSources returned no error, want one explaining why nothing was deleted")
+			}
+			if tt.deletes > 0 && err != nil {
+				t.Fatalf("MoveSources: %v", err)
+			}
+
+			var deletes int
+			for _, action := range source.Actions() {
+				if action.GetVerb() == "delete" {
+					deletes++
+				}
+			}
+			if deletes != tt.deletes {
+				t.Errorf("%d sources deleted, want %d", deletes, tt.deletes)
+			}
+		})
+	}
+}
+
+func TestMoveSourcesKeepsSourcesThatAreTheirOwnTarget(t *testing.T) {
+	// A rename within the namespace: the Deployment moves to web-v2, while
+	// its ConfigMap keeps its name, so the plan found the source itself
+	renamed := movedResult(deploymentGVR, "Deployment", "web", "created")
+	renamed.TargetNS, renamed.TargetName = "prod", "web-v2"
+	itself := movedResult(configMapGVR, "ConfigMap", "config", "unchanged")
+	itself.TargetNS = "prod"
+
+	tests := []struct {
+		name        string
+		sameCluster bool
+		want        []string
+	}{
+		{"same cluster", true, []string{"web"}},
+		{"other cluster", false, []string{"config", "web"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+			c := &Copier{SourceClient: source}
+
+			results := []CopyResult{itself, renamed}
+			if err := c.MoveSources(context.Background(), results, MoveOptions{SameCluster: tt.sameCluster}); err != nil {
+				t.Fatalf("MoveSources: %v", err)
+			}
+			var deleted []string
+			for _, action := range source.Actions() {
+				if d, ok := action.(k8stesting.DeleteAction); ok {
+					deleted = append(deleted, d.GetName())
+				}
+			}
+			slices.Sort(deleted)
+			if !slices.Equal(deleted, tt.want) {
+				t.Errorf("deleted %v, want %v", deleted, tt.want)
+			}
+			if tt.sameCluster && results[0].Action != "unchanged" {
+				t.Errorf("kept source marked %q, want unchanged", results[0].Action)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package copier
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/a13x22/kube-copy/pkg/sanitizer"
+)
+
+var namespaceGVR = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+
+// planNamespaces plans the target namespaces of results that do not exist
+// in the target as "create", for CreateNamespace. A namespace that exists
+// is left alone, as is one whose existence cannot be checked: the copies
+// into it report the problem.
+func (c *Copier) planNamespaces(ctx context.Context, results []CopyResult) []CopyResult {
+	var planned []CopyResult
+	seen := map[string]bool{}
+	for _, r := range results {
+		if !r.Source.Namespaced || r.TargetNS == "" || seen[r.TargetNS] {
+			continue
+		}
+		seen[r.TargetNS] = true
+		_, err := c.TargetClient.Resource(namespaceGVR).Get(ctx, r.TargetNS, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			planned = append(planned, c.planNamespace(ctx, r.Source.Namespace, r.TargetNS))
+		}
+	}
+	return planned
+}
+
+// planNamespace plans the creation of targetNS from the source namespace it
+// is copied from, so it keeps the labels and annotations that set it up
+// (see the Namespace sanitizer). When the source namespace cannot be read,
+// as from a bundle, targetNS is created bare with a warning.
+func (c *Copier) planNamespace(ctx context.Context, sourceNS, targetNS string) CopyResult {
+	ref := ResourceRef{GVR: namespaceGVR, Kind: "Namespace", Name: sourceNS}
+	result := CopyResult{
+		Source:     ref,
+		TargetName: targetNS,
+		TargetGVR:  namespaceGVR,
+		Action:     "create",
+		namespace:  true,
+	}
+
+	p := c.progress()
+	p.Fetching(ref.DisplayName(), "")
+	var obj *unstructured.Unstructured
+	retries, err := c.retry(ctx, func() (err error) {
+		obj, err = c.SourceClient.Resource(namespaceGVR).Get(ctx, sourceNS, metav1.GetOptions{})
+		return err
+	})
+	result.Retries = retries
+	var warnings []sanitizer.Warning
+	if err != nil {
+		obj = &unstructured.Unstructured{}
+		obj.SetAPIVersion("v1")
+		obj.SetKind("Namespace")
+		warnings = append(warnings, sanitizer.Warning{
+			Resource: "Namespace/" + targetNS,
+			Code:     sanitizer.CodeNamespaceUnread,
+			Severity: sanitizer.SeverityWarning,
+			Message:  fmt.Sprintf("cannot read the source namespace %q (%v); creating it without its labels and annotations", sourceNS, err),
+		})
+	}
+
+	p.Sanitizing(ref.DisplayName())
+	copied := obj.DeepCopy()
+	warnings = append(warnings, c.sanitize(copied, "", targetNS)...)
+	result.Warnings = c.markSuppressed(warnings)
+	result.Sanitized = copied
+	return result
+}
@@ -0,0 +1,290 @@
+package copier
+
+import (
+	"fmt"
+	"path"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/a13x22/kube-copy/pkg/conflict"
+	"github.com/a13x22/kube-copy/pkg/provenance"
+	"github.com/a13x22/kube-copy/pkg/sanitizer"
+)
+
+// ConflictStrategy decides what happens to a resource that already exists
+// in the target.
+type ConflictStrategy string
+
+const (
+	ConflictSkip      ConflictStrategy = "skip"      // leave the existing object alone
+	ConflictWarn      ConflictStrategy = "warn"      // replace it, reporting fields that cannot change
+	ConflictOverwrite ConflictStrategy = "overwrite" // replace it
+	ConflictPrompt    ConflictStrategy = "prompt"    // let Copier.Resolve decide, per resource
+)
+
+// Valid reports whether s is a known strategy.
+func (s ConflictStrategy) Valid() bool {
+	switch s {
+	case ConflictSkip, ConflictWarn, ConflictOverwrite, ConflictPrompt:
+		return true
+	}
+	return false
+}
+
+// RetryPolicy bounds how transient API errors are retried: every call is
+// tried at most MaxAttempts times, backing off from BaseDelay and doubling
+// it on each retry, unless the server asks for a specific delay.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+}
+
+// DefaultRetryPolicy is used when no policy is configured.
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 4, BaseDelay: 500 * time.Millisecond}
+
+// Option configures a Copier built by New.
+type Option func(*Copier)
+
+// New returns a Copier reading from source and creating in target, and
+// reports options that do not make sense together. Either client may be nil
+// when the copy never uses it, and may also be set on the Copier later.
+func New(source, target dynamic.Interface, opts ...Option) (*Copier, error) {
+	c := &Copier{SourceClient: source, TargetClient: target}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Copier) validate() error {
+	if c.OnConflict != "" && !ConflictStrategy(c.OnConflict).Valid() {
+		return fmt.Errorf("invalid conflict strategy %q: must be %s, %s, %s, or %s", c.OnConflict, ConflictSkip, ConflictWarn, ConflictOverwrite, ConflictPrompt)
+	}
+	if c.conflictStrategy() == ConflictPrompt && c.Resolve == nil {
+		return fmt.Errorf("the %s conflict strategy needs a resolver (WithResolver)", ConflictPrompt)
+	}
+	if c.SkipExisting && c.conflictStrategy() != ConflictSkip {
+		return fmt.Errorf("skipping existing resources requires the %s conflict strategy, not %s", ConflictSkip, c.conflictStrategy())
+	}
+	switch c.FailOn {
+	case "", conflict.SeverityError, conflict.SeverityWarning:
+	default:
+		return fmt.Errorf("invalid fail-on severity %q: must be %s or %s", c.FailOn, conflict.SeverityError, conflict.SeverityWarning)
+	}
+	for _, pattern := range c.SuppressWarnings {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid warning pattern %q: %w", pattern, err)
+		}
+	}
+	if c.Metadata != nil {
+		if err := c.Metadata.Validate(); err != nil {
+			return err
+		}
+	}
+	for name, image := range c.Images {
+		if name == "" || image == "" {
+			return fmt.Errorf("invalid image override %q=%q: container name and image must not be empty", name, image)
+		}
+	}
+	if c.DeletionTimeout < 0 {
+		return fmt.Errorf("invalid deletion timeout %s: must not be negative", c.DeletionTimeout)
+	}
+	if c.Parallelism < 0 {
+		return fmt.Errorf("invalid parallelism %d: must not be negative", c.Parallelism)
+	}
+	if c.retryPolicy != nil && c.retryPolicy.MaxAttempts < 1 {
+		return fmt.Errorf("invalid retry policy: MaxAttempts must be at least 1")
+	}
+	return nil
+}
+
+// WithConflictStrategy sets what happens to resources that already exist in
+// the target. The default is ConflictSkip.
+func WithConflictStrategy(s ConflictStrategy) Option {
+	return func(c *Copier) { c.OnConflict = string(s) }
+}
+
+// WithDeletePropagation sets how an overwrite deletes the existing object.
+// The default is metav1.DeletePropagationForeground.
+func WithDeletePropagation(p metav1.DeletionPropagation) Option {
+	return func(c *Copier) { c.DeletePropagation = p }
+}
+
+// WithDeletionTimeout sets how long an overwrite waits for the existing
+// object to be deleted. Zero, the default, allows a minute plus the
+// termination grace period of the pods the deletion waits for.
+func WithDeletionTimeout(d time.Duration) Option {
+	return func(c *Copier) { c.DeletionTimeout = d }
+}
+
+// WithResolver sets the function that decides existing resources under the
+// ConflictPrompt strategy (see Copier.Resolve).
+func WithResolver(resolve func(r *CopyResult) Decision) Option {
+	return func(c *Copier) { c.Resolve = resolve }
+}
+
+// WithProgress reports progress to p.
+func WithProgress(p Progress) Option {
+	return func(c *Copier) { c.Progress = p }
+}
+
+// WithDryRun makes CopyAll stop after planning.
+func WithDryRun() Option {
+	return func(c *Copier) { c.DryRun = true }
+}
+
+// WithFieldManager sets the field manager recorded on created resources.
+func WithFieldManager(name string) Option {
+	return func(c *Copier) { c.fieldManager = name }
+}
+
+// WithRetry replaces DefaultRetryPolicy.
+func WithRetry(policy RetryPolicy) Option {
+	return func(c *Copier) { c.retryPolicy = &policy }
+}
+
+// WithResumed resumes the earlier run whose results are prior (see
+// Copier.Resumed).
+func WithResumed(prior []CopyResult) Option {
+	return func(c *Copier) { c.Resumed = prior }
+}
+
+// WithServerValidation runs a server-side dry-run create while planning.
+func WithServerValidation() Option {
+	return func(c *Copier) { c.ValidateWithServer = true }
+}
+
+// WithFailOn sets the lowest conflict severity that blocks a create.
+func WithFailOn(s conflict.Severity) Option {
+	return func(c *Copier) { c.FailOn = s }
+}
+
+// WithSuppressedWarnings hides warnings whose code matches one of patterns
+// (codes or path.Match globs) and keeps them from blocking.
+func WithSuppressedWarnings(patterns ...string) Option {
+	return func(c *Copier) { c.SuppressWarnings = append(c.SuppressWarnings, patterns...) }
+}
+
+// WithForce creates resources despite blocking conflicts.
+func WithForce() Option {
+	return func(c *Copier) { c.Force = true }
+}
+
+// WithSkipExisting plans resources that only conflict by already existing as
+// "exists" instead of "skip".
+func WithSkipExisting() Option {
+	return func(c *Copier) { c.SkipExisting = true }
+}
+
+// WithoutConflictCheck plans every resource as "create" without looking at
+// the target.
+func WithoutConflictCheck() Option {
+	return func(c *Copier) { c.SkipConflictCheck = true }
+}
+
+// WithIgnoredConflicts drops conflicts of the given types.
+func WithIgnoredConflicts(types ...conflict.Type) Option {
+	return func(c *Copier) { c.IgnoreConflicts = append(c.IgnoreConflicts, types...) }
+}
+
+// WithNamespaceMap maps source namespaces to target namespaces.
+func WithNamespaceMap(m map[string]string) Option {
+	return func(c *Copier) { c.NamespaceMap = m }
+}
+
+// WithParallelism applies up to n resources concurrently.
+func WithParallelism(n int) Option {
+	return func(c *Copier) { c.Parallelism = n }
+}
+
+// WithAtomic rolls the whole copy back when a create fails.
+func WithAtomic() Option {
+	return func(c *Copier) { c.Atomic = true }
+}
+
+// WithVerify reads created resources back and reports what the target changed.
+func WithVerify() Option {
+	return func(c *Copier) { c.Verify = true }
+}
+
+// WithStats records timings and API requests into s.
+func WithStats(s *Stats) Option {
+	return func(c *Copier) { c.Stats = s }
+}
+
+// WithProvenance stamps info on every created resource.
+func WithProvenance(info provenance.Info) Option {
+	return func(c *Copier) { c.Provenance = &info }
+}
+
+// WithSanitizers sanitizes with r instead of the default registry.
+func WithSanitizers(r *sanitizer.Registry) Option {
+	return func(c *Copier) { c.Sanitizers = r }
+}
+
+// WithMetadata stamps extra labels and annotations on every resource.
+func WithMetadata(m sanitizer.Metadata) Option {
+	return func(c *Copier) { c.Metadata = &m }
+}
+
+// WithImages replaces container images, keyed by container name or "*".
+func WithImages(images map[string]string) Option {
+	return func(c *Copier) { c.Images = images }
+}
+
+// WithEnv sets environment variables in copied workloads.
+func WithEnv(vars ...sanitizer.EnvVar) Option {
+	return func(c *Copier) { c.Env = append(c.Env, vars...) }
+}
+
+// WithClearHostPorts removes the hostPorts of copied pod specs.
+func WithClearHostPorts() Option {
+	return func(c *Copier) { c.ClearHostPorts = true }
+}
+
+// WithPreserveNodePorts keeps the nodePorts of copied Services.
+func WithPreserveNodePorts() Option {
+	return func(c *Copier) { c.PreserveNodePorts = true }
+}
+
+// WithPreserveClusterIP keeps the clusterIP of copied Services.
+func WithPreserveClusterIP() Option {
+	return func(c *Copier) { c.PreserveClusterIP = true }
+}
+
+// WithPreserveNodeName keeps the nodeName of copied Pods, or pins them to
+// the node name when it is not empty.
+func WithPreserveNodeName(name string) Option {
+	return func(c *Copier) {
+		c.PreserveNodeName = true
+		c.NodeName = name
+	}
+}
+
+// WithHelmMetadata keeps the Helm release metadata of copied objects.
+func WithHelmMetadata() Option {
+	return func(c *Copier) { c.KeepHelmMetadata = true }
+}
+
+// WithPersistentVolumes copies the PersistentVolumes of copied claims.
+func WithPersistentVolumes() Option {
+	return func(c *Copier) { c.CopyVolumes = true }
+}
+
+// WithCreateNamespace creates missing target namespaces from their sources.
+func WithCreateNamespace() Option {
+	return func(c *Copier) { c.CreateNamespace = true }
+}
+
+// conflictStrategy is the strategy for existing resources.
+func (c *Copier) conflictStrategy() ConflictStrategy {
+	if c.OnConflict == "" {
+		return ConflictSkip
+	}
+	return ConflictStrategy(c.OnConflict)
+}
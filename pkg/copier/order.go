@@ -0,0 +1,117 @@
+package copier
+
+import "sort"
+
+// Dependency records that Dependent should be created after Dependency, e.g.
+// a Deployment after the ConfigMap it mounts.
+type Dependency struct {
+	Dependent  ResourceRef
+	Dependency ResourceRef
+}
+
+// applyPriority orders kinds for creation: namespaces, CRDs, and identity
+// first, then configuration and storage, Services, workloads, autoscalers,
+// and finally resources that route traffic to the rest. Unknown kinds are
+// created alongside workloads.
+func applyPriority(kind string) int {
+	switch kind {
+	case "Namespace", "CustomResourceDefinition":
+		return 0
+	case "ServiceAccount", "Role", "ClusterRole", "RoleBinding", "ClusterRoleBinding":
+		return 1
+	case "Secret", "ConfigMap":
+		return 2
+	case "StorageClass", "PersistentVolume", "PersistentVolumeClaim":
+		return 3
+	case "Service":
+		return 4
+	case "HorizontalPodAutoscaler", "VerticalPodAutoscaler", "PodDisruptionBudget":
+		return 6
+	case "Ingress", "HTTPRoute", "NetworkPolicy", "ServiceMonitor", "PodMonitor":
+		return 7
+	default:
+		return 5
+	}
+}
+
+// orderForApply sorts results into creation order: by applyPriority, and
+// within a priority by deps (dependencies first), keeping the planned order
+// otherwise. Dependency cycles fall back to the planned order. Each result
+// keeps its dependencies, for the parallel ApplyAll.
+func orderForApply(results []CopyResult, deps []Dependency) []CopyResult {
+	index := map[refID]int{}
+	for i, r := range results {
+		index[idOf(r.Source)] = i
+	}
+
+	// blockers[i] lists results that must come before result i
+	blockers := make([][]int, len(results))
+	for _, d := range deps {
+		dependent, ok1 := index[idOf(d.Dependent)]
+		dependency, ok2 := index[idOf(d.Dependency)]
+		if !ok1 || !ok2 || dependent == dependency {
+			continue
+		}
+		blockers[dependent] = append(blockers[dependent], dependency)
+		results[dependent].dependencies = append(results[dependent].dependencies, idOf(d.Dependency))
+	}
+
+	order := make([]int, len(results))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return applyPriority(results[order[a]].Source.Kind) < applyPriority(results[order[b]].Source.Kind)
+	})
+
+	// Within each priority, repeatedly take the first result whose
+	// same-priority dependencies have all been placed.
+	placed := make([]bool, len(results))
+	ordered := make([]CopyResult, 0, len(results))
+	for start := 0; start < len(order); {
+		priority := applyPriority(results[order[start]].Source.Kind)
+		end := start
+		for end < len(order) && applyPriority(results[order[end]].Source.Kind) == priority {
+			end++
+		}
+
+		remaining := append([]int(nil), order[start:end]...)
+		for len(remaining) > 0 {
+			pick := 0
+			for j, i := range remaining {
+				if ready(i, blockers, placed, results, priority) {
+					pick = j
+					break
+				}
+			}
+			i := remaining[pick]
+			placed[i] = true
+			ordered = append(ordered, results[i])
+			remaining = append(remaining[:pick], remaining[pick+1:]...)
+		}
+		start = end
+	}
+
+	return ordered
+}
+
+// ready reports whether every same-priority dependency of result i is placed.
+func ready(i int, blockers [][]int, placed []bool, results []CopyResult, priority int) bool {
+	for _, b := range blockers[i] {
+		if !placed[b] && applyPriority(results[b].Source.Kind) == priority {
+			return false
+		}
+	}
+	return true
+}
+
+// refID identifies a source resource independent of its display fields.
+type refID struct {
+	Resource  string
+	Namespace string
+	Name      string
+}
+
+func idOf(ref ResourceRef) refID {
+	return refID{Resource: ref.GVR.GroupResource().String(), Namespace: ref.Namespace, Name: ref.Name}
+}
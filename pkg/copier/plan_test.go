@@ -0,0 +1,65 @@
+package copier
+
+import (
+	"testing"
+
+	"github.com/a13x22/kube-copy/pkg/conflict"
+)
+
+func TestPlanAction(t *testing.T) {
+	exists := conflict.Conflict{Type: conflict.TypeExistence, Severity: conflict.SeverityError}
+	addressError := conflict.Conflict{Type: conflict.TypeAddress, Severity: conflict.SeverityError}
+	referenceWarning := conflict.Conflict{Type: conflict.TypeReference, Severity: conflict.SeverityWarning}
+	excludedNote := conflict.Conflict{Type: conflict.TypeReference, Severity: conflict.SeverityNote}
+
+	tests := []struct {
+		name      string
+		copier    Copier
+		conflicts []conflict.Conflict
+		want      string
+	}{
+		{"no conflicts", Copier{}, nil, "create"},
+		{"note", Copier{}, []conflict.Conflict{excludedNote}, "create"},
+		{"warning", Copier{}, []conflict.Conflict{referenceWarning}, "create"},
+		{"warning with fail-on warning", Copier{FailOn: conflict.SeverityWarning}, []conflict.Conflict{referenceWarning}, "skip"},
+		{"note with fail-on warning", Copier{FailOn: conflict.SeverityWarning}, []conflict.Conflict{excludedNote}, "create"},
+		{"error", Copier{}, []conflict.Conflict{addressError}, "skip"},
+		{"error with force", Copier{Force: true}, []conflict.Conflict{addressError}, "create"},
+		{"exists", Copier{}, []conflict.Conflict{exists}, "skip"},
+		{"exists, warn", Copier{OnConflict: string(ConflictWarn)}, []conflict.Conflict{exists}, "overwrite"},
+		{"exists, overwrite", Copier{OnConflict: string(ConflictOverwrite)}, []conflict.Conflict{exists}, "overwrite"},
+		{"exists with an error, overwrite", Copier{OnConflict: string(ConflictOverwrite)}, []conflict.Conflict{exists, addressError}, "skip"},
+		{"exists with an error, overwrite and force", Copier{OnConflict: string(ConflictOverwrite), Force: true}, []conflict.Conflict{exists, addressError}, "overwrite"},
+		{"exists, skip-existing", Copier{SkipExisting: true}, []conflict.Conflict{exists}, "exists"},
+		{"exists with a warning, skip-existing", Copier{SkipExisting: true}, []conflict.Conflict{exists, referenceWarning}, "skip"},
+		{"skip-existing without conflicts", Copier{SkipExisting: true}, nil, "create"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.copier.planAction(tt.conflicts); got != tt.want {
+				t.Errorf("planAction = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBlocks(t *testing.T) {
+	tests := []struct {
+		severity conflict.Severity
+		failOn   conflict.Severity
+		want     bool
+	}{
+		{conflict.SeverityError, "", true},
+		{conflict.SeverityError, conflict.SeverityError, true},
+		{conflict.SeverityWarning, "", false},
+		{conflict.SeverityWarning, conflict.SeverityError, false},
+		{conflict.SeverityWarning, conflict.SeverityWarning, true},
+		{conflict.SeverityNote, conflict.SeverityWarning, false},
+	}
+	for _, tt := range tests {
+		c := &Copier{FailOn: tt.failOn}
+		if got := c.blocks(conflict.Conflict{Severity: tt.severity}); got != tt.want {
+			t.Errorf("blocks(%s) with FailOn %q = %v, want %v", tt.severity, tt.failOn, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package copier
+
+import (
+	"context"
+	"sort"
+)
+
+// PlanPrune finds the copies left over from earlier runs: resources of the
+// given types in the target namespaces of results that carry provenance from
+// the same source cluster and namespace, but are no longer copied -- neither
+// in results nor Excluded. Each is planned as "prune". A type that cannot be
+// listed yields a failed "prune" result, so its leftovers are reported as
+// unknown rather than silently kept. Resources without provenance are never
+// selected.
+func (c *Copier) PlanPrune(ctx context.Context, types []ResourceRef, results []CopyResult) []CopyResult {
+	type key struct{ kind, namespace, name string }
+	copied := map[key]bool{}
+	type scope struct{ targetNS, sourceNS string }
+	var scopes []scope
+	seen := map[scope]bool{}
+	for _, r := range results {
+		if !r.Source.Namespaced {
+			continue
+		}
+		copied[key{r.Source.Kind, r.TargetNS, r.TargetName}] = true
+		s := scope{r.TargetNS, r.Source.Namespace}
+		if !seen[s] {
+			seen[s] = true
+			scopes = append(scopes, s)
+		}
+	}
+	for _, ref := range c.Excluded {
+		for _, s := range scopes {
+			if ref.Namespaced && ref.Namespace == s.sourceNS {
+				copied[key{ref.Kind, s.targetNS, ref.Name}] = true
+			}
+		}
+	}
+
+	var cluster string
+	if c.Provenance != nil {
+		cluster = c.Provenance.Cluster
+	}
+	var pruned []CopyResult
+	for _, s := range scopes {
+		filter := CleanupFilter{SourceCluster: cluster, SourceNamespace: s.sourceNS}
+		for _, t := range types {
+			copies, err := c.listCopies(ctx, t, s.targetNS, filter)
+			if err != nil {
+				pruned = append(pruned, CopyResult{
+					Source:     ResourceRef{GVR: t.GVR, Kind: t.Kind, Name: "*", Namespace: s.sourceNS, Namespaced: true},
+					TargetNS:   s.targetNS,
+					TargetName: "*",
+					TargetGVR:  t.GVR,
+					Action:     "prune",
+					Error:      err,
+					ErrorClass: Classify(err),
+				})
+				continue
+			}
+			for _, r := range copies {
+				// The same object may be served by more than one group
+				k := key{r.Source.Kind, r.TargetNS, r.TargetName}
+				if copied[k] {
+					continue
+				}
+				copied[k] = true
+				r.Action = "prune"
+				pruned = append(pruned, r)
+			}
+		}
+	}
+
+	sort.SliceStable(pruned, func(i, j int) bool {
+		return applyPriority(pruned[i].Source.Kind) > applyPriority(pruned[j].Source.Kind)
+	})
+	return pruned
+}
+
+// prune deletes the planned leftovers once every copy was applied without
+// errors; otherwise they are marked "aborted", since a copy that failed may
+// be what replaces them.
+func (c *Copier) prune(ctx context.Context, copies, leftovers []CopyResult) {
+	for _, r := range copies {
+		if r.Error != nil {
+			for i := range leftovers {
+				if leftovers[i].Error == nil {
+					leftovers[i].Action = "aborted"
+				}
+			}
+			return
+		}
+	}
+	c.ApplyCleanup(ctx, leftovers)
+}
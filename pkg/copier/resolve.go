@@ -0,0 +1,74 @@
+package copier
+
+import (
+	"context"
+	"slices"
+)
+
+// Decision resolves a resource that already exists in the target, under the
+// ConflictPrompt strategy.
+type Decision struct {
+	Action string `json:"action"`         // "skip", "overwrite", "rename", or "abort"
+	Name   string `json:"name,omitempty"` // the target name to copy to instead, for "rename"
+}
+
+// resolveConflicts asks Resolve about every resource of a batch that only
+// its existence in the target keeps from being created, and applies the
+// decisions. A rename replans the batch under the new names, so references
+// to the renamed resource follow it, and asks again when the new name is
+// taken too. When a resource is aborted, it is marked "aborted" along with
+// every resource not yet decided, and CopyAll stops after planning.
+func (c *Copier) resolveConflicts(ctx context.Context, refs []ResourceRef, targetNS string, names []string, results []CopyResult) []CopyResult {
+	decided := make([]*Decision, len(results))
+	for {
+		renamed := false
+		for i := range results {
+			r := &results[i]
+			if !r.undecided {
+				r.Decision = decided[i]
+				continue
+			}
+			if ctx.Err() != nil {
+				return results
+			}
+			d := c.Resolve(r)
+			r.Decision = &d
+			switch d.Action {
+			case "overwrite":
+				r.Action = "overwrite"
+			case "rename":
+				if d.Name != "" && d.Name != names[i] {
+					names[i] = d.Name
+					decided[i] = &d
+					renamed = true
+				}
+			case "abort":
+				for j := range results {
+					if j >= i && results[j].undecided {
+						results[j].Action = "aborted"
+					}
+				}
+				return results
+			}
+		}
+		if !renamed {
+			return results
+		}
+		// Skips and overwrites hold; a renamed resource is asked about
+		// again only when its new name is taken too
+		prior := results
+		results = c.planBatch(ctx, refs, targetNS, names)
+		for i := range results {
+			if prior[i].undecided && prior[i].Decision.Action != "rename" {
+				results[i].undecided = false
+				results[i].Action = prior[i].Action
+				decided[i] = prior[i].Decision
+			}
+		}
+	}
+}
+
+// aborted reports whether Resolve aborted the copy.
+func aborted(results []CopyResult) bool {
+	return slices.ContainsFunc(results, func(r CopyResult) bool { return r.Action == "aborted" })
+}
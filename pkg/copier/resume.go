@@ -0,0 +1,46 @@
+package copier
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// resumedResult returns the result of an earlier run that already copied ref
+// to targetNS/targetName, planned as "done", when Resumed records it as
+// created, overwritten, or unchanged and the object is still in the target.
+func (c *Copier) resumedResult(ctx context.Context, ref ResourceRef, targetNS, targetName string) (CopyResult, bool) {
+	for _, prior := range c.Resumed {
+		if prior.Error != nil || prior.Source.GVR.GroupResource() != ref.GVR.GroupResource() ||
+			prior.Source.Namespace != ref.Namespace || prior.Source.Name != ref.Name ||
+			prior.TargetNS != targetNS || prior.TargetName != targetName {
+			continue
+		}
+		switch prior.Action {
+		case "created", "overwritten", "unchanged":
+		default:
+			return CopyResult{}, false
+		}
+
+		// The target may have changed since, so an object that is gone is
+		// copied again
+		_, err := c.retry(ctx, func() error {
+			_, err := c.TargetClient.Resource(prior.TargetGVR).Namespace(targetNS).Get(ctx, targetName, metav1.GetOptions{})
+			return err
+		})
+		if err != nil {
+			return CopyResult{}, false
+		}
+		return CopyResult{
+			Source:        ref,
+			TargetNS:      targetNS,
+			TargetName:    targetName,
+			TargetGVR:     prior.TargetGVR,
+			Action:        "done",
+			Verification:  prior.Verification,
+			ReadbackDiff:  prior.ReadbackDiff,
+			resumedAction: prior.Action,
+		}, true
+	}
+	return CopyResult{}, false
+}
@@ -0,0 +1,47 @@
+package copier
+
+import (
+	"context"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// isTransient reports whether an API error is worth retrying: throttling,
+// server timeouts, and optimistic-concurrency conflicts.
+func isTransient(err error) bool {
+	if _, ok := apierrors.SuggestsClientDelay(err); ok {
+		return true
+	}
+	return apierrors.IsServerTimeout(err) || apierrors.IsTimeout(err) ||
+		apierrors.IsTooManyRequests(err) || apierrors.IsConflict(err)
+}
+
+// retry calls fn until it succeeds, fails with a non-transient error, or
+// the retry policy's attempts are used up, backing off exponentially and
+// honoring the server's Retry-After. Returns the number of retries and fn's
+// last error.
+func (c *Copier) retry(ctx context.Context, fn func() error) (int, error) {
+	policy := DefaultRetryPolicy
+	if c.retryPolicy != nil {
+		policy = *c.retryPolicy
+	}
+	delay := policy.BaseDelay
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= policy.MaxAttempts || !isTransient(err) {
+			return attempt - 1, err
+		}
+
+		wait := delay
+		if seconds, ok := apierrors.SuggestsClientDelay(err); ok && seconds > 0 {
+			wait = time.Duration(seconds) * time.Second
+		}
+		select {
+		case <-ctx.Done():
+			return attempt - 1, err
+		case <-time.After(wait):
+		}
+		delay *= 2
+	}
+}
@@ -0,0 +1,58 @@
+package copier
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Stats records where the time of a run went: how long each phase took and
+// how many API requests it made. A nil *Stats records nothing.
+type Stats struct {
+	// Requests returns the number of API requests made so far; when nil,
+	// phases report no request counts.
+	Requests func() int64 `json:"-"`
+
+	Phases []PhaseStats `json:"phases"`
+}
+
+// PhaseStats is the time and API requests spent in one phase of a run.
+type PhaseStats struct {
+	Name     string
+	Duration time.Duration
+	APICalls int64
+}
+
+// MarshalJSON reports the duration in seconds, for trend tracking in CI.
+func (p PhaseStats) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Name     string  `json:"name"`
+		Seconds  float64 `json:"seconds"`
+		APICalls int64   `json:"apiCalls"`
+	}{p.Name, p.Duration.Seconds(), p.APICalls})
+}
+
+// Start begins timing the named phase and returns the function that ends
+// it. Time and requests of a phase started more than once add up.
+func (s *Stats) Start(name string) (stop func()) {
+	if s == nil {
+		return func() {}
+	}
+	start, calls := time.Now(), s.requests()
+	return func() {
+		for i := range s.Phases {
+			if s.Phases[i].Name == name {
+				s.Phases[i].Duration += time.Since(start)
+				s.Phases[i].APICalls += s.requests() - calls
+				return
+			}
+		}
+		s.Phases = append(s.Phases, PhaseStats{Name: name, Duration: time.Since(start), APICalls: s.requests() - calls})
+	}
+}
+
+func (s *Stats) requests() int64 {
+	if s.Requests == nil {
+		return 0
+	}
+	return s.Requests()
+}
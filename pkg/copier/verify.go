@@ -0,0 +1,74 @@
+package copier
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/a13x22/kube-copy/pkg/sanitizer"
+)
+
+// verify reads a just-created resource back from the target and records how
+// the target cluster changed it, typically through mutating webhooks: the
+// result is marked "verified" or "modified", or "unverified" when the
+// readback itself failed. Either of the latter adds a warning.
+func (c *Copier) verify(ctx context.Context, planned *CopyResult, resource dynamic.ResourceInterface, submitted *unstructured.Unstructured) {
+	var readback *unstructured.Unstructured
+	retries, err := c.retry(ctx, func() (err error) {
+		readback, err = resource.Get(ctx, planned.TargetName, metav1.GetOptions{})
+		return err
+	})
+	planned.Retries += retries
+	if err != nil {
+		planned.Verification = "unverified"
+		planned.Warnings = append(planned.Warnings, sanitizer.Warning{
+			Resource: planned.Source.DisplayName(),
+			Code:     sanitizer.CodeReadbackFailed,
+			Severity: sanitizer.SeverityWarning,
+			Message:  fmt.Sprintf("could not read back from the target to verify it: %v", err),
+		})
+		return
+	}
+
+	planned.ReadbackDiff = c.readbackDiff(submitted, readback, planned.TargetNS, planned.TargetName)
+	if len(planned.ReadbackDiff) == 0 {
+		planned.Verification = "verified"
+		return
+	}
+	planned.Verification = "modified"
+	paths := make([]string, len(planned.ReadbackDiff))
+	for i, d := range planned.ReadbackDiff {
+		paths[i] = d.Path
+	}
+	planned.Warnings = append(planned.Warnings, sanitizer.Warning{
+		Resource: planned.Source.DisplayName(),
+		Code:     sanitizer.CodeReadbackChanged,
+		Severity: sanitizer.SeverityWarning,
+		Message:  fmt.Sprintf("modified by target cluster: %s", strings.Join(paths, ", ")),
+	})
+}
+
+// readbackDiff compares what was submitted with what the target stored. Both
+// go through the sanitizer first, which drops status and server-managed
+// metadata. Fields the server added to an object are taken to be defaults and
+// ignored, unless they hold a non-empty list: injected sidecars, init
+// containers, and volumes are what a readback is meant to catch.
+func (c *Copier) readbackDiff(submitted, readback *unstructured.Unstructured, targetNS, targetName string) []FieldDiff {
+	sent := c.normalizeTarget(submitted, targetNS, targetName)
+	stored := c.normalizeTarget(readback, targetNS, targetName)
+
+	var diffs []FieldDiff
+	for _, d := range Diff(sent.Object, stored.Object) {
+		if d.Op == "removed" && !strings.HasSuffix(d.Path, "]") {
+			if list, ok := d.Target.([]interface{}); !ok || len(list) == 0 {
+				continue
+			}
+		}
+		diffs = append(diffs, d)
+	}
+	return diffs
+}
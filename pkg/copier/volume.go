@@ -0,0 +1,36 @@
+package copier
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/a13x22/kube-copy/pkg/sanitizer"
+)
+
+var persistentVolumeGVR = schema.GroupVersionResource{Version: "v1", Resource: "persistentvolumes"}
+
+// planVolumes plans the PersistentVolumes that the claims planned for
+// creation stay bound to, for CopyVolumes, and the dependencies that create
+// each volume before its claims. A volume keeps its name, which the copied
+// claims refer to.
+func (c *Copier) planVolumes(ctx context.Context, results []CopyResult, mapNS sanitizer.NamespaceMapper) ([]CopyResult, []Dependency) {
+	var planned []CopyResult
+	var deps []Dependency
+	seen := map[string]bool{}
+	for _, r := range results {
+		if r.volumeName == "" || r.Error != nil || r.Action != "create" && r.Action != "overwrite" {
+			continue
+		}
+		ref := ResourceRef{GVR: persistentVolumeGVR, Kind: "PersistentVolume", Name: r.volumeName}
+		deps = append(deps, Dependency{Dependent: r.Source, Dependency: ref})
+		if seen[r.volumeName] {
+			continue
+		}
+		seen[r.volumeName] = true
+		result := c.plan(ctx, ref, "", r.volumeName, nil, mapNS, nil)
+		result.volume = true
+		planned = append(planned, result)
+	}
+	return planned, deps
+}
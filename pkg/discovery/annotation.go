@@ -0,0 +1,88 @@
+package discovery
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/a13x22/kube-copy/pkg/copier"
+)
+
+// DependsOnAnnotation declares dependencies discovery cannot infer, e.g. a
+// ConfigMap read through the API at runtime. The value is a comma-separated
+// list of Kind/name entries; Kind may be qualified with its group
+// ("Certificate.cert-manager.io/web-tls").
+const DependsOnAnnotation = "kubecopy.io/depends-on"
+
+// extractDependsOnRefs parses the depends-on annotation and resolves each
+// entry's kind through the REST mapper. Malformed or unresolvable entries
+// produce warnings naming the annotation value.
+func extractDependsOnRefs(obj *unstructured.Unstructured, namespace string, mapper meta.RESTMapper) ([]link, []Warning) {
+	value, ok := obj.GetAnnotations()[DependsOnAnnotation]
+	if !ok || mapper == nil {
+		return nil, nil
+	}
+
+	var links []link
+	var warnings []Warning
+	identifier := fmt.Sprintf("%s/%s", obj.GetKind(), obj.GetName())
+	warn := func(entry, reason string) {
+		warnings = append(warnings, Warning{
+			Resource: identifier,
+			Message:  fmt.Sprintf("ignoring %q in annotation %s=%q: %s", entry, DependsOnAnnotation, value, reason),
+		})
+	}
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		kind, name, found := strings.Cut(entry, "/")
+		if !found || kind == "" || name == "" || strings.Contains(name, "/") {
+			warn(entry, "expected Kind/name")
+			continue
+		}
+
+		mapping, err := resolveKind(mapper, kind)
+		if err != nil {
+			warn(entry, fmt.Sprintf("unknown kind %q", kind))
+			continue
+		}
+		if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+			warn(entry, "cluster-scoped resources are not copied by discovery")
+			continue
+		}
+
+		links = append(links, link{
+			ref: copier.ResourceRef{
+				GVR:        mapping.Resource,
+				Kind:       mapping.GroupVersionKind.Kind,
+				Name:       name,
+				Namespace:  namespace,
+				Namespaced: true,
+			},
+			relation: "depends-on annotation",
+			required: true,
+		})
+	}
+
+	return links, warnings
+}
+
+// resolveKind maps a Kind ("ConfigMap", "Certificate.cert-manager.io") or a
+// lowercase resource name ("configmap", "configmaps") to a REST mapping.
+func resolveKind(mapper meta.RESTMapper, kind string) (*meta.RESTMapping, error) {
+	gk := schema.ParseGroupKind(kind)
+	if mapping, err := mapper.RESTMapping(gk); err == nil {
+		return mapping, nil
+	}
+	gvk, err := mapper.KindFor(schema.GroupVersionResource{Group: gk.Group, Resource: strings.ToLower(gk.Kind)})
+	if err != nil {
+		return nil, err
+	}
+	return mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+}
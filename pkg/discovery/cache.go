@@ -0,0 +1,57 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// listCache memoizes namespace List calls for the duration of a single
+// Discover run. Every queue item asks for the same Services, Ingresses, HPAs
+// and so on, so each list is fetched once and then served from memory.
+// Failed lists are cached too. Nothing is invalidated: a run sees one snapshot.
+//
+// A NotFound list means the resource type is not installed (an absent CRD)
+// and is expected. Any other failure is recorded in errors.
+type listCache struct {
+	client  dynamic.Interface
+	entries map[listKey]listEntry
+	errors  []Warning
+}
+
+type listKey struct {
+	gvr       schema.GroupVersionResource
+	namespace string
+}
+
+type listEntry struct {
+	list *unstructured.UnstructuredList
+	err  error
+}
+
+func newListCache(client dynamic.Interface) *listCache {
+	return &listCache{client: client, entries: map[listKey]listEntry{}}
+}
+
+// List returns all objects of the resource in the namespace, fetching them
+// on first use.
+func (c *listCache) List(ctx context.Context, gvr schema.GroupVersionResource, namespace string) (*unstructured.UnstructuredList, error) {
+	key := listKey{gvr: gvr, namespace: namespace}
+	if e, ok := c.entries[key]; ok {
+		return e.list, e.err
+	}
+	list, err := c.client.Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	c.entries[key] = listEntry{list: list, err: err}
+	if err != nil && !apierrors.IsNotFound(err) {
+		c.errors = append(c.errors, Warning{
+			Resource: gvr.GroupResource().String(),
+			Message:  fmt.Sprintf("listing %s in namespace %q failed: %s -- related resources of this type may be missing", gvr.GroupResource(), namespace, lookupReason(err)),
+		})
+	}
+	return list, err
+}
@@ -0,0 +1,49 @@
+package discovery
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/a13x22/kube-copy/pkg/copier"
+)
+
+var certificateGVR = schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "certificates"}
+
+// certificateNameAnnotation is set by cert-manager on the Secrets it issues.
+const certificateNameAnnotation = "cert-manager.io/certificate-name"
+
+// findCertificatesForSecret finds cert-manager Certificates that materialize
+// the given Secret, either via the annotation cert-manager puts on issued
+// Secrets or a Certificate whose spec.secretName names it. Without the
+// Certificate the copied Secret never renews. When the CRD is not installed
+// the List fails and nothing is returned.
+func findCertificatesForSecret(ctx context.Context, lists *listCache, namespace string, secret *unstructured.Unstructured) ([]copier.ResourceRef, []*unstructured.Unstructured) {
+	list, err := lists.List(ctx, certificateGVR, namespace)
+	if err != nil {
+		return nil, nil
+	}
+
+	var refs []copier.ResourceRef
+	var objs []*unstructured.Unstructured
+	annotated := secret.GetAnnotations()[certificateNameAnnotation]
+
+	for i := range list.Items {
+		cert := &list.Items[i]
+		secretName, _, _ := unstructured.NestedString(cert.Object, "spec", "secretName")
+		if secretName != secret.GetName() && (annotated == "" || cert.GetName() != annotated) {
+			continue
+		}
+		refs = append(refs, copier.ResourceRef{
+			GVR:        certificateGVR,
+			Kind:       "Certificate",
+			Name:       cert.GetName(),
+			Namespace:  namespace,
+			Namespaced: true,
+		})
+		objs = append(objs, cert)
+	}
+
+	return refs, objs
+}
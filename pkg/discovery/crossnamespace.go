@@ -0,0 +1,74 @@
+package discovery
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/a13x22/kube-copy/pkg/copier"
+	"github.com/a13x22/kube-copy/pkg/sanitizer"
+)
+
+// extractCrossNamespaceRefs finds references that may point outside the
+// object's own namespace: the Service behind an ExternalName Service's
+// in-cluster DNS name, Secrets named by Ingress controller annotations, and
+// the ServiceAccount subjects of a RoleBinding. Each ref carries the
+// namespace it actually points to.
+func extractCrossNamespaceRefs(obj *unstructured.Unstructured, namespace string) []link {
+	var links []link
+
+	add := func(gvr schema.GroupVersionResource, kind, relation, name, ns string) {
+		links = append(links, link{
+			ref: copier.ResourceRef{
+				GVR:        gvr,
+				Kind:       kind,
+				Name:       name,
+				Namespace:  ns,
+				Namespaced: true,
+			},
+			relation: relation,
+		})
+	}
+
+	switch obj.GetKind() {
+	case "Service":
+		host, _, _ := unstructured.NestedString(obj.Object, "spec", "externalName")
+		if name, ns, ok := sanitizer.ParseServiceHost(host); ok {
+			add(schema.GroupVersionResource{Version: "v1", Resource: "services"}, "Service", "externalName", name, ns)
+		}
+	case "Ingress":
+		for _, key := range sanitizer.IngressSecretAnnotations {
+			value := obj.GetAnnotations()[key]
+			if value == "" {
+				continue
+			}
+			ns, name, found := strings.Cut(value, "/")
+			if !found {
+				ns, name = namespace, value
+			}
+			add(schema.GroupVersionResource{Version: "v1", Resource: "secrets"}, "Secret", "annotation "+key, name, ns)
+		}
+	case "RoleBinding":
+		subjects, _, _ := unstructured.NestedSlice(obj.Object, "subjects")
+		for _, s := range subjects {
+			subject, ok := s.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if kind, _ := subject["kind"].(string); kind != "ServiceAccount" {
+				continue
+			}
+			name, _ := subject["name"].(string)
+			ns, _ := subject["namespace"].(string)
+			if ns == "" {
+				ns = namespace
+			}
+			if name != "" && name != "default" {
+				add(schema.GroupVersionResource{Version: "v1", Resource: "serviceaccounts"}, "ServiceAccount", "role binding subject", name, ns)
+			}
+		}
+	}
+
+	return links
+}
@@ -0,0 +1,111 @@
+package discovery
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/a13x22/kube-copy/pkg/copier"
+)
+
+const gatewayGroup = "gateway.networking.k8s.io"
+
+var (
+	httpRouteGVR = schema.GroupVersionResource{Group: gatewayGroup, Version: "v1", Resource: "httproutes"}
+	gatewayGVR   = schema.GroupVersionResource{Group: gatewayGroup, Version: "v1", Resource: "gateways"}
+)
+
+// findHTTPRoutesForService finds Gateway API HTTPRoutes with a backendRef
+// naming the given Service. When the CRD is not installed the List fails and
+// nothing is returned.
+func findHTTPRoutesForService(ctx context.Context, lists *listCache, namespace, serviceName string) ([]copier.ResourceRef, []*unstructured.Unstructured) {
+	list, err := lists.List(ctx, httpRouteGVR, namespace)
+	if err != nil {
+		return nil, nil
+	}
+
+	var refs []copier.ResourceRef
+	var objs []*unstructured.Unstructured
+
+	for i := range list.Items {
+		route := &list.Items[i]
+		if !httpRouteReferencesService(route, namespace, serviceName) {
+			continue
+		}
+		refs = append(refs, copier.ResourceRef{
+			GVR:        httpRouteGVR,
+			Kind:       "HTTPRoute",
+			Name:       route.GetName(),
+			Namespace:  namespace,
+			Namespaced: true,
+		})
+		objs = append(objs, route)
+	}
+
+	return refs, objs
+}
+
+// httpRouteReferencesService checks whether any rule of the route has a
+// backendRef to the named Service in the route's namespace.
+func httpRouteReferencesService(route *unstructured.Unstructured, namespace, serviceName string) bool {
+	rules, _, _ := unstructured.NestedSlice(route.Object, "spec", "rules")
+	for _, r := range rules {
+		rule, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		backends, _ := rule["backendRefs"].([]interface{})
+		for _, b := range backends {
+			backend, ok := b.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			// group and kind default to the core Service
+			group, _ := backend["group"].(string)
+			kind, _ := backend["kind"].(string)
+			ns, _ := backend["namespace"].(string)
+			name, _ := backend["name"].(string)
+			if group == "" && (kind == "" || kind == "Service") && (ns == "" || ns == namespace) && name == serviceName {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// extractGatewayRefs returns the same-namespace Gateways an HTTPRoute
+// attaches to. Gateways are often shared infrastructure, so they are only
+// followed when Options.IncludeGateways is set.
+func extractGatewayRefs(obj *unstructured.Unstructured, namespace string) []link {
+	if obj.GetKind() != "HTTPRoute" {
+		return nil
+	}
+
+	var links []link
+	parents, _, _ := unstructured.NestedSlice(obj.Object, "spec", "parentRefs")
+	for _, p := range parents {
+		parent, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		group, _ := parent["group"].(string)
+		kind, _ := parent["kind"].(string)
+		ns, _ := parent["namespace"].(string)
+		name, _ := parent["name"].(string)
+		if (group != "" && group != gatewayGroup) || (kind != "" && kind != "Gateway") || (ns != "" && ns != namespace) || name == "" {
+			continue
+		}
+		links = append(links, link{
+			ref: copier.ResourceRef{
+				GVR:        gatewayGVR,
+				Kind:       "Gateway",
+				Name:       name,
+				Namespace:  namespace,
+				Namespaced: true,
+			},
+			relation: "parent gateway",
+		})
+	}
+	return links
+}
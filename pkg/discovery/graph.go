@@ -4,12 +4,15 @@ import (
 	"context"
 	"fmt"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 
 	"github.com/a13x22/kube-copy/pkg/copier"
+	"github.com/a13x22/kube-copy/pkg/selector"
 )
 
 // refKey uniquely identifies a resource for cycle detection.
@@ -19,12 +22,126 @@ type refKey struct {
 	Namespace string
 }
 
-// Discover finds all related resources for the given primary resource.
-// Returns additional ResourceRefs that should be copied alongside the primary.
+// Warning is an advisory message produced during discovery, e.g. about a
+// dependency that was deliberately not added to the graph.
+type Warning struct {
+	Resource string // e.g. "RoleBinding/my-binding"
+	Message  string
+}
+
+// IgnoreLabel pins a resource so discovery never sweeps it into a copy, e.g.
+// a shared Secret managed by an external system.
+const IgnoreLabel = "kubecopy.io/ignore"
+
+// ExcludedBySkipSecrets is the Edge.ExcludedBy of Secrets left out because
+// Options.SkipSecrets is set.
+const ExcludedBySkipSecrets = "skip-secrets"
+
+// isIgnored reports whether the object opts out of discovery via IgnoreLabel.
+func isIgnored(obj *unstructured.Unstructured) bool {
+	return obj.GetLabels()[IgnoreLabel] == "true"
+}
+
+// excludedBy returns why a discovered object is left out of the graph:
+// IgnoreLabel, ExcludedBySkipSecrets, or empty when it is not.
+func excludedBy(ref copier.ResourceRef, obj *unstructured.Unstructured, opts Options) string {
+	switch {
+	case opts.SkipSecrets && ref.GVR.Group == "" && ref.GVR.Resource == "secrets":
+		return ExcludedBySkipSecrets
+	case obj != nil && isIgnored(obj):
+		return IgnoreLabel
+	}
+	return ""
+}
+
+// Options controls which optional relationships discovery follows.
+type Options struct {
+	// IncludeGateways follows HTTPRoutes to the Gateways they attach to.
+	// Gateways are often shared infrastructure, so this is off by default.
+	IncludeGateways bool
+
+	// IncludeStatefulSetPVCs follows StatefulSets to the claims created
+	// from their volumeClaimTemplates, which only their names tie to them.
+	IncludeStatefulSetPVCs bool
+
+	// Mapper resolves the kinds listed in the depends-on annotation. When
+	// nil the annotation is ignored.
+	Mapper meta.RESTMapper
+
+	// SkipSecrets leaves every Secret out of the graph, as if it carried
+	// IgnoreLabel. The root is always kept.
+	SkipSecrets bool
+}
+
+// Edge records why a resource was added to the graph: From references or is
+// referenced by To through Relation (e.g. "configMap reference",
+// "service selector match").
+type Edge struct {
+	From     copier.ResourceRef
+	To       copier.ResourceRef
+	Relation string
+	Forward  bool // From references To, so To must exist first; otherwise To points at From
+
+	// ExcludedBy is why To was left out, for the edges in Graph.Excluded:
+	// IgnoreLabel or ExcludedBySkipSecrets.
+	ExcludedBy string
+}
+
+// Graph is the result of dependency discovery.
+type Graph struct {
+	Root     copier.ResourceRef
+	Edges    []Edge // in discovery order; every discovered resource is the To of exactly one edge
+	Warnings []Warning
+	Excluded []Edge // resources left out because they carry IgnoreLabel or are skipped Secrets
+
+	// Errors are lookups that failed for a reason other than NotFound (RBAC,
+	// connectivity), so the graph may be missing resources.
+	Errors []Warning
+}
+
+// Dependencies returns the creation-order constraints implied by the edges.
+func (g *Graph) Dependencies() []copier.Dependency {
+	deps := make([]copier.Dependency, len(g.Edges))
+	for i, e := range g.Edges {
+		if e.Forward {
+			deps[i] = copier.Dependency{Dependent: e.From, Dependency: e.To}
+		} else {
+			deps[i] = copier.Dependency{Dependent: e.To, Dependency: e.From}
+		}
+	}
+	return deps
+}
+
+// Refs returns the discovered resources, excluding the root, in discovery order.
+func (g *Graph) Refs() []copier.ResourceRef {
+	refs := make([]copier.ResourceRef, len(g.Edges))
+	for i, e := range g.Edges {
+		refs[i] = e.To
+	}
+	return refs
+}
+
+// link is a resource found from another during traversal. obj is nil for
+// forward references, which still need to be fetched. A required forward
+// reference that does not exist in the source produces a warning. forward
+// marks a listed resource that the other depends on all the same, such as
+// the claims of a StatefulSet.
+type link struct {
+	ref      copier.ResourceRef
+	obj      *unstructured.Unstructured
+	relation string
+	required bool
+	forward  bool
+}
+
+// Discover finds all related resources for the given primary resource and
+// returns them as a graph rooted at the primary, together with warnings about
+// dependencies that were left out. Each discovered ref carries the namespace
+// it lives in, which differs from the primary's for cross-namespace references.
 // Uses BFS to traverse the dependency graph with cycle detection.
-func Discover(ctx context.Context, client dynamic.Interface, gvr schema.GroupVersionResource, name, namespace string) ([]copier.ResourceRef, error) {
+func Discover(ctx context.Context, client dynamic.Interface, gvr schema.GroupVersionResource, name, namespace string, opts Options) (*Graph, error) {
 	visited := map[refKey]bool{}
-	var result []copier.ResourceRef
+	lists := newListCache(client)
 
 	// Mark the primary resource as visited
 	primaryKey := refKey{Resource: gvr.Resource, Name: name, Namespace: namespace}
@@ -36,103 +153,203 @@ func Discover(ctx context.Context, client dynamic.Interface, gvr schema.GroupVer
 		return nil, fmt.Errorf("fetching primary resource %s/%s: %w", gvr.Resource, name, err)
 	}
 
+	kind := primaryObj.GetKind()
+	if kind == "" {
+		kind = gvrKind(gvr)
+	}
+	graph := &Graph{Root: copier.ResourceRef{GVR: gvr, Kind: kind, Name: name, Namespace: namespace, Namespaced: true}}
+
 	// BFS queue
 	type queueItem struct {
 		obj *unstructured.Unstructured
 		ref copier.ResourceRef
 	}
-	queue := []queueItem{{obj: primaryObj, ref: copier.ResourceRef{GVR: gvr, Kind: gvrKind(gvr), Name: name, Namespace: namespace, Namespaced: true}}}
+	queue := []queueItem{{obj: primaryObj, ref: graph.Root}}
 
 	for len(queue) > 0 {
 		current := queue[0]
 		queue = queue[1:]
+		// References are resolved relative to the object's own namespace
+		ns := current.ref.Namespace
 
 		// Discover forward references (ConfigMaps, Secrets, PVCs, ServiceAccounts)
-		forwardRefs := extractForwardRefs(current.obj, namespace)
-		for _, ref := range forwardRefs {
-			key := refKey{Resource: ref.GVR.Resource, Name: ref.Name, Namespace: ref.Namespace}
+		forwardLinks := extractForwardRefs(current.obj, ns)
+		forwardLinks = append(forwardLinks, extractCrossNamespaceRefs(current.obj, ns)...)
+		if opts.IncludeGateways {
+			forwardLinks = append(forwardLinks, extractGatewayRefs(current.obj, ns)...)
+		}
+		annotatedLinks, annotationWarnings := extractDependsOnRefs(current.obj, ns, opts.Mapper)
+		forwardLinks = append(forwardLinks, annotatedLinks...)
+		graph.Warnings = append(graph.Warnings, annotationWarnings...)
+		for _, l := range forwardLinks {
+			key := refKey{Resource: l.ref.GVR.Resource, Name: l.ref.Name, Namespace: l.ref.Namespace}
 			if visited[key] {
 				continue
 			}
 			visited[key] = true
 
 			// Verify the resource exists before adding
-			obj, err := client.Resource(ref.GVR).Namespace(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+			obj, err := client.Resource(l.ref.GVR).Namespace(l.ref.Namespace).Get(ctx, l.ref.Name, metav1.GetOptions{})
+			if err != nil && !apierrors.IsNotFound(err) {
+				// RBAC or connectivity problem: the graph may be incomplete
+				graph.Errors = append(graph.Errors, Warning{
+					Resource: l.ref.DisplayName(),
+					Message:  fmt.Sprintf("fetching %s (%s of %s) failed: %s", l.ref.DisplayName(), l.relation, current.ref.DisplayName(), lookupReason(err)),
+				})
+				continue
+			}
 			if err != nil {
-				// Resource doesn't exist in source -- skip silently
+				// Resource doesn't exist in source -- skip, warning only when the
+				// referencing object is misconfigured without it
+				if l.required {
+					graph.Warnings = append(graph.Warnings, Warning{
+						Resource: current.ref.DisplayName(),
+						Message:  fmt.Sprintf("%s %s does not exist in source namespace %q", l.relation, l.ref.DisplayName(), l.ref.Namespace),
+					})
+				}
 				continue
 			}
 
-			result = append(result, ref)
+			edge := Edge{From: current.ref, To: l.ref, Relation: l.relation, Forward: true}
+			if edge.ExcludedBy = excludedBy(l.ref, obj, opts); edge.ExcludedBy != "" {
+				graph.Excluded = append(graph.Excluded, edge)
+				continue
+			}
+			graph.Edges = append(graph.Edges, edge)
 
 			// ConfigMaps, Secrets, PVCs, and SAs don't typically reference other resources,
 			// but we still add them to the queue for completeness
-			queue = append(queue, queueItem{obj: obj, ref: ref})
+			queue = append(queue, queueItem{obj: obj, ref: l.ref})
 		}
 
 		// Discover reverse references (Services, Ingresses, HPAs that point to this resource)
-		reverseRefs, reverseObjs := discoverReverseRefs(ctx, client, current.obj, namespace)
-		for i, ref := range reverseRefs {
-			key := refKey{Resource: ref.GVR.Resource, Name: ref.Name, Namespace: ref.Namespace}
+		reverseLinks, reverseWarnings := discoverReverseRefs(ctx, lists, current.obj, ns)
+		graph.Warnings = append(graph.Warnings, reverseWarnings...)
+		if opts.IncludeStatefulSetPVCs {
+			claimLinks, claimErrors := findStatefulSetClaims(ctx, lists, current.obj, ns)
+			reverseLinks = append(reverseLinks, claimLinks...)
+			graph.Errors = append(graph.Errors, claimErrors...)
+		}
+		for _, l := range reverseLinks {
+			key := refKey{Resource: l.ref.GVR.Resource, Name: l.ref.Name, Namespace: l.ref.Namespace}
 			if visited[key] {
 				continue
 			}
 			visited[key] = true
-			result = append(result, ref)
+			edge := Edge{From: current.ref, To: l.ref, Relation: l.relation, Forward: l.forward}
+			if edge.ExcludedBy = excludedBy(l.ref, l.obj, opts); edge.ExcludedBy != "" {
+				graph.Excluded = append(graph.Excluded, edge)
+				continue
+			}
+			graph.Edges = append(graph.Edges, edge)
 
 			// Continue traversal for reverse refs (e.g., Service -> Ingress chain)
-			if reverseObjs[i] != nil {
-				queue = append(queue, queueItem{obj: reverseObjs[i], ref: ref})
+			if l.obj != nil {
+				queue = append(queue, queueItem{obj: l.obj, ref: l.ref})
 			}
 		}
 	}
 
-	return result, nil
+	graph.Errors = append(graph.Errors, lists.errors...)
+
+	return graph, nil
+}
+
+// lookupReason returns a short description of why a source lookup failed.
+func lookupReason(err error) string {
+	switch {
+	case apierrors.IsForbidden(err):
+		return "forbidden"
+	case apierrors.IsUnauthorized(err):
+		return "unauthorized"
+	case apierrors.IsTimeout(err) || apierrors.IsServerTimeout(err):
+		return "timeout"
+	default:
+		return err.Error()
+	}
 }
 
 // discoverReverseRefs finds resources that depend on the given object:
 // - Services whose selector matches the pod template labels
-// - Ingresses whose backends reference those Services
-// - HPAs that target this resource
-func discoverReverseRefs(ctx context.Context, client dynamic.Interface, obj *unstructured.Unstructured, namespace string) ([]copier.ResourceRef, []*unstructured.Unstructured) {
-	var refs []copier.ResourceRef
-	var objs []*unstructured.Unstructured
+// - Ingresses and HTTPRoutes whose backends reference those Services
+// - HPAs and VPAs that target this resource
+// - RoleBindings granting to a ServiceAccount
+// - NetworkPolicies whose podSelector matches the pod template labels
+// - ServiceMonitors and PodMonitors selecting the Services/pods
+// - cert-manager Certificates issuing a Secret
+func discoverReverseRefs(ctx context.Context, lists *listCache, obj *unstructured.Unstructured, namespace string) ([]link, []Warning) {
+	var links []link
+	var warnings []Warning
+
+	add := func(relation string, refs []copier.ResourceRef, objs []*unstructured.Unstructured) {
+		for i := range refs {
+			links = append(links, link{ref: refs[i], obj: objs[i], relation: relation})
+		}
+	}
 
 	kind := obj.GetKind()
 
-	// Services matching pod template labels (only for workload resources)
+	// Services and NetworkPolicies matching pod template labels (only for workload resources)
 	switch kind {
 	case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "Pod":
 		podLabels := extractPodTemplateLabels(obj)
 		if len(podLabels) > 0 {
-			svcRefs, svcObjs := findMatchingServices(ctx, client, namespace, podLabels)
-			refs = append(refs, svcRefs...)
-			objs = append(objs, svcObjs...)
+			svcRefs, svcObjs := findMatchingServices(ctx, lists, namespace, podLabels)
+			add("service selector match", svcRefs, svcObjs)
 		}
+
+		npRefs, npObjs, npWarnings := findNetworkPoliciesForPods(ctx, lists, namespace, podLabels)
+		add("network policy pod selector", npRefs, npObjs)
+		warnings = append(warnings, npWarnings...)
+
+		pmRefs, pmObjs := findPodMonitorsForPods(ctx, lists, namespace, podLabels)
+		add("pod monitor selector", pmRefs, pmObjs)
 	}
 
-	// Ingresses pointing to Services
+	// Ingresses, HTTPRoutes and ServiceMonitors pointing to Services
 	if kind == "Service" {
-		ingRefs, ingObjs := findIngressesForService(ctx, client, namespace, obj.GetName())
-		refs = append(refs, ingRefs...)
-		objs = append(objs, ingObjs...)
+		ingRefs, ingObjs := findIngressesForService(ctx, lists, namespace, obj.GetName())
+		add("ingress backend", ingRefs, ingObjs)
+		routeRefs, routeObjs := findHTTPRoutesForService(ctx, lists, namespace, obj.GetName())
+		add("HTTPRoute backend", routeRefs, routeObjs)
+		smRefs, smObjs := findServiceMonitorsForService(ctx, lists, namespace, obj.GetLabels())
+		add("service monitor selector", smRefs, smObjs)
 	}
 
 	// HPAs targeting this resource
 	switch kind {
 	case "Deployment", "StatefulSet", "ReplicaSet":
-		hpaRefs, hpaObjs := findHPAsForResource(ctx, client, namespace, obj.GetKind(), obj.GetName())
-		refs = append(refs, hpaRefs...)
-		objs = append(objs, hpaObjs...)
+		hpaRefs, hpaObjs := findHPAsForResource(ctx, lists, namespace, obj.GetKind(), obj.GetName())
+		add("HPA scale target", hpaRefs, hpaObjs)
 	}
 
-	return refs, objs
+	// VPAs targeting this resource
+	switch kind {
+	case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "Job", "CronJob":
+		vpaRefs, vpaObjs := findVPAsForResource(ctx, lists, namespace, obj.GetKind(), obj.GetName())
+		add("VPA target", vpaRefs, vpaObjs)
+	}
+
+	// cert-manager Certificates that issue a Secret
+	if kind == "Secret" {
+		certRefs, certObjs := findCertificatesForSecret(ctx, lists, namespace, obj)
+		add("certificate secret", certRefs, certObjs)
+	}
+
+	// RoleBindings granting permissions to a ServiceAccount
+	if kind == "ServiceAccount" {
+		rbRefs, rbObjs, rbWarnings := findRoleBindingsForServiceAccount(ctx, lists, namespace, obj.GetName())
+		add("role binding subject", rbRefs, rbObjs)
+		warnings = append(warnings, rbWarnings...)
+	}
+
+	return links, warnings
 }
 
 // findMatchingServices finds Services whose selector is a subset of the given labels.
-func findMatchingServices(ctx context.Context, client dynamic.Interface, namespace string, podLabels map[string]string) ([]copier.ResourceRef, []*unstructured.Unstructured) {
+func findMatchingServices(ctx context.Context, lists *listCache, namespace string, podLabels map[string]string) ([]copier.ResourceRef, []*unstructured.Unstructured) {
 	svcGVR := schema.GroupVersionResource{Version: "v1", Resource: "services"}
-	svcList, err := client.Resource(svcGVR).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	svcList, err := lists.List(ctx, svcGVR, namespace)
 	if err != nil {
 		return nil, nil
 	}
@@ -146,26 +363,10 @@ func findMatchingServices(ctx context.Context, client dynamic.Interface, namespa
 		if !ok {
 			continue
 		}
-		selectorRaw, ok := spec["selector"].(map[string]interface{})
-		if !ok || len(selectorRaw) == 0 {
-			continue
-		}
+		selectorRaw, _ := spec["selector"].(map[string]interface{})
 
 		// Check if all selector labels match the pod template labels
-		match := true
-		for k, v := range selectorRaw {
-			sv, ok := v.(string)
-			if !ok {
-				match = false
-				break
-			}
-			if podLabels[k] != sv {
-				match = false
-				break
-			}
-		}
-
-		if match {
+		if selector.MatchesMap(selectorRaw, podLabels) {
 			refs = append(refs, copier.ResourceRef{
 				GVR:        svcGVR,
 				Kind:       "Service",
@@ -181,9 +382,9 @@ func findMatchingServices(ctx context.Context, client dynamic.Interface, namespa
 }
 
 // findIngressesForService finds Ingresses that reference the given Service.
-func findIngressesForService(ctx context.Context, client dynamic.Interface, namespace, serviceName string) ([]copier.ResourceRef, []*unstructured.Unstructured) {
+func findIngressesForService(ctx context.Context, lists *listCache, namespace, serviceName string) ([]copier.ResourceRef, []*unstructured.Unstructured) {
 	ingGVR := schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"}
-	ingList, err := client.Resource(ingGVR).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	ingList, err := lists.List(ctx, ingGVR, namespace)
 	if err != nil {
 		return nil, nil
 	}
@@ -263,13 +464,13 @@ func ingressReferencesService(ing *unstructured.Unstructured, serviceName string
 }
 
 // findHPAsForResource finds HPAs targeting the given resource.
-func findHPAsForResource(ctx context.Context, client dynamic.Interface, namespace, kind, name string) ([]copier.ResourceRef, []*unstructured.Unstructured) {
+func findHPAsForResource(ctx context.Context, lists *listCache, namespace, kind, name string) ([]copier.ResourceRef, []*unstructured.Unstructured) {
 	hpaGVR := schema.GroupVersionResource{Group: "autoscaling", Version: "v2", Resource: "horizontalpodautoscalers"}
-	hpaList, err := client.Resource(hpaGVR).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	hpaList, err := lists.List(ctx, hpaGVR, namespace)
 	if err != nil {
 		// Try v1 if v2 is not available
 		hpaGVR = schema.GroupVersionResource{Group: "autoscaling", Version: "v1", Resource: "horizontalpodautoscalers"}
-		hpaList, err = client.Resource(hpaGVR).Namespace(namespace).List(ctx, metav1.ListOptions{})
+		hpaList, err = lists.List(ctx, hpaGVR, namespace)
 		if err != nil {
 			return nil, nil
 		}
@@ -305,24 +506,65 @@ func findHPAsForResource(ctx context.Context, client dynamic.Interface, namespac
 	return refs, objs
 }
 
+// findVPAsForResource finds VerticalPodAutoscalers targeting the given resource.
+// VPA is a CRD; when it is not installed the List fails and nothing is returned.
+func findVPAsForResource(ctx context.Context, lists *listCache, namespace, kind, name string) ([]copier.ResourceRef, []*unstructured.Unstructured) {
+	vpaGVR := schema.GroupVersionResource{Group: "autoscaling.k8s.io", Version: "v1", Resource: "verticalpodautoscalers"}
+	vpaList, err := lists.List(ctx, vpaGVR, namespace)
+	if err != nil {
+		return nil, nil
+	}
+
+	var refs []copier.ResourceRef
+	var objs []*unstructured.Unstructured
+
+	for i := range vpaList.Items {
+		vpa := &vpaList.Items[i]
+		spec, ok := vpa.Object["spec"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		targetRef, ok := spec["targetRef"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		refKind, _ := targetRef["kind"].(string)
+		refName, _ := targetRef["name"].(string)
+		if refKind == kind && refName == name {
+			refs = append(refs, copier.ResourceRef{
+				GVR:        vpaGVR,
+				Kind:       "VerticalPodAutoscaler",
+				Name:       vpa.GetName(),
+				Namespace:  namespace,
+				Namespaced: true,
+			})
+			objs = append(objs, vpa)
+		}
+	}
+
+	return refs, objs
+}
+
 // gvrKind maps a GVR resource name to a human-friendly Kind string.
 func gvrKind(gvr schema.GroupVersionResource) string {
 	kinds := map[string]string{
-		"deployments":               "Deployment",
-		"statefulsets":              "StatefulSet",
-		"daemonsets":                "DaemonSet",
-		"replicasets":               "ReplicaSet",
-		"pods":                      "Pod",
-		"services":                  "Service",
-		"configmaps":                "ConfigMap",
-		"secrets":                   "Secret",
-		"serviceaccounts":           "ServiceAccount",
-		"persistentvolumeclaims":    "PersistentVolumeClaim",
-		"ingresses":                 "Ingress",
-		"jobs":                      "Job",
-		"cronjobs":                  "CronJob",
-		"horizontalpodautoscalers":  "HorizontalPodAutoscaler",
-		"networkpolicies":           "NetworkPolicy",
+		"deployments":              "Deployment",
+		"statefulsets":             "StatefulSet",
+		"daemonsets":               "DaemonSet",
+		"replicasets":              "ReplicaSet",
+		"pods":                     "Pod",
+		"services":                 "Service",
+		"configmaps":               "ConfigMap",
+		"secrets":                  "Secret",
+		"serviceaccounts":          "ServiceAccount",
+		"persistentvolumeclaims":   "PersistentVolumeClaim",
+		"ingresses":                "Ingress",
+		"jobs":                     "Job",
+		"cronjobs":                 "CronJob",
+		"horizontalpodautoscalers": "HorizontalPodAutoscaler",
+		"networkpolicies":          "NetworkPolicy",
+		"rolebindings":             "RoleBinding",
+		"roles":                    "Role",
 	}
 	if k, ok := kinds[gvr.Resource]; ok {
 		return k
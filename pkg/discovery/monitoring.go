@@ -0,0 +1,82 @@
+package discovery
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/a13x22/kube-copy/pkg/copier"
+	"github.com/a13x22/kube-copy/pkg/selector"
+)
+
+var (
+	serviceMonitorGVR = schema.GroupVersionResource{Group: "monitoring.coreos.com", Version: "v1", Resource: "servicemonitors"}
+	podMonitorGVR     = schema.GroupVersionResource{Group: "monitoring.coreos.com", Version: "v1", Resource: "podmonitors"}
+)
+
+// findServiceMonitorsForService finds Prometheus Operator ServiceMonitors whose
+// selector matches the Service's labels. When the CRD is not installed the
+// List fails and nothing is returned.
+func findServiceMonitorsForService(ctx context.Context, lists *listCache, namespace string, svcLabels map[string]string) ([]copier.ResourceRef, []*unstructured.Unstructured) {
+	return findMonitors(ctx, lists, serviceMonitorGVR, "ServiceMonitor", namespace, svcLabels)
+}
+
+// findPodMonitorsForPods finds Prometheus Operator PodMonitors whose selector
+// matches the pod template labels.
+func findPodMonitorsForPods(ctx context.Context, lists *listCache, namespace string, podLabels map[string]string) ([]copier.ResourceRef, []*unstructured.Unstructured) {
+	return findMonitors(ctx, lists, podMonitorGVR, "PodMonitor", namespace, podLabels)
+}
+
+func findMonitors(ctx context.Context, lists *listCache, gvr schema.GroupVersionResource, kind, namespace string, labels map[string]string) ([]copier.ResourceRef, []*unstructured.Unstructured) {
+	if len(labels) == 0 {
+		return nil, nil
+	}
+	list, err := lists.List(ctx, gvr, namespace)
+	if err != nil {
+		return nil, nil
+	}
+
+	var refs []copier.ResourceRef
+	var objs []*unstructured.Unstructured
+
+	for i := range list.Items {
+		mon := &list.Items[i]
+		sel, found, _ := unstructured.NestedMap(mon.Object, "spec", "selector")
+		// An empty selector would match every object; only explicit selectors count
+		if !found || selector.IsEmptyLabelSelector(sel) || !selector.MatchesLabelSelector(sel, labels) {
+			continue
+		}
+		if !monitorWatchesNamespace(mon, namespace) {
+			continue
+		}
+		refs = append(refs, copier.ResourceRef{
+			GVR:        gvr,
+			Kind:       kind,
+			Name:       mon.GetName(),
+			Namespace:  namespace,
+			Namespaced: true,
+		})
+		objs = append(objs, mon)
+	}
+
+	return refs, objs
+}
+
+// monitorWatchesNamespace checks spec.namespaceSelector: by default a monitor
+// only watches its own namespace, "any" watches all, and matchNames lists them.
+func monitorWatchesNamespace(mon *unstructured.Unstructured, namespace string) bool {
+	if watchAll, _, _ := unstructured.NestedBool(mon.Object, "spec", "namespaceSelector", "any"); watchAll {
+		return true
+	}
+	names, found, _ := unstructured.NestedStringSlice(mon.Object, "spec", "namespaceSelector", "matchNames")
+	if !found || len(names) == 0 {
+		return true
+	}
+	for _, n := range names {
+		if n == namespace {
+			return true
+		}
+	}
+	return false
+}
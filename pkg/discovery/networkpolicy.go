@@ -0,0 +1,54 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/a13x22/kube-copy/pkg/copier"
+	"github.com/a13x22/kube-copy/pkg/selector"
+)
+
+var networkPolicyGVR = schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "networkpolicies"}
+
+// findNetworkPoliciesForPods finds NetworkPolicies whose spec.podSelector
+// selects the given pod labels. A policy with an empty podSelector applies to
+// every pod in the namespace; it is included with a warning.
+func findNetworkPoliciesForPods(ctx context.Context, lists *listCache, namespace string, podLabels map[string]string) ([]copier.ResourceRef, []*unstructured.Unstructured, []Warning) {
+	npList, err := lists.List(ctx, networkPolicyGVR, namespace)
+	if err != nil {
+		return nil, nil, nil
+	}
+
+	var refs []copier.ResourceRef
+	var objs []*unstructured.Unstructured
+	var warnings []Warning
+
+	for i := range npList.Items {
+		np := &npList.Items[i]
+		podSelector, _, _ := unstructured.NestedMap(np.Object, "spec", "podSelector")
+		if !selector.MatchesLabelSelector(podSelector, podLabels) {
+			continue
+		}
+
+		refs = append(refs, copier.ResourceRef{
+			GVR:        networkPolicyGVR,
+			Kind:       "NetworkPolicy",
+			Name:       np.GetName(),
+			Namespace:  namespace,
+			Namespaced: true,
+		})
+		objs = append(objs, np)
+
+		if selector.IsEmptyLabelSelector(podSelector) {
+			warnings = append(warnings, Warning{
+				Resource: fmt.Sprintf("NetworkPolicy/%s", np.GetName()),
+				Message:  "has an empty podSelector and applies to every pod in the target namespace",
+			})
+		}
+	}
+
+	return refs, objs, warnings
+}
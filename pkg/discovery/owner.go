@@ -0,0 +1,76 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/a13x22/kube-copy/pkg/copier"
+)
+
+// maxOwnerDepth bounds the ownerReference walk in case of cycles.
+const maxOwnerDepth = 10
+
+// FindTopLevelOwner follows the controller ownerReference chain of the given
+// resource (e.g. Pod -> ReplicaSet -> Deployment) and returns the top-level
+// controller. Returns nil when the resource has no owner. When an owner in the
+// chain cannot be fetched (deleted, or its type unknown), the last owner that
+// could be resolved is returned.
+func FindTopLevelOwner(ctx context.Context, client dynamic.Interface, mapper meta.RESTMapper, ref copier.ResourceRef) (*copier.ResourceRef, error) {
+	obj, err := client.Resource(ref.GVR).Namespace(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", ref.DisplayName(), err)
+	}
+
+	var top *copier.ResourceRef
+	for depth := 0; depth < maxOwnerDepth; depth++ {
+		owner := controllerOf(obj)
+		if owner == nil {
+			break
+		}
+
+		gv, err := schema.ParseGroupVersion(owner.APIVersion)
+		if err != nil {
+			break
+		}
+		mapping, err := mapper.RESTMapping(schema.GroupKind{Group: gv.Group, Kind: owner.Kind}, gv.Version)
+		if err != nil {
+			break
+		}
+		ownerObj, err := client.Resource(mapping.Resource).Namespace(ref.Namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+		if err != nil {
+			break
+		}
+
+		top = &copier.ResourceRef{
+			GVR:        mapping.Resource,
+			Kind:       owner.Kind,
+			Name:       owner.Name,
+			Namespace:  ref.Namespace,
+			Namespaced: true,
+		}
+		obj = ownerObj
+	}
+
+	return top, nil
+}
+
+// controllerOf returns the controlling ownerReference, or the first one if
+// none is marked as controller.
+func controllerOf(obj *unstructured.Unstructured) *metav1.OwnerReference {
+	owners := obj.GetOwnerReferences()
+	if len(owners) == 0 {
+		return nil
+	}
+	for i := range owners {
+		if owners[i].Controller != nil && *owners[i].Controller {
+			return &owners[i]
+		}
+	}
+	return &owners[0]
+}
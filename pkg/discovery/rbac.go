@@ -0,0 +1,78 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/a13x22/kube-copy/pkg/copier"
+)
+
+var (
+	roleBindingGVR = schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "rolebindings"}
+	roleGVR        = schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "roles"}
+)
+
+// findRoleBindingsForServiceAccount finds RoleBindings whose subjects include
+// the given ServiceAccount. The namespaced Roles they reference are discovered
+// as forward references of the bindings.
+// ClusterRole references produce a warning instead: cluster-scoped RBAC is
+// shared and should not be copied implicitly.
+func findRoleBindingsForServiceAccount(ctx context.Context, lists *listCache, namespace, saName string) ([]copier.ResourceRef, []*unstructured.Unstructured, []Warning) {
+	rbList, err := lists.List(ctx, roleBindingGVR, namespace)
+	if err != nil {
+		return nil, nil, nil
+	}
+
+	var refs []copier.ResourceRef
+	var objs []*unstructured.Unstructured
+	var warnings []Warning
+
+	for i := range rbList.Items {
+		rb := &rbList.Items[i]
+		if !roleBindingHasServiceAccount(rb, namespace, saName) {
+			continue
+		}
+
+		refs = append(refs, copier.ResourceRef{
+			GVR:        roleBindingGVR,
+			Kind:       "RoleBinding",
+			Name:       rb.GetName(),
+			Namespace:  namespace,
+			Namespaced: true,
+		})
+		objs = append(objs, rb)
+
+		// Namespaced Roles are followed as a forward reference of the RoleBinding
+		roleKind, _, _ := unstructured.NestedString(rb.Object, "roleRef", "kind")
+		roleName, _, _ := unstructured.NestedString(rb.Object, "roleRef", "name")
+		if roleKind == "ClusterRole" {
+			warnings = append(warnings, Warning{
+				Resource: fmt.Sprintf("RoleBinding/%s", rb.GetName()),
+				Message:  fmt.Sprintf("references ClusterRole %q which is not copied -- make sure it exists in the target cluster", roleName),
+			})
+		}
+	}
+
+	return refs, objs, warnings
+}
+
+// roleBindingHasServiceAccount reports whether a RoleBinding grants to the named ServiceAccount.
+func roleBindingHasServiceAccount(rb *unstructured.Unstructured, namespace, saName string) bool {
+	subjects, _, _ := unstructured.NestedSlice(rb.Object, "subjects")
+	for _, s := range subjects {
+		subject, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		kind, _ := subject["kind"].(string)
+		name, _ := subject["name"].(string)
+		ns, _ := subject["namespace"].(string)
+		if kind == "ServiceAccount" && name == saName && (ns == "" || ns == namespace) {
+			return true
+		}
+	}
+	return false
+}
@@ -8,56 +8,75 @@ import (
 )
 
 // extractForwardRefs finds all resources that the given object depends on:
-// ConfigMaps, Secrets, PVCs, and ServiceAccounts referenced in the pod spec.
-func extractForwardRefs(obj *unstructured.Unstructured, namespace string) []copier.ResourceRef {
-	var refs []copier.ResourceRef
-
-	podSpec := extractPodSpec(obj)
-	if podSpec == nil {
-		return nil
+// ConfigMaps, Secrets, PVCs, and ServiceAccounts referenced in the pod spec,
+// the TLS Secrets of an Ingress, the Role of a RoleBinding, and the governing
+// Service of a StatefulSet.
+func extractForwardRefs(obj *unstructured.Unstructured, namespace string) []link {
+	var links []link
+
+	add := func(gvr schema.GroupVersionResource, kind, relation string, names ...string) {
+		for _, name := range names {
+			links = append(links, link{
+				ref: copier.ResourceRef{
+					GVR:        gvr,
+					Kind:       kind,
+					Name:       name,
+					Namespace:  namespace,
+					Namespaced: true,
+				},
+				relation: relation,
+			})
+		}
 	}
 
-	// ConfigMaps
-	for _, name := range extractConfigMapNames(podSpec) {
-		refs = append(refs, copier.ResourceRef{
-			GVR:        schema.GroupVersionResource{Version: "v1", Resource: "configmaps"},
-			Name:       name,
-			Namespace:  namespace,
-			Namespaced: true,
-		})
+	switch obj.GetKind() {
+	case "Ingress":
+		// Ingress TLS secrets. These are kubernetes.io/tls Secrets the Ingress
+		// cannot serve without, so they must never be filtered out.
+		add(schema.GroupVersionResource{Version: "v1", Resource: "secrets"}, "Secret", "ingress TLS", extractIngressTLSSecretNames(obj)...)
+		return links
+	case "RoleBinding":
+		// ClusterRole references are reported as warnings by the reverse lookup
+		roleKind, _, _ := unstructured.NestedString(obj.Object, "roleRef", "kind")
+		roleName, _, _ := unstructured.NestedString(obj.Object, "roleRef", "name")
+		if roleKind == "Role" && roleName != "" {
+			add(roleGVR, "Role", "roleRef", roleName)
+		}
+		return links
 	}
 
-	// Secrets
-	for _, name := range extractSecretNames(podSpec) {
-		refs = append(refs, copier.ResourceRef{
-			GVR:        schema.GroupVersionResource{Version: "v1", Resource: "secrets"},
-			Name:       name,
-			Namespace:  namespace,
-			Namespaced: true,
-		})
+	// A StatefulSet's governing headless Service is named explicitly and may
+	// not match the pod labels, so selector-based discovery can miss it.
+	// Stable pod DNS names break without it.
+	if obj.GetKind() == "StatefulSet" {
+		if svc, _, _ := unstructured.NestedString(obj.Object, "spec", "serviceName"); svc != "" {
+			links = append(links, link{
+				ref: copier.ResourceRef{
+					GVR:        schema.GroupVersionResource{Version: "v1", Resource: "services"},
+					Kind:       "Service",
+					Name:       svc,
+					Namespace:  namespace,
+					Namespaced: true,
+				},
+				relation: "governing service",
+				required: true,
+			})
+		}
 	}
 
-	// PVCs
-	for _, name := range extractPVCNames(podSpec) {
-		refs = append(refs, copier.ResourceRef{
-			GVR:        schema.GroupVersionResource{Version: "v1", Resource: "persistentvolumeclaims"},
-			Name:       name,
-			Namespace:  namespace,
-			Namespaced: true,
-		})
+	podSpec := extractPodSpec(obj)
+	if podSpec == nil {
+		return links
 	}
 
-	// ServiceAccount
+	add(schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}, "ConfigMap", "configMap reference", extractConfigMapNames(podSpec)...)
+	add(schema.GroupVersionResource{Version: "v1", Resource: "secrets"}, "Secret", "secret reference", extractSecretNames(podSpec)...)
+	add(schema.GroupVersionResource{Version: "v1", Resource: "persistentvolumeclaims"}, "PersistentVolumeClaim", "volume claim", extractPVCNames(podSpec)...)
 	if sa := extractServiceAccountName(podSpec); sa != "" && sa != "default" {
-		refs = append(refs, copier.ResourceRef{
-			GVR:        schema.GroupVersionResource{Version: "v1", Resource: "serviceaccounts"},
-			Name:       sa,
-			Namespace:  namespace,
-			Namespaced: true,
-		})
+		add(schema.GroupVersionResource{Version: "v1", Resource: "serviceaccounts"}, "ServiceAccount", "service account", sa)
 	}
 
-	return refs
+	return links
 }
 
 // extractPodSpec navigates to the pod spec within various resource types.
@@ -80,7 +99,7 @@ func extractPodSpec(obj *unstructured.Unstructured) map[string]interface{} {
 		if jobTemplate == nil {
 			return nil
 		}
-		return getPodSpecFromTemplateMap(jobTemplate)
+		return getPodSpecFromTemplate(jobTemplate)
 	}
 	return nil
 }
@@ -197,10 +216,32 @@ func extractSecretNames(podSpec map[string]interface{}) []string {
 					names = append(names, name)
 				}
 			}
+			// CSI volumes (e.g. secrets-store) authenticate with a node publish secret
+			if csi, ok := vol["csi"].(map[string]interface{}); ok {
+				if ref, ok := csi["nodePublishSecretRef"].(map[string]interface{}); ok {
+					if name, ok := ref["name"].(string); ok && !seen[name] {
+						seen[name] = true
+						names = append(names, name)
+					}
+				}
+			}
 			extractFromProjected(vol, "secret", "name", seen, &names)
 		}
 	}
 
+	if pullSecrets, ok := podSpec["imagePullSecrets"].([]interface{}); ok {
+		for _, ps := range pullSecrets {
+			entry, _ := ps.(map[string]interface{})
+			if entry == nil {
+				continue
+			}
+			if name, ok := entry["name"].(string); ok && !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+
 	extractFromContainerEnv(podSpec, "secretRef", "name", "secretKeyRef", "name", seen, &names)
 
 	return names
@@ -238,6 +279,25 @@ func extractServiceAccountName(podSpec map[string]interface{}) string {
 	return ""
 }
 
+func extractIngressTLSSecretNames(ing *unstructured.Unstructured) []string {
+	seen := map[string]bool{}
+	var names []string
+
+	tls, _, _ := unstructured.NestedSlice(ing.Object, "spec", "tls")
+	for _, t := range tls {
+		entry, _ := t.(map[string]interface{})
+		if entry == nil {
+			continue
+		}
+		if name, ok := entry["secretName"].(string); ok && name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
 // ---- Helpers ----
 
 func extractFromProjected(vol map[string]interface{}, sourceKey, nameKey string, seen map[string]bool, names *[]string) {
@@ -0,0 +1,182 @@
+package discovery
+
+import (
+	"slices"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// parse decodes a YAML manifest for a test.
+func parse(t *testing.T, manifest string) *unstructured.Unstructured {
+	t.Helper()
+	obj := &unstructured.Unstructured{}
+	if err := yaml.Unmarshal([]byte(manifest), &obj.Object); err != nil {
+		t.Fatalf("parse manifest: %v", err)
+	}
+	return obj
+}
+
+const referencingDeployment = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      serviceAccountName: web
+      imagePullSecrets:
+      - name: registry
+      volumes:
+      - name: config
+        configMap:
+          name: web-config
+      - name: tls
+        secret:
+          secretName: web-tls
+      - name: data
+        persistentVolumeClaim:
+          claimName: web-data
+      - name: bundle
+        projected:
+          sources:
+          - configMap:
+              name: ca-bundle
+          - secret:
+              name: projected-token
+          - serviceAccountToken:
+              path: token
+      - name: vault
+        csi:
+          driver: secrets-store.csi.k8s.io
+          nodePublishSecretRef:
+            name: vault-creds
+      - name: scratch
+        emptyDir: {}
+      initContainers:
+      - name: migrate
+        envFrom:
+        - secretRef:
+            name: db-credentials
+      containers:
+      - name: web
+        envFrom:
+        - configMapRef:
+            name: web-env
+        env:
+        - name: API_KEY
+          valueFrom:
+            secretKeyRef:
+              name: api-key
+              key: key
+        - name: MODE
+          valueFrom:
+            configMapKeyRef:
+              name: web-config
+              key: mode
+`
+
+func TestPodSpecExtractors(t *testing.T) {
+	podSpec := extractPodSpec(parse(t, referencingDeployment))
+	if podSpec == nil {
+		t.Fatal("no pod spec found in the Deployment")
+	}
+
+	tests := []struct {
+		name    string
+		extract func(map[string]interface{}) []string
+		want    []string
+	}{
+		{"configMaps", extractConfigMapNames, []string{"web-config", "ca-bundle", "web-env"}},
+		{"secrets", extractSecretNames, []string{"web-tls", "projected-token", "vault-creds", "registry", "api-key", "db-credentials"}},
+		{"claims", extractPVCNames, []string{"web-data"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.extract(podSpec); !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if got := extractServiceAccountName(podSpec); got != "web" {
+		t.Errorf("service account %q, want web", got)
+	}
+}
+
+func TestExtractPodSpec(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		want     bool
+	}{
+		{"pod", "kind: Pod\nspec:\n  containers: []\n", true},
+		{"deployment", "kind: Deployment\nspec:\n  template:\n    spec:\n      containers: []\n", true},
+		{"cronjob", "kind: CronJob\nspec:\n  jobTemplate:\n    spec:\n      template:\n        spec:\n          containers: []\n", true},
+		{"cronjob without a job template", "kind: CronJob\nspec: {}\n", false},
+		{"service", "kind: Service\nspec:\n  selector: {}\n", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractPodSpec(parse(t, tt.manifest)) != nil; got != tt.want {
+				t.Errorf("found a pod spec: %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractForwardRefs(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		want     []string // Kind/name of each link
+	}{
+		{
+			name:     "deployment",
+			manifest: referencingDeployment,
+			want: []string{
+				"ConfigMap/web-config", "ConfigMap/ca-bundle", "ConfigMap/web-env",
+				"Secret/web-tls", "Secret/projected-token", "Secret/vault-creds", "Secret/registry", "Secret/api-key", "Secret/db-credentials",
+				"PersistentVolumeClaim/web-data", "ServiceAccount/web",
+			},
+		},
+		{
+			name:     "default service account",
+			manifest: "kind: Pod\nspec:\n  serviceAccountName: default\n",
+		},
+		{
+			name:     "statefulset",
+			manifest: "kind: StatefulSet\nspec:\n  serviceName: db-headless\n  template:\n    spec:\n      serviceAccount: db\n",
+			want:     []string{"Service/db-headless", "ServiceAccount/db"},
+		},
+		{
+			name:     "ingress",
+			manifest: "kind: Ingress\nspec:\n  tls:\n  - secretName: a-tls\n  - secretName: a-tls\n  - hosts: [b.example.com]\n",
+			want:     []string{"Secret/a-tls"},
+		},
+		{
+			name:     "rolebinding to a role",
+			manifest: "kind: RoleBinding\nroleRef:\n  kind: Role\n  name: reader\n",
+			want:     []string{"Role/reader"},
+		},
+		{
+			name:     "rolebinding to a clusterrole",
+			manifest: "kind: RoleBinding\nroleRef:\n  kind: ClusterRole\n  name: view\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, l := range extractForwardRefs(parse(t, tt.manifest), "prod") {
+				if l.ref.Namespace != "prod" {
+					t.Errorf("%s/%s in namespace %q, want prod", l.ref.Kind, l.ref.Name, l.ref.Namespace)
+				}
+				got = append(got, l.ref.Kind+"/"+l.ref.Name)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/a13x22/kube-copy/pkg/copier"
+	"github.com/a13x22/kube-copy/pkg/sanitizer"
+)
+
+// InstanceLabel names the release of an object by chart convention, set by
+// most charts; Helm itself sets sanitizer.HelmManagedByLabel and the release
+// annotations on every object it installs.
+const InstanceLabel = "app.kubernetes.io/instance"
+
+// Relations of the resources of a release graph to its root.
+const (
+	RelationReleaseMember  = "Helm release member"
+	RelationReleaseHistory = "Helm release history"
+)
+
+// DiscoverRelease finds the resources of a Helm release in a namespace and
+// returns them as a graph rooted at the release record of its latest
+// revision: the objects of types annotated as installed by the release or
+// labeled with its InstanceLabel, and the records of earlier revisions, so
+// helm in the target recognizes the release and its history. Objects only
+// labeled, without Helm's annotations, are included with a warning. Objects
+// managed by a controller (the Pods of a Deployment) are left to it, as are
+// Endpoints. Cluster-scoped objects of the release are not found.
+func DiscoverRelease(ctx context.Context, client dynamic.Interface, types []copier.ResourceRef, namespace, release string, opts Options) (*Graph, error) {
+	records, err := releaseRecords(ctx, client, namespace, release)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no Helm release %q in namespace %q: found no sh.helm.release.v1.%s.v* Secrets", release, namespace, release)
+	}
+	graph := &Graph{Root: records[len(records)-1]}
+
+	add := func(ref copier.ResourceRef, obj *unstructured.Unstructured, relation string) {
+		// From the root, which records the release's objects, to them
+		edge := Edge{From: graph.Root, To: ref, Relation: relation, Forward: true}
+		if edge.ExcludedBy = excludedBy(ref, obj, opts); edge.ExcludedBy != "" {
+			graph.Excluded = append(graph.Excluded, edge)
+			return
+		}
+		graph.Edges = append(graph.Edges, edge)
+	}
+	for _, r := range records[:len(records)-1] {
+		add(r, nil, RelationReleaseHistory)
+	}
+
+	type key struct{ kind, name string }
+	seen := map[key]bool{}
+	for _, t := range types {
+		for _, selector := range []string{sanitizer.HelmManagedByLabel + "=Helm", InstanceLabel + "=" + release} {
+			list, err := client.Resource(t.GVR).Namespace(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+			if err != nil {
+				if !apierrors.IsNotFound(err) && !apierrors.IsMethodNotSupported(err) {
+					graph.Errors = append(graph.Errors, Warning{
+						Resource: t.GVR.GroupResource().String(),
+						Message:  fmt.Sprintf("listing %s in namespace %q failed: %s -- resources of the release of this type may be missing", t.GVR.GroupResource(), namespace, lookupReason(err)),
+					})
+				}
+				break
+			}
+			for i := range list.Items {
+				obj := &list.Items[i]
+				// The same object may be served by more than one group
+				k := key{t.Kind, obj.GetName()}
+				if seen[k] || !releaseMember(obj, namespace, release) || managedByController(t.Kind, obj) {
+					continue
+				}
+				seen[k] = true
+				ref := copier.ResourceRef{GVR: t.GVR, Kind: t.Kind, Name: obj.GetName(), Namespace: namespace, Namespaced: true}
+				if obj.GetAnnotations()[sanitizer.HelmReleaseNameAnnotation] == "" {
+					graph.Warnings = append(graph.Warnings, Warning{
+						Resource: ref.DisplayName(),
+						Message:  fmt.Sprintf("labeled %s=%s but not annotated as installed by Helm; copying it with the release", InstanceLabel, release),
+					})
+				}
+				add(ref, obj, RelationReleaseMember)
+			}
+		}
+	}
+	return graph, nil
+}
+
+// releaseRecords lists the Secrets Helm stores the revisions of a release
+// in, oldest first.
+func releaseRecords(ctx context.Context, client dynamic.Interface, namespace, release string) ([]copier.ResourceRef, error) {
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+	list, err := client.Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{LabelSelector: "owner=helm,name=" + release})
+	if err != nil {
+		return nil, fmt.Errorf("listing the records of Helm release %q: %w", release, err)
+	}
+	items := list.Items
+	revision := func(obj *unstructured.Unstructured) int {
+		n, _ := strconv.Atoi(obj.GetLabels()["version"])
+		return n
+	}
+	sort.SliceStable(items, func(i, j int) bool { return revision(&items[i]) < revision(&items[j]) })
+
+	refs := make([]copier.ResourceRef, len(items))
+	for i, obj := range items {
+		refs[i] = copier.ResourceRef{GVR: gvr, Kind: "Secret", Name: obj.GetName(), Namespace: namespace, Namespaced: true}
+	}
+	return refs, nil
+}
+
+// releaseMember reports whether obj belongs to the release: annotated as
+// installed by it, or labeled with its name.
+func releaseMember(obj *unstructured.Unstructured, namespace, release string) bool {
+	annotations := obj.GetAnnotations()
+	if name := annotations[sanitizer.HelmReleaseNameAnnotation]; name != "" {
+		ns := annotations[sanitizer.HelmReleaseNamespaceAnnotation]
+		return name == release && (ns == "" || ns == namespace)
+	}
+	return obj.GetLabels()[InstanceLabel] == release
+}
+
+// managedByController reports whether obj is maintained by a controller
+// rather than installed, so it must not be copied: it has a controller
+// owner (the Pods of a ReplicaSet), or it is an Endpoints object, which
+// carries the labels of its Service.
+func managedByController(kind string, obj *unstructured.Unstructured) bool {
+	if kind == "Endpoints" {
+		return true
+	}
+	return metav1.GetControllerOfNoCopy(obj) != nil
+}
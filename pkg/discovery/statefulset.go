@@ -0,0 +1,90 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/a13x22/kube-copy/pkg/copier"
+)
+
+var pvcGVR = schema.GroupVersionResource{Version: "v1", Resource: "persistentvolumeclaims"}
+
+// findStatefulSetClaims finds the PersistentVolumeClaims a StatefulSet
+// created from its volumeClaimTemplates, named
+// <template>-<statefulset>-<ordinal>. Nothing in the pod spec names them,
+// so they are only followed when Options.IncludeStatefulSetPVCs is set.
+// Claims of ordinals above the current replicas are included too: they
+// hold the data of replicas scaled down. A failed list is returned as an
+// error naming the StatefulSet, whose claims are then missing from the copy.
+func findStatefulSetClaims(ctx context.Context, lists *listCache, obj *unstructured.Unstructured, namespace string) ([]link, []Warning) {
+	if obj.GetKind() != "StatefulSet" {
+		return nil, nil
+	}
+	templates, _, _ := unstructured.NestedSlice(obj.Object, "spec", "volumeClaimTemplates")
+	if len(templates) == 0 {
+		return nil, nil
+	}
+	list, err := lists.List(ctx, pvcGVR, namespace)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		resource := fmt.Sprintf("StatefulSet/%s", obj.GetName())
+		return nil, []Warning{{
+			Resource: resource,
+			Message:  fmt.Sprintf("finding the claims of %s's volumeClaimTemplates failed: %s -- they are not copied", resource, lookupReason(err)),
+		}}
+	}
+
+	var links []link
+	for _, t := range templates {
+		template, ok := t.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(template, "metadata", "name")
+		if name == "" {
+			continue
+		}
+		prefix := name + "-" + obj.GetName() + "-"
+		for i := range list.Items {
+			pvc := &list.Items[i]
+			ordinal, ok := strings.CutPrefix(pvc.GetName(), prefix)
+			if !ok || !isOrdinal(ordinal) {
+				continue
+			}
+			links = append(links, link{
+				ref: copier.ResourceRef{
+					GVR:        pvcGVR,
+					Kind:       "PersistentVolumeClaim",
+					Name:       pvc.GetName(),
+					Namespace:  namespace,
+					Namespaced: true,
+				},
+				obj:      pvc,
+				relation: "volume claim template",
+				forward:  true,
+			})
+		}
+	}
+	return links, nil
+}
+
+// isOrdinal reports whether s is a StatefulSet pod ordinal: digits without
+// leading zeros.
+func isOrdinal(s string) bool {
+	if s == "" || len(s) > 1 && s[0] == '0' {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,76 @@
+package discovery
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+const databaseStatefulSet = `
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: db
+  namespace: prod
+spec:
+  volumeClaimTemplates:
+  - metadata:
+      name: data
+`
+
+func claimManifest(t *testing.T, name string) runtime.Object {
+	t.Helper()
+	return parse(t, `
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: `+name+`
+  namespace: prod
+`)
+}
+
+func TestFindStatefulSetClaims(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{pvcGVR: "PersistentVolumeClaimList"},
+		claimManifest(t, "data-db-0"),
+		claimManifest(t, "data-db-2"),
+		claimManifest(t, "data-db-01"), // not an ordinal
+		claimManifest(t, "data-dba-0"), // another StatefulSet's
+		claimManifest(t, "logs-db-0"))  // another template's
+
+	links, errs := findStatefulSetClaims(context.Background(), newListCache(client), parse(t, databaseStatefulSet), "prod")
+	if len(errs) != 0 {
+		t.Fatalf("errors = %v", errs)
+	}
+	var got []string
+	for _, l := range links {
+		got = append(got, l.ref.Name)
+	}
+	slices.Sort(got)
+	if want := []string{"data-db-0", "data-db-2"}; !slices.Equal(got, want) {
+		t.Errorf("claims = %v, want %v", got, want)
+	}
+}
+
+func TestFindStatefulSetClaimsReportsAFailedList(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{pvcGVR: "PersistentVolumeClaimList"})
+	client.PrependReactor("list", "persistentvolumeclaims", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(pvcGVR.GroupResource(), "", nil)
+	})
+
+	links, errs := findStatefulSetClaims(context.Background(), newListCache(client), parse(t, databaseStatefulSet), "prod")
+	if len(links) != 0 {
+		t.Errorf("links = %v, want none", links)
+	}
+	if len(errs) != 1 || errs[0].Resource != "StatefulSet/db" || !strings.Contains(errs[0].Message, "forbidden") {
+		t.Errorf("errors = %v, want the forbidden list of StatefulSet/db's claims", errs)
+	}
+}
@@ -0,0 +1,211 @@
+package kubecopy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/version"
+
+	"github.com/a13x22/kube-copy/pkg/audit"
+	"github.com/a13x22/kube-copy/pkg/bundle"
+	"github.com/a13x22/kube-copy/pkg/client"
+	"github.com/a13x22/kube-copy/pkg/copier"
+	"github.com/a13x22/kube-copy/pkg/provenance"
+	"github.com/a13x22/kube-copy/pkg/sanitizer"
+)
+
+// NewBundle packages the plan of an export for Import: a Copy with the
+// copier.WithDryRun and copier.WithoutConflictCheck options, whose results
+// all plan a create. info names the source cluster and the kubecopy version.
+// The objects keep their source namespaces; Import picks the target ones.
+func NewBundle(report *Report, info provenance.Info) (*bundle.Bundle, error) {
+	b := &bundle.Bundle{Manifest: bundle.Manifest{
+		ToolVersion:   info.Version,
+		CreatedAt:     time.Now().UTC().Truncate(time.Second),
+		SourceCluster: info.Cluster,
+	}}
+	if report.SourceVersion != nil {
+		b.Manifest.SourceVersion = "v" + report.SourceVersion.String()
+	}
+	for _, r := range report.Results {
+		switch {
+		case r.Error != nil:
+			return nil, fmt.Errorf("not exporting: %s failed: %w", r.Source.DisplayName(), r.Error)
+		case r.Action != "create" || r.Sanitized == nil:
+			return nil, fmt.Errorf("not exporting: %s was planned as %s", r.Source.DisplayName(), r.Action)
+		}
+		resource := bundle.Resource{
+			Group:     r.Source.GVR.Group,
+			Version:   r.Source.GVR.Version,
+			Resource:  r.Source.GVR.Resource,
+			Kind:      r.Source.Kind,
+			Namespace: r.TargetNS,
+			Name:      r.TargetName,
+		}
+		if !r.Source.Namespaced {
+			resource.Namespace = ""
+		}
+		for _, w := range r.Warnings {
+			resource.Warnings = append(resource.Warnings, bundle.Warning{
+				Resource: w.Resource,
+				Code:     w.Code,
+				Severity: string(w.Severity),
+				Message:  w.Message,
+			})
+		}
+		b.Manifest.Resources = append(b.Manifest.Resources, resource)
+		b.Objects = append(b.Objects, r.Sanitized)
+	}
+	if len(b.Objects) == 0 {
+		return nil, fmt.Errorf("not exporting: nothing to export")
+	}
+	return b, nil
+}
+
+// ImportRequest describes the import of a bundle.
+type ImportRequest struct {
+	Bundle *bundle.Bundle
+
+	// Clients to use; only the target ones are. When nil they are built
+	// from Connection, whose target defaults to the source settings as for
+	// a copy.
+	Clients    *client.Clients
+	Connection client.Options
+
+	// TargetNamespace receives every namespaced resource not mapped by the
+	// copier.WithNamespaceMap option. When empty, resources are imported
+	// into the namespaces they were exported from.
+	TargetNamespace string
+
+	// AuditLog, when set, records every write to the target cluster.
+	AuditLog *audit.Log
+
+	// Options configure planning and applying as for Copy. Sanitizers are
+	// replaced: the objects were sanitized at export, and the warnings
+	// recorded then are reported again instead.
+	Options []copier.Option
+
+	// Confirm is called with the report once the plan is made, as for Copy.
+	Confirm func(report *Report) bool
+}
+
+// Import plans the objects of a bundle against the target cluster, with the
+// same conflict detection as a copy, and applies them. The report's Primary
+// is the bundle's first resource.
+func Import(ctx context.Context, req ImportRequest) (*Report, error) {
+	b := req.Bundle
+	if len(b.Manifest.Resources) == 0 {
+		return nil, fmt.Errorf("the bundle is empty")
+	}
+	c, err := copier.New(b.Client(), nil, req.Options...)
+	if err != nil {
+		return nil, err
+	}
+	p := c.Progress
+	if p == nil {
+		p = noopProgress{}
+	}
+
+	p.Connecting()
+	stopConnect := c.Stats.Start("connect")
+	clients := req.Clients
+	if clients == nil {
+		if err := client.ValidateContexts(req.Connection); err != nil {
+			return nil, err
+		}
+		clients, err = client.NewTargetOnly(req.Connection)
+		if err != nil {
+			return nil, fmt.Errorf("cannot connect to cluster: %w\n    Check your kubeconfig and network connectivity.", err)
+		}
+	}
+	if c.Stats != nil && c.Stats.Requests == nil {
+		c.Stats.Requests = clients.Requests.Count
+	}
+	stopConnect()
+
+	refs := make([]copier.ResourceRef, len(b.Manifest.Resources))
+	for i, r := range b.Manifest.Resources {
+		refs[i] = copier.ResourceRef{
+			GVR:        r.GVR(),
+			Kind:       r.Kind,
+			Name:       r.Name,
+			Namespace:  r.Namespace,
+			Namespaced: r.Namespace != "",
+		}
+	}
+	report := &Report{Requested: refs[0], Primary: refs[0]}
+	if v, err := version.ParseGeneric(b.Manifest.SourceVersion); err == nil {
+		report.SourceVersion = v
+	}
+	_, report.TargetVersion = clients.ServerVersions()
+
+	c.TargetClient = clients.TargetDynamic
+	c.TargetMapper = clients.TargetMapper
+	c.TargetVersion = report.TargetVersion
+	c.KeepHelmMetadata = true // stripped at export, unless asked to keep it
+	if req.AuditLog != nil {
+		c.Audit = req.AuditLog.Recorder(audit.Cluster{}, audit.Cluster{Server: clients.TargetServer, User: clients.TargetUser})
+	}
+	if req.Confirm != nil {
+		c.Confirm = func(planned []copier.CopyResult) bool {
+			report.Results = planned
+			return req.Confirm(report)
+		}
+	}
+
+	// Without a target namespace every resource stays in its own
+	targetNS := req.TargetNamespace
+	if targetNS == "" {
+		namespaces := map[string]string{}
+		for _, ref := range refs {
+			if ref.Namespaced {
+				namespaces[ref.Namespace] = ref.Namespace
+			}
+		}
+		for from, to := range c.NamespaceMap {
+			namespaces[from] = to
+		}
+		c.NamespaceMap = namespaces
+		targetNS = refs[0].Namespace
+	}
+	c.Sanitizers = replayWarnings(b, func(ns string) string {
+		if mapped, ok := c.NamespaceMap[ns]; ok {
+			return mapped
+		}
+		return targetNS
+	})
+
+	report.Results, report.Applied = c.CopyAll(ctx, refs, targetNS, "")
+	return report, ctx.Err()
+}
+
+// replayWarnings returns a registry that leaves the already sanitized bundle
+// objects alone and reports the warnings recorded for them at export. The
+// objects are sanitized into their target namespaces, which mapNS gives for
+// the namespaces they were exported from.
+func replayWarnings(b *bundle.Bundle, mapNS func(string) string) *sanitizer.Registry {
+	warnings := map[string][]sanitizer.Warning{}
+	r := sanitizer.NewRegistry()
+	replay := sanitizer.SanitizerFunc(func(obj *unstructured.Unstructured) []sanitizer.Warning {
+		return warnings[obj.GetNamespace()+"/"+obj.GetKind()+"/"+obj.GetName()]
+	})
+	for i, res := range b.Manifest.Resources {
+		namespace := res.Namespace
+		if namespace != "" {
+			namespace = mapNS(namespace)
+		}
+		key := namespace + "/" + res.Kind + "/" + res.Name
+		for _, w := range res.Warnings {
+			warnings[key] = append(warnings[key], sanitizer.Warning{
+				Resource: w.Resource,
+				Code:     w.Code,
+				Severity: sanitizer.Severity(w.Severity),
+				Message:  w.Message,
+			})
+		}
+		r.Register(b.Objects[i].GroupVersionKind(), replay)
+	}
+	return r
+}
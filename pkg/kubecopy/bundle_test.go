@@ -0,0 +1,51 @@
+package kubecopy
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/a13x22/kube-copy/pkg/bundle"
+)
+
+func configMap(namespace, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("ConfigMap")
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	return obj
+}
+
+func TestReplayWarningsPerNamespace(t *testing.T) {
+	b := &bundle.Bundle{
+		Manifest: bundle.Manifest{Resources: []bundle.Resource{
+			{Version: "v1", Resource: "configmaps", Kind: "ConfigMap", Namespace: "prod", Name: "web",
+				Warnings: []bundle.Warning{{Resource: "ConfigMap/web", Code: "KC-TEST-001", Severity: "warning", Message: "from prod"}}},
+			{Version: "v1", Resource: "configmaps", Kind: "ConfigMap", Namespace: "qa", Name: "web",
+				Warnings: []bundle.Warning{{Resource: "ConfigMap/web", Code: "KC-TEST-002", Severity: "warning", Message: "from qa"}}},
+		}},
+		Objects: []*unstructured.Unstructured{configMap("prod", "web"), configMap("qa", "web")},
+	}
+	// qa is imported into staging, prod into its own namespace
+	mapNS := func(ns string) string {
+		if ns == "qa" {
+			return "staging"
+		}
+		return ns
+	}
+	registry := replayWarnings(b, mapNS)
+
+	for _, tt := range []struct {
+		from, to string
+		want     string
+	}{
+		{"prod", "prod", "from prod"},
+		{"qa", "staging", "from qa"},
+	} {
+		warnings := registry.Run(configMap(tt.from, "web"), tt.to, "")
+		if len(warnings) != 1 || warnings[0].Message != tt.want {
+			t.Errorf("ConfigMap/web from %s: warnings %v, want only %q", tt.from, warnings, tt.want)
+		}
+	}
+}
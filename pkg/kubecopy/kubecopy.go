@@ -0,0 +1,376 @@
+// Package kubecopy is the library API of kubectl-copy. Copy resolves a
+// resource, discovers its dependencies, plans the copy, and applies it, and
+// returns what happened without printing anything, so other programs can
+// embed it. The kubectl-copy command is a consumer of this package.
+package kubecopy
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/version"
+
+	"github.com/a13x22/kube-copy/pkg/audit"
+	"github.com/a13x22/kube-copy/pkg/client"
+	"github.com/a13x22/kube-copy/pkg/copier"
+	"github.com/a13x22/kube-copy/pkg/discovery"
+	"github.com/a13x22/kube-copy/pkg/transfer"
+)
+
+// CopyRequest describes a copy.
+type CopyRequest struct {
+	// Clients to use. When nil they are built from Connection.
+	Clients    *client.Clients
+	Connection client.Options
+
+	// SourceOnly builds only source clients from Connection. It is for runs
+	// that never contact the target: the copier.WithDryRun and
+	// copier.WithoutConflictCheck options must be given.
+	SourceOnly bool
+
+	// Resource is the resource type as a user would type it ("deployment",
+	// "deploy", "deployments.apps"); Name and Namespace identify the object.
+	// Namespace is ignored for cluster-scoped resources.
+	Resource  string
+	Name      string
+	Namespace string
+
+	// Release, when set, copies the Helm release of this name in Namespace
+	// instead of Resource and Name (see discovery.DiscoverRelease). The
+	// release record of its latest revision is the primary resource, and
+	// the Helm metadata of its objects is kept.
+	Release string
+
+	// TargetNamespace defaults to Namespace; TargetName, which only applies
+	// to the primary resource, to its name.
+	TargetNamespace string
+	TargetName      string
+
+	// Recursive also copies the resources the primary one depends on.
+	Recursive       bool
+	IncludeGateways bool // follow HTTPRoutes to their Gateways
+
+	// IncludeStatefulSetPVCs also copies the claims StatefulSets created
+	// from their volumeClaimTemplates in a recursive copy. It cannot be
+	// combined with TargetName: a renamed StatefulSet would not find them.
+	IncludeStatefulSetPVCs bool
+
+	// SkipSecrets leaves Secrets out of a recursive copy; references to them
+	// are noted rather than warned about. A Secret named as the resource to
+	// copy is still copied. It cannot be combined with Release.
+	SkipSecrets bool
+
+	// FollowOwner copies the top-level controller of a managed resource
+	// (the Deployment of a Pod) instead of the resource itself.
+	FollowOwner bool
+
+	// DiscoverOnly stops after discovery, for callers that only want the
+	// dependency graph. It requires Recursive.
+	DiscoverOnly bool
+
+	// WithData copies the contents of every PersistentVolumeClaim the copy
+	// creates; ForceData also copies from ReadWriteOnce claims in use.
+	WithData  bool
+	ForceData bool
+
+	// Move, when set, deletes the sources once the copy was applied and
+	// every resource is in the target (see copier.Copier.MoveSources).
+	Move *copier.MoveOptions
+
+	// Prune also deletes the copies from the same source cluster and
+	// namespace that earlier runs left in the target namespace and that
+	// this copy no longer includes (see copier.Copier.PruneTypes). It needs
+	// the copier.WithProvenance option and cannot be combined with Move or
+	// SourceOnly.
+	Prune bool
+
+	// AuditLog, when set, records every write to either cluster.
+	AuditLog *audit.Log
+
+	// Options configure planning and applying (see copier.New). The
+	// clients, mapper, target version, dependencies, exclusions, and data
+	// mover are filled in by Copy.
+	Options []copier.Option
+
+	// Confirm, when set, is called with the report once the plan is made
+	// and before anything is applied. Returning false stops the copy.
+	Confirm func(report *Report) bool
+}
+
+// Report is the outcome of a copy.
+type Report struct {
+	// Requested is the resource asked for and Primary the one copied, which
+	// is its controller when FollowOwner applied. ManagedBy is the top-level
+	// controller of the requested resource, when it has one.
+	Requested copier.ResourceRef
+	Primary   copier.ResourceRef
+	ManagedBy *copier.ResourceRef
+
+	// Graph is the discovered dependency graph of a recursive copy.
+	Graph *discovery.Graph
+
+	// Kubernetes versions of the clusters; nil when unknown.
+	SourceVersion *version.Version
+	TargetVersion *version.Version
+
+	// SameCluster is true when the source and target API servers are the
+	// same, whatever contexts named them.
+	SameCluster bool
+
+	// Results hold one entry per resource in apply order. Applied tells
+	// whether they were applied: unapplied results hold plan actions
+	// ("create"), applied ones final actions ("created").
+	Results []copier.CopyResult
+	Applied bool
+}
+
+// Copy runs a copy as described by req. Errors are returned for problems
+// that stop the copy as a whole, such as an unknown resource type;
+// per-resource failures are in the report's results. When ctx is canceled,
+// the partial report is returned along with ctx's error.
+func Copy(ctx context.Context, req CopyRequest) (*Report, error) {
+	c, err := copier.New(nil, nil, req.Options...)
+	if err != nil {
+		return nil, err
+	}
+	if req.Prune {
+		switch {
+		case c.Provenance == nil:
+			return nil, fmt.Errorf("pruning needs provenance to find earlier copies")
+		case req.Move != nil, req.SourceOnly:
+			return nil, fmt.Errorf("pruning cannot be combined with a move or a source-only copy")
+		}
+	}
+	if req.IncludeStatefulSetPVCs && req.TargetName != "" {
+		return nil, fmt.Errorf("the claims of a StatefulSet are named after it, so they cannot be included when it is renamed")
+	}
+	p := c.Progress
+	if p == nil {
+		p = noopProgress{}
+	}
+
+	p.Connecting()
+	stopConnect := c.Stats.Start("connect")
+	clients := req.Clients
+	if clients == nil {
+		if err := client.ValidateContexts(req.Connection); err != nil {
+			return nil, err
+		}
+		if req.SourceOnly {
+			clients, err = client.NewSourceOnly(req.Connection)
+		} else {
+			clients, err = client.New(req.Connection)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot connect to cluster: %w\n    Check your kubeconfig and network connectivity.", err)
+		}
+	}
+	if c.Stats != nil && c.Stats.Requests == nil {
+		c.Stats.Requests = clients.Requests.Count
+	}
+	stopConnect()
+	if !req.SourceOnly && !req.DiscoverOnly {
+		if err := checkSameObject(clients, req); err != nil {
+			return nil, err
+		}
+		if c.CopyVolumes && clients.SameCluster {
+			return nil, fmt.Errorf("--with-pv cannot copy within one cluster: PersistentVolumes are cluster-scoped and keep their names, so each would be its own target while still bound to the source claim\n    Copy the claims without --with-pv, or use --with-data to copy their contents.")
+		}
+	}
+	stopDiscovery := c.Stats.Start("discovery")
+
+	var report *Report
+	var refs []copier.ResourceRef
+	if req.Release != "" {
+		report, refs, err = discoverRelease(ctx, clients, req, c, p)
+	} else {
+		report, refs, err = discoverResource(ctx, clients, req, c, p)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if req.DiscoverOnly {
+		stopDiscovery()
+		return report, nil
+	}
+
+	report.SameCluster = clients.SameCluster
+
+	// Cluster versions, used for deprecated API checks
+	report.SourceVersion, report.TargetVersion = clients.ServerVersions()
+	stopDiscovery()
+
+	c.SourceClient = clients.SourceDynamic
+	c.TargetClient = clients.TargetDynamic
+	c.TargetMapper = clients.TargetMapper
+	c.TargetVersion = report.TargetVersion
+	if req.AuditLog != nil {
+		c.Audit = req.AuditLog.Recorder(
+			audit.Cluster{Server: clients.SourceServer, User: clients.SourceUser},
+			audit.Cluster{Server: clients.TargetServer, User: clients.TargetUser})
+	}
+	if req.WithData {
+		c.DataMover = &transfer.Mover{
+			Source:       clients.SourceTyped,
+			Target:       clients.TargetTyped,
+			SourceConfig: clients.SourceConfig,
+			TargetConfig: clients.TargetConfig,
+			SameCluster:  clients.SameCluster,
+			Force:        req.ForceData,
+		}
+		if c.Audit != nil {
+			audit := c.Audit
+			c.DataMover.Audit = func(source bool, verb, namespace, name string, err error) {
+				audit(copier.AuditEvent{
+					Source: source, Verb: verb, Action: "transfer",
+					GVR:       schema.GroupVersionResource{Version: "v1", Resource: "pods"},
+					Namespace: namespace, Name: name, Err: err,
+				})
+			}
+		}
+	}
+	if req.Prune {
+		resources, err := clients.TargetNamespacedResources()
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range resources {
+			c.PruneTypes = append(c.PruneTypes, copier.ResourceRef{GVR: r.GVR, Kind: r.Kind, Namespaced: true})
+		}
+	}
+	if req.Confirm != nil {
+		c.Confirm = func(planned []copier.CopyResult) bool {
+			report.Results = planned
+			return req.Confirm(report)
+		}
+	}
+
+	// Target namespace is empty for cluster-scoped resources
+	targetNS := req.TargetNamespace
+	if targetNS == "" {
+		targetNS = req.Namespace
+	}
+	if !report.Primary.Namespaced {
+		targetNS = ""
+	}
+
+	report.Results, report.Applied = c.CopyAll(ctx, refs, targetNS, req.TargetName)
+	if report.Applied && req.Move != nil && ctx.Err() == nil {
+		stop := c.Stats.Start("move")
+		move := *req.Move
+		move.SameCluster = clients.SameCluster
+		err := c.MoveSources(ctx, report.Results, move)
+		stop()
+		if err != nil {
+			return report, err
+		}
+	}
+	return report, ctx.Err()
+}
+
+// checkSameObject rejects a copy whose target is its source: the same API
+// server, namespace, and name. Options.Complete only catches this when no
+// target context is given; a target context can alias the source cluster.
+func checkSameObject(clients *client.Clients, req CopyRequest) error {
+	if !clients.SameCluster {
+		return nil
+	}
+	targetNS := req.TargetNamespace
+	if targetNS == "" {
+		targetNS = req.Namespace
+	}
+	if targetNS != req.Namespace || (req.TargetName != "" && req.TargetName != req.Name) {
+		return nil
+	}
+	what := fmt.Sprintf("%s/%s", req.Resource, req.Name)
+	if req.Release != "" {
+		what = "release " + req.Release
+	}
+	return fmt.Errorf("source and target are the same object: %s in namespace %q on %s\n    The target context points at the source cluster; pass --to-namespace or --to-name to copy it elsewhere.", what, req.Namespace, clients.SourceServer)
+}
+
+// discoverResource resolves the resource of a copy and, when it is
+// recursive, discovers its dependencies, returning the refs to copy with
+// the primary one first.
+func discoverResource(ctx context.Context, clients *client.Clients, req CopyRequest, c *copier.Copier, p copier.Progress) (*Report, []copier.ResourceRef, error) {
+	// Resolve resource type dynamically via the API server's discovery
+	// This handles short names, plural, singular, CRDs, resource.group format, etc.
+	resolved, err := clients.Resolve(req.Resource)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	report := &Report{Primary: copier.ResourceRef{
+		GVR:        resolved.GVR,
+		Kind:       resolved.Kind,
+		Name:       req.Name,
+		Namespace:  req.Namespace,
+		Namespaced: resolved.Namespaced,
+	}}
+	if !resolved.Namespaced {
+		report.Primary.Namespace = ""
+	}
+	report.Requested = report.Primary
+
+	// Cluster-scoped in same cluster requires a new name to avoid overwriting
+	if !report.Primary.Namespaced && (req.TargetName == "" || req.TargetName == req.Name) && clients.SameCluster {
+		return nil, nil, fmt.Errorf("copying a cluster-scoped resource (e.g. StorageClass) in the same cluster requires a new name (--to-name)")
+	}
+
+	// Managed resources (a Pod owned by a ReplicaSet owned by a Deployment)
+	// drift from their controller when copied alone
+	if report.Primary.Namespaced {
+		owner, err := discovery.FindTopLevelOwner(ctx, clients.SourceDynamic, clients.SourceMapper, report.Primary)
+		if err == nil && owner != nil {
+			report.ManagedBy = owner
+			if req.FollowOwner {
+				report.Primary = *owner
+			}
+		}
+	}
+
+	// Build list of resources to copy
+	refs := []copier.ResourceRef{report.Primary}
+	if req.Recursive {
+		if d, ok := p.(interface{ Discovering() }); ok {
+			d.Discovering()
+		}
+		graph, err := discovery.Discover(ctx, clients.SourceDynamic, report.Primary.GVR, report.Primary.Name, report.Primary.Namespace, discovery.Options{
+			IncludeGateways:        req.IncludeGateways,
+			IncludeStatefulSetPVCs: req.IncludeStatefulSetPVCs,
+			Mapper:                 clients.SourceMapper,
+			SkipSecrets:            req.SkipSecrets,
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("discovering dependencies: %w", err)
+		}
+		report.Graph = graph
+		discovered := graph.Refs()
+		refs = append(refs, discovered...)
+		useGraph(c, graph, req)
+		p.Discovered(len(discovered))
+	}
+	return report, refs, nil
+}
+
+// useGraph passes what discovery found out about the copied resources to
+// the copier.
+func useGraph(c *copier.Copier, graph *discovery.Graph, req CopyRequest) {
+	c.Dependencies = graph.Dependencies()
+	for _, e := range graph.Excluded {
+		c.Excluded = append(c.Excluded, e.To)
+	}
+	c.SkipSecrets = req.SkipSecrets
+}
+
+// noopProgress is used when the request sets no Progress.
+type noopProgress struct{}
+
+func (noopProgress) Connecting()                {}
+func (noopProgress) Fetching(string, string)    {}
+func (noopProgress) Sanitizing(string)          {}
+func (noopProgress) Checking(string)            {}
+func (noopProgress) Creating(string, string)    {}
+func (noopProgress) Discovered(int)             {}
+func (noopProgress) Transferring(string, int64) {}
@@ -0,0 +1,45 @@
+package kubecopy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/a13x22/kube-copy/pkg/client"
+	"github.com/a13x22/kube-copy/pkg/copier"
+	"github.com/a13x22/kube-copy/pkg/discovery"
+)
+
+// discoverRelease finds the resources of the Helm release of a copy,
+// returning the refs to copy with its latest release record first.
+func discoverRelease(ctx context.Context, clients *client.Clients, req CopyRequest, c *copier.Copier, p copier.Progress) (*Report, []copier.ResourceRef, error) {
+	if req.TargetName != "" || req.Recursive || req.FollowOwner {
+		return nil, nil, fmt.Errorf("a Helm release is copied under its own names, without following references: TargetName, Recursive, and FollowOwner cannot be set")
+	}
+	if req.SkipSecrets {
+		return nil, nil, fmt.Errorf("a Helm release is copied whole, so SkipSecrets cannot be set: helm in the target would manage a release whose Secrets are missing")
+	}
+	if d, ok := p.(interface{ Discovering() }); ok {
+		d.Discovering()
+	}
+	resources, err := clients.NamespacedResources()
+	if err != nil {
+		return nil, nil, err
+	}
+	types := make([]copier.ResourceRef, len(resources))
+	for i, r := range resources {
+		types[i] = copier.ResourceRef{GVR: r.GVR, Kind: r.Kind, Namespaced: true}
+	}
+	graph, err := discovery.DiscoverRelease(ctx, clients.SourceDynamic, types, req.Namespace, req.Release, discovery.Options{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	report := &Report{Requested: graph.Root, Primary: graph.Root, Graph: graph}
+	discovered := graph.Refs()
+	refs := append([]copier.ResourceRef{graph.Root}, discovered...)
+	useGraph(c, graph, req)
+	// The copy is the release, so helm in the target must recognize its objects
+	c.KeepHelmMetadata = true
+	p.Discovered(len(discovered))
+	return report, refs, nil
+}
@@ -0,0 +1,42 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// PrintChoices numbers items from 1 for a prompt to pick from.
+func PrintChoices(w io.Writer, items []string) {
+	width := len(strconv.Itoa(len(items)))
+	for i, item := range items {
+		fmt.Fprintf(w, "    %*d) %s\n", width, i+1, item)
+	}
+}
+
+// ParseChoices parses the answer to a PrintChoices prompt: a number, or
+// comma-separated numbers, from 1 to n. It returns the indices of the chosen
+// items in the order given, each once.
+func ParseChoices(answer string, n int) ([]int, error) {
+	var chosen []int
+	seen := map[int]bool{}
+	for _, field := range strings.Split(answer, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		i, err := strconv.Atoi(field)
+		if err != nil || i < 1 || i > n {
+			return nil, fmt.Errorf("%q is not a number from 1 to %d", field, n)
+		}
+		if !seen[i] {
+			seen[i] = true
+			chosen = append(chosen, i-1)
+		}
+	}
+	if len(chosen) == 0 {
+		return nil, fmt.Errorf("nothing chosen")
+	}
+	return chosen, nil
+}
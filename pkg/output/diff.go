@@ -0,0 +1,40 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/a13x22/kube-copy/pkg/copier"
+)
+
+// maxDiffValue is the longest value PrintDiff shows in full.
+const maxDiffValue = 60
+
+// PrintDiff shows how a copy differs from the live target object, one field
+// per line: "+" for fields only in the copy, "-" for fields only in the
+// target, "~" for changed values.
+func PrintDiff(w io.Writer, diffs []copier.FieldDiff) {
+	for _, d := range diffs {
+		switch d.Op {
+		case "added":
+			fmt.Fprintf(w, "    %s+ %s: %s%s\n", colorGreen, d.Path, diffValue(d.Source), colorReset)
+		case "removed":
+			fmt.Fprintf(w, "    %s- %s: %s%s\n", colorRed, d.Path, diffValue(d.Target), colorReset)
+		default:
+			fmt.Fprintf(w, "    %s~ %s: %s -> %s%s\n", colorYellow, d.Path, diffValue(d.Target), diffValue(d.Source), colorReset)
+		}
+	}
+}
+
+// diffValue renders a field value as compact JSON, shortened when long.
+func diffValue(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	if s := []rune(string(data)); len(s) > maxDiffValue {
+		return string(s[:maxDiffValue-3]) + "..."
+	}
+	return string(data)
+}
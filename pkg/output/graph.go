@@ -0,0 +1,102 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/a13x22/kube-copy/pkg/copier"
+	"github.com/a13x22/kube-copy/pkg/discovery"
+)
+
+// PrintGraph renders the discovered dependency graph to w in the given
+// format ("tree" or "dot").
+func PrintGraph(w io.Writer, g *discovery.Graph, format string) error {
+	switch format {
+	case "dot":
+		return printDot(g, w)
+	default:
+		return printTree(g, w)
+	}
+}
+
+// printTree prints the graph as an indented tree, one resource per line,
+// annotated with the relationship that pulled it in; resources outside the
+// root's namespace are prefixed with theirs:
+//
+//	Deployment/myapp
+//	├── ConfigMap/app-config  (configMap reference)
+//	└── Service/myapp  (service selector match)
+//	    └── Ingress/myapp  (ingress backend)
+func printTree(g *discovery.Graph, w io.Writer) error {
+	children := map[string][]discovery.Edge{}
+	for _, e := range g.Edges {
+		key := graphNodeID(e.From)
+		children[key] = append(children[key], e)
+	}
+
+	fmt.Fprintln(w, g.Root.DisplayName())
+
+	var walk func(ref copier.ResourceRef, prefix string)
+	walk = func(ref copier.ResourceRef, prefix string) {
+		edges := children[graphNodeID(ref)]
+		for i, e := range edges {
+			branch, indent := "├── ", "│   "
+			if i == len(edges)-1 {
+				branch, indent = "└── ", "    "
+			}
+			fmt.Fprintf(w, "%s%s%s  (%s)\n", prefix, branch, graphLabel(g, e.To), e.Relation)
+			walk(e.To, prefix+indent)
+		}
+	}
+	walk(g.Root, "")
+
+	return nil
+}
+
+// printDot emits the graph in Graphviz DOT format. Nodes are identified by
+// type, namespace, and name, so same-named resources in different namespaces
+// stay apart.
+func printDot(g *discovery.Graph, w io.Writer) error {
+	fmt.Fprintln(w, "digraph kubecopy {")
+	fmt.Fprintln(w, "  rankdir=LR;")
+	fmt.Fprintln(w, "  node [shape=box];")
+	fmt.Fprintf(w, "  %s [label=%s, style=bold];\n", dotQuote(graphNodeID(g.Root)), dotQuote(graphLabel(g, g.Root)))
+	declared := map[string]bool{graphNodeID(g.Root): true}
+	for _, e := range g.Edges {
+		for _, ref := range []copier.ResourceRef{e.From, e.To} {
+			if id := graphNodeID(ref); !declared[id] {
+				declared[id] = true
+				fmt.Fprintf(w, "  %s [label=%s];\n", dotQuote(id), dotQuote(graphLabel(g, ref)))
+			}
+		}
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(w, "  %s -> %s [label=%s];\n", dotQuote(graphNodeID(e.From)), dotQuote(graphNodeID(e.To)), dotQuote(e.Relation))
+	}
+	fmt.Fprintln(w, "}")
+	return nil
+}
+
+// graphNodeID identifies a resource within a graph, as
+// <resource>.<group>/<namespace>/<name>.
+func graphNodeID(ref copier.ResourceRef) string {
+	if ref.Namespace == "" {
+		return ref.GVR.GroupResource().String() + "/" + ref.Name
+	}
+	return ref.GVR.GroupResource().String() + "/" + ref.Namespace + "/" + ref.Name
+}
+
+// graphLabel names a resource of g, with its namespace when that is not the
+// root's.
+func graphLabel(g *discovery.Graph, ref copier.ResourceRef) string {
+	if ref.Namespace != "" && ref.Namespace != g.Root.Namespace {
+		return ref.Namespace + "/" + ref.DisplayName()
+	}
+	return ref.DisplayName()
+}
+
+// dotQuote quotes a string as a DOT ID.
+func dotQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
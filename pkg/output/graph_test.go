@@ -0,0 +1,78 @@
+package output
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/a13x22/kube-copy/pkg/copier"
+	"github.com/a13x22/kube-copy/pkg/discovery"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// golden compares got with testdata/name, or rewrites it with -update.
+func golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s (rerun with -update to accept):\n%s", path, got)
+	}
+}
+
+func ref(gvr schema.GroupVersionResource, kind, namespace, name string) copier.ResourceRef {
+	return copier.ResourceRef{GVR: gvr, Kind: kind, Namespace: namespace, Name: name, Namespaced: namespace != ""}
+}
+
+// testGraph is a Deployment with a Service and Ingress in front of it, its
+// configuration, and a same-named Service in another namespace.
+func testGraph() *discovery.Graph {
+	var (
+		deployments = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+		configMaps  = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+		services    = schema.GroupVersionResource{Version: "v1", Resource: "services"}
+		ingresses   = schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"}
+		classes     = schema.GroupVersionResource{Group: "storage.k8s.io", Version: "v1", Resource: "storageclasses"}
+	)
+	web := ref(deployments, "Deployment", "prod", "web")
+	service := ref(services, "Service", "prod", "web")
+	return &discovery.Graph{
+		Root: web,
+		Edges: []discovery.Edge{
+			{From: web, To: ref(configMaps, "ConfigMap", "prod", "web-config"), Relation: "configMap reference", Forward: true},
+			{From: web, To: service, Relation: "service selector match"},
+			{From: service, To: ref(ingresses, "Ingress", "prod", "web"), Relation: "ingress backend"},
+			{From: web, To: ref(services, "Service", "shared", "web"), Relation: "cross-namespace reference", Forward: true},
+			{From: web, To: ref(classes, "StorageClass", "", "fast"), Relation: "storage class", Forward: true},
+		},
+	}
+}
+
+func TestPrintTree(t *testing.T) {
+	var buf bytes.Buffer
+	if err := printTree(testGraph(), &buf); err != nil {
+		t.Fatal(err)
+	}
+	golden(t, "graph.tree", buf.Bytes())
+}
+
+func TestPrintDot(t *testing.T) {
+	var buf bytes.Buffer
+	if err := printDot(testGraph(), &buf); err != nil {
+		t.Fatal(err)
+	}
+	golden(t, "graph.dot", buf.Bytes())
+}
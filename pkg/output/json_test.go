@@ -0,0 +1,72 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/a13x22/kube-copy/pkg/copier"
+)
+
+// withDiffs returns planned results whose Deployment already exists in the
+// target with other replicas, and was changed by the target on read-back.
+func withDiffs() []copier.CopyResult {
+	results := planned()
+	results[1].Action = "overwrite"
+	results[1].Diff = []copier.FieldDiff{{Path: "spec.replicas", Op: "changed", Source: int64(2), Target: int64(3)}}
+	results[1].ReadbackDiff = []copier.FieldDiff{{Path: "spec.template.spec.containers[1]", Op: "added"}}
+	return results
+}
+
+func TestPrintJSONIsOnlyTheObjects(t *testing.T) {
+	tests := []struct {
+		name    string
+		asItems bool
+		want    []string
+	}{
+		{"list", false, []string{"apiVersion", "items", "kind"}},
+		{"items", true, []string{"items"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := printJSON(withDiffs(), tt.asItems, &buf); err != nil {
+				t.Fatal(err)
+			}
+			var doc map[string]json.RawMessage
+			if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+				t.Fatal(err)
+			}
+			var keys []string
+			for key := range doc {
+				keys = append(keys, key)
+			}
+			slices.Sort(keys)
+			if !slices.Equal(keys, tt.want) {
+				t.Errorf("keys = %v, want %v", keys, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintReportCarriesTheDiffs(t *testing.T) {
+	var buf bytes.Buffer
+	if err := printReport(withDiffs(), &copier.Stats{}, false, &buf); err != nil {
+		t.Fatal(err)
+	}
+	var rep report
+	if err := json.Unmarshal(buf.Bytes(), &rep); err != nil {
+		t.Fatal(err)
+	}
+	deployment := rep.Results[1]
+	if len(deployment.Diff) != 1 || deployment.Diff[0].Path != "spec.replicas" {
+		t.Errorf("diff = %v, want spec.replicas", deployment.Diff)
+	}
+	if len(deployment.ReadbackDiff) != 1 {
+		t.Errorf("readbackDiff = %v, want the added container", deployment.ReadbackDiff)
+	}
+	if rep.Stats == nil {
+		t.Errorf("report has no stats")
+	}
+}
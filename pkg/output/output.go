@@ -5,11 +5,15 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"text/tabwriter"
+	"strings"
+	"time"
 
 	"sigs.k8s.io/yaml"
 
+	"github.com/a13x22/kube-copy/pkg/conflict"
 	"github.com/a13x22/kube-copy/pkg/copier"
+	"github.com/a13x22/kube-copy/pkg/discovery"
+	"github.com/a13x22/kube-copy/pkg/sanitizer"
 )
 
 // ANSI color codes
@@ -23,56 +27,200 @@ const (
 	colorBold   = "\033[1m"
 )
 
+// MaxWarnings caps the warning and conflict lines printed below the plan
+// table; the rest are only counted. Zero means no cap.
+var MaxWarnings int
+
+// PlanHeader describes the source and target of a copy, printed above the plan table.
+type PlanHeader struct {
+	SourceVersion   string // e.g. "v1.24.9"; empty if unknown
+	TargetVersion   string
+	SourceNamespace string // namespaces read from and written to; empty if unknown
+	TargetNamespace string
+	Notices         []string // plan-level notices, e.g. about the primary resource
+}
+
+// PrintPlanHeader shows cluster versions, namespaces, and plan-level notices
+// above the plan table so version skew between source and target, and where
+// the copy goes, are always visible.
+func PrintPlanHeader(w io.Writer, h PlanHeader) {
+	versions := h.SourceVersion != "" || h.TargetVersion != ""
+	if versions {
+		src, tgt := h.SourceVersion, h.TargetVersion
+		if src == "" {
+			src = "unknown"
+		}
+		if tgt == "" {
+			tgt = "unknown"
+		}
+		fmt.Fprintf(w, "\n  %sCluster: source %s → target %s%s\n", colorGray, src, tgt, colorReset)
+	}
+	if h.SourceNamespace != "" && h.TargetNamespace != "" {
+		if !versions {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "  %sNamespace: source %s → target %s%s\n", colorGray, h.SourceNamespace, h.TargetNamespace, colorReset)
+	}
+	for _, notice := range h.Notices {
+		fmt.Fprintf(w, "\n  %sNOTE%s  %s\n", colorCyan, colorReset, notice)
+	}
+}
+
+// PrintDiscoveryWarnings shows advisories from dependency discovery, such as
+// dependencies that were deliberately left out of the graph.
+func PrintDiscoveryWarnings(w io.Writer, warnings []discovery.Warning) {
+	if len(warnings) == 0 {
+		return
+	}
+	fmt.Fprintln(w)
+	for _, warn := range warnings {
+		fmt.Fprintf(w, "  %sWARN%s  %s: %s\n", colorYellow, colorReset, warn.Resource, warn.Message)
+	}
+}
+
+// PrintDiscoveryErrors shows lookups that failed during dependency
+// discovery, meaning the plan may be missing resources.
+func PrintDiscoveryErrors(w io.Writer, errs []discovery.Warning) {
+	if len(errs) == 0 {
+		return
+	}
+	fmt.Fprintln(w)
+	for _, e := range errs {
+		fmt.Fprintf(w, "  %sERROR%s %s: %s\n", colorRed, colorReset, e.Resource, e.Message)
+	}
+}
+
+// PrintDiscoveryIncomplete notes below the plan summary that discovery hit
+// errors, so a short plan is not mistaken for a complete one.
+func PrintDiscoveryIncomplete(w io.Writer, errorCount int) {
+	if errorCount == 0 {
+		return
+	}
+	fmt.Fprintf(w, "  %sDiscovery incomplete: %d error(s)%s\n", colorRed, errorCount, colorReset)
+}
+
+// PrintExcluded lists resources discovery left out because they carry the
+// kubecopy.io/ignore label or are Secrets skipped by --skip-secrets, so the
+// exclusion is visible in the plan.
+func PrintExcluded(w io.Writer, excluded []discovery.Edge) {
+	if len(excluded) == 0 {
+		return
+	}
+	fmt.Fprintln(w)
+	for _, e := range excluded {
+		why := fmt.Sprintf("labeled %s=true", discovery.IgnoreLabel)
+		if e.ExcludedBy == discovery.ExcludedBySkipSecrets {
+			why = "--skip-secrets: provision it in the target"
+		}
+		fmt.Fprintf(w, "  %sEXCLUDED  %s (%s of %s, %s)%s\n",
+			colorGray, e.To.DisplayName(), e.Relation, e.From.DisplayName(), why, colorReset)
+	}
+}
+
+// Manifest output formats besides table. FormatYAML prints ----separated
+// documents and FormatYAMLList a v1 List; FormatJSON prints a single object
+// as is and several as a v1 List, and FormatJSONItems always prints an
+// object with an items array that does not claim to be a v1 List.
+// FormatWide prints the table without truncating names to the terminal
+// width. FormatReport prints what was planned or done with each resource as JSON,
+// and FormatReportObjects adds the sanitized objects to it.
+const (
+	FormatYAML          = "yaml"
+	FormatYAMLList      = "yaml-list"
+	FormatJSON          = "json"
+	FormatJSONItems     = "json-items"
+	FormatReport        = "report"
+	FormatReportObjects = "report-objects"
+	FormatWide          = "wide"
+)
+
+// tableWidth is the width tables written to w are fitted to: the terminal's,
+// unless the format is wide or w is not a terminal.
+func tableWidth(w io.Writer, format string) int {
+	if format == FormatWide {
+		return 0
+	}
+	return terminalWidth(w)
+}
+
 // PrintPlan shows the planned actions before execution (or for --dry-run).
-func PrintPlan(results []copier.CopyResult, format string) error {
+// Objects (yaml, json, report) are written to out, tables to log. When stats
+// is non-nil, the report carries the stats and every other format is followed
+// by a timing footer on log, so objects stay plain manifests.
+func PrintPlan(out, log io.Writer, results []copier.CopyResult, format string, stats *copier.Stats) error {
 	switch format {
-	case "yaml":
-		return printYAML(results, os.Stdout)
-	case "json":
-		return printJSON(results, os.Stdout)
+	case FormatYAML, FormatYAMLList:
+		err := printYAML(results, format == FormatYAMLList, out)
+		printStats(stats, log)
+		return err
+	case FormatJSON, FormatJSONItems:
+		err := printJSON(results, format == FormatJSONItems, out)
+		printStats(stats, log)
+		return err
+	case FormatReport, FormatReportObjects:
+		return printReport(results, stats, format == FormatReportObjects, out)
 	default:
-		return printPlanTable(results, os.Stderr)
+		err := printPlanTable(results, log, tableWidth(log, format))
+		printStats(stats, log)
+		return err
 	}
 }
 
-// PrintResults shows what actually happened after apply.
-func PrintResults(results []copier.CopyResult, format string) error {
+// PrintResults shows what actually happened after apply, writing to out and
+// log like PrintPlan.
+func PrintResults(out, log io.Writer, results []copier.CopyResult, format string, stats *copier.Stats) error {
 	switch format {
-	case "yaml":
-		return printYAML(results, os.Stdout)
-	case "json":
-		return printJSON(results, os.Stdout)
+	case FormatYAML, FormatYAMLList:
+		err := printYAML(results, format == FormatYAMLList, out)
+		printStats(stats, log)
+		return err
+	case FormatJSON, FormatJSONItems:
+		err := printJSON(results, format == FormatJSONItems, out)
+		printStats(stats, log)
+		return err
+	case FormatReport, FormatReportObjects:
+		return printReport(results, stats, format == FormatReportObjects, out)
 	default:
-		return printResultsTable(results, os.Stderr)
+		err := printResultsTable(results, log, tableWidth(log, format))
+		printStats(stats, log)
+		return err
 	}
 }
 
-func printPlanTable(results []copier.CopyResult, w io.Writer) error {
+// printStats prints a footer with the duration and API requests of each phase.
+func printStats(stats *copier.Stats, w io.Writer) {
+	if stats == nil || len(stats.Phases) == 0 {
+		return
+	}
+	parts := make([]string, len(stats.Phases))
+	for i, p := range stats.Phases {
+		parts[i] = fmt.Sprintf("%s %.1fs (%d API calls)", p.Name, p.Duration.Seconds(), p.APICalls)
+	}
+	fmt.Fprintf(w, "  %sTimings: %s%s\n\n", colorGray, strings.Join(parts, ", "), colorReset)
+}
+
+func printPlanTable(results []copier.CopyResult, w io.Writer, width int) error {
 	fmt.Fprintln(w)
-	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
-	fmt.Fprintf(tw, "  %s%sACTION\tRESOURCE\tSOURCE\tTARGET%s\n", colorBold, colorGray, colorReset)
-	fmt.Fprintf(tw, "  %s------\t--------\t------\t------%s\n", colorGray, colorReset)
+	t := &table{}
+	t.add(colorBold+colorGray, "ACTION", "RESOURCE", "SOURCE", "TARGET")
+	t.add(colorGray, "------", "--------", "------", "------")
 
 	for _, r := range results {
+		source := r.Source.Namespace + "/" + r.Source.Name
+		target := r.TargetNS + "/" + r.TargetName
 		if r.Error != nil {
-			fmt.Fprintf(tw, "  %serror\t%s\t%s/%s\t%s/%s%s\n",
-				colorRed,
-				r.Source.DisplayName(),
-				r.Source.Namespace, r.Source.Name,
-				r.TargetNS, r.TargetName,
-				colorReset)
+			t.add(colorRed, "error", r.Source.DisplayName(), source, target)
 			continue
 		}
 
 		color, symbol := actionStyle(r.Action)
-		fmt.Fprintf(tw, "  %s%s %s\t%s\t%s/%s\t%s/%s%s\n",
-			color, symbol, r.Action,
-			r.Source.DisplayName(),
-			r.Source.Namespace, r.Source.Name,
-			r.TargetNS, r.TargetName,
-			colorReset)
+		action := r.Action
+		if len(r.Diff) > 0 {
+			action += fmt.Sprintf(" (%d field(s) differ)", len(r.Diff))
+		}
+		t.add(color, symbol+" "+action, r.Source.DisplayName(), source, target)
 	}
-	tw.Flush()
+	t.render(w, width, false, true, true, true)
 
 	// Warnings and conflicts
 	printWarningsAndConflicts(results, w)
@@ -90,28 +238,47 @@ func printPlanTable(results []copier.CopyResult, w io.Writer) error {
 	return nil
 }
 
-func printResultsTable(results []copier.CopyResult, w io.Writer) error {
-	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+func printResultsTable(results []copier.CopyResult, w io.Writer, width int) error {
+	t := &table{}
+	verified := anyVerified(results)
+	if verified {
+		t.add(colorBold+colorGray, "   ACTION", "RESOURCE", "TARGET", "VERIFIED")
+	}
 
 	for _, r := range results {
+		target := "-> " + r.TargetNS + "/" + r.TargetName
+		var cells []string
 		if r.Error != nil {
-			fmt.Fprintf(tw, "  %sx  %s\t-> %s/%s\t%s%s\n",
-				colorRed,
-				r.Source.DisplayName(),
-				r.TargetNS, r.TargetName,
-				"ERROR",
-				colorReset)
-			continue
+			label := "x  error"
+			if r.Action == "deleted" {
+				label = "x  deleted, not recreated"
+			}
+			cells = []string{label, r.Source.DisplayName(), target}
+		} else {
+			_, symbol := doneStyle(r.Action)
+			action := r.Action
+			if r.DeletionTime > 0 {
+				action += fmt.Sprintf(" (deleted in %s)", r.DeletionTime.Round(100*time.Millisecond))
+			}
+			cells = []string{symbol + "  " + action, r.Source.DisplayName(), target}
 		}
+		if verified {
+			cells = append(cells, verificationLabel(r.Verification))
+		}
+		color, _ := doneStyle(r.Action)
+		if r.Error != nil {
+			color = colorRed
+		}
+		t.add(color, cells...)
+	}
+	t.render(w, width, false, true, true, false)
 
-		color, symbol := doneStyle(r.Action)
-		fmt.Fprintf(tw, "  %s%s  %-12s\t%s -> %s/%s%s\n",
-			color, symbol, r.Action,
-			r.Source.DisplayName(),
-			r.TargetNS, r.TargetName,
-			colorReset)
+	// Fields the target changed, for resources read back with --verify
+	for _, r := range results {
+		if r.Verification == "modified" {
+			fmt.Fprintf(w, "  %sMODIFIED %s:%s %s\n", colorYellow, r.Source.DisplayName(), colorReset, readbackPaths(r.ReadbackDiff))
+		}
 	}
-	tw.Flush()
 
 	// Errors detail
 	for _, r := range results {
@@ -126,6 +293,36 @@ func printResultsTable(results []copier.CopyResult, w io.Writer) error {
 	return nil
 }
 
+func anyVerified(results []copier.CopyResult) bool {
+	for _, r := range results {
+		if r.Verification != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func verificationLabel(v string) string {
+	switch v {
+	case "verified":
+		return "yes"
+	case "modified":
+		return "modified"
+	case "unverified":
+		return "unknown"
+	default:
+		return "-"
+	}
+}
+
+func readbackPaths(diffs []copier.FieldDiff) string {
+	paths := make([]string, len(diffs))
+	for i, d := range diffs {
+		paths[i] = d.Path
+	}
+	return strings.Join(paths, ", ")
+}
+
 func actionStyle(action string) (string, string) {
 	switch action {
 	case "create":
@@ -134,6 +331,16 @@ func actionStyle(action string) (string, string) {
 		return colorYellow, "-"
 	case "overwrite":
 		return colorYellow, "~"
+	case "unchanged":
+		return colorGray, "="
+	case "exists":
+		return colorGray, "-"
+	case "done":
+		return colorGray, "="
+	case "delete", "prune":
+		return colorRed, "-"
+	case "aborted":
+		return colorGray, "-"
 	default:
 		return colorCyan, "?"
 	}
@@ -147,31 +354,106 @@ func doneStyle(action string) (string, string) {
 		return colorYellow, "-"
 	case "overwritten":
 		return colorYellow, "~"
+	case "unchanged":
+		return colorGray, "="
+	case "exists":
+		return colorGray, "-"
+	case "deleted", "pruned":
+		return colorRed, "-"
+	case "moved":
+		return colorGreen, ">"
+	case "rolled back":
+		return colorYellow, "<"
+	case "aborted", "canceled":
+		return colorGray, "-"
 	default:
 		return colorRed, "x"
 	}
 }
 
+// printWarningsAndConflicts lists warnings and conflicts under a header per
+// resource, in table order. Repeated identical lines are collapsed with a
+// count, and with MaxWarnings set, lines beyond it are only counted.
 func printWarningsAndConflicts(results []copier.CopyResult, w io.Writer) {
-	hasAny := false
-	for _, r := range results {
-		if len(r.Warnings) > 0 || len(r.Conflicts) > 0 {
-			hasAny = true
-			break
-		}
-	}
-	if !hasAny {
-		return
+	type line struct {
+		text  string
+		count int
 	}
+	printed, hidden, suppressed := 0, 0, 0
+	first := true
 
-	fmt.Fprintln(w)
 	for _, r := range results {
+		var lines []*line
+		seen := map[string]*line{}
+		addLine := func(text string) {
+			if l, ok := seen[text]; ok {
+				l.count++
+				return
+			}
+			l := &line{text: text, count: 1}
+			seen[text] = l
+			lines = append(lines, l)
+		}
 		for _, warn := range r.Warnings {
-			fmt.Fprintf(w, "  %sWARN%s  %s: %s\n", colorYellow, colorReset, warn.Resource, warn.Message)
+			if warn.Suppressed {
+				suppressed++
+				continue
+			}
+			msg := warn.Message
+			if warn.Resource != "" && warn.Resource != r.Source.DisplayName() {
+				msg = warn.Resource + ": " + msg
+			}
+			if warn.Code != "" {
+				msg = "[" + warn.Code + "] " + msg
+			}
+			label, color := "WARN", colorYellow
+			if warn.Severity == sanitizer.SeverityInfo {
+				label, color = "INFO", colorGray
+			}
+			addLine(fmt.Sprintf("%s%s%s  %s", color, label, colorReset, msg))
 		}
 		for _, c := range r.Conflicts {
-			fmt.Fprintf(w, "  %sCONFLICT [%s]%s %s\n", colorRed, c.Type, colorReset, c.Message)
+			addLine(fmt.Sprintf("%sCONFLICT [%s]%s %s", conflictColor(c.Severity), c.Type, colorReset, c.Message))
 		}
+
+		for _, l := range lines {
+			if MaxWarnings > 0 && printed >= MaxWarnings {
+				hidden += l.count
+				continue
+			}
+			if first {
+				fmt.Fprintln(w)
+				first = false
+			}
+			if l == lines[0] {
+				fmt.Fprintf(w, "  %s%s%s\n", colorBold, r.Source.DisplayName(), colorReset)
+			}
+			text := l.text
+			if l.count > 1 {
+				text += fmt.Sprintf(" %s(x%d)%s", colorGray, l.count, colorReset)
+			}
+			fmt.Fprintf(w, "    %s\n", text)
+			printed++
+		}
+	}
+	if hidden > 0 {
+		fmt.Fprintf(w, "  %s... and %d more (raise --max-warnings to see them)%s\n", colorGray, hidden, colorReset)
+	}
+	if suppressed > 0 {
+		fmt.Fprintf(w, "  %s%d suppressed warning(s) not shown%s\n", colorGray, suppressed, colorReset)
+	}
+}
+
+// conflictColor renders blocking conflicts in red, informational ones in
+// yellow, and notes in gray.
+func conflictColor(severity conflict.Severity) string {
+	switch severity {
+	case conflict.SeverityError:
+		return colorRed
+	case conflict.SeverityNote:
+		return colorGray
+	default:
+		return colorYellow
 	}
 }
 
@@ -179,6 +461,11 @@ func printPlanSummary(results []copier.CopyResult, w io.Writer) {
 	creates := countAction(results, "create")
 	skips := countAction(results, "skip")
 	overwrites := countAction(results, "overwrite")
+	unchanged := countAction(results, "unchanged")
+	existing := countAction(results, "exists")
+	done := countAction(results, "done")
+	deletes := countAction(results, "delete")
+	prunes := countAction(results, "prune")
 	errors := countErrors(results)
 
 	fmt.Fprintf(w, "\n  %sPlan: %d resource(s)", colorGray, len(results))
@@ -191,6 +478,21 @@ func printPlanSummary(results []copier.CopyResult, w io.Writer) {
 	if overwrites > 0 {
 		fmt.Fprintf(w, ", %s%d to overwrite%s", colorYellow, overwrites, colorGray)
 	}
+	if unchanged > 0 {
+		fmt.Fprintf(w, ", %d unchanged", unchanged)
+	}
+	if existing > 0 {
+		fmt.Fprintf(w, ", %d already existing", existing)
+	}
+	if done > 0 {
+		fmt.Fprintf(w, ", %d done by the resumed run", done)
+	}
+	if deletes > 0 {
+		fmt.Fprintf(w, ", %s%d to delete%s", colorRed, deletes, colorGray)
+	}
+	if prunes > 0 {
+		fmt.Fprintf(w, ", %s%d to prune%s", colorRed, prunes, colorGray)
+	}
 	if errors > 0 {
 		fmt.Fprintf(w, ", %s%d error(s)%s", colorRed, errors, colorGray)
 	}
@@ -201,7 +503,19 @@ func printDoneSummary(results []copier.CopyResult, w io.Writer) {
 	created := countAction(results, "created")
 	skipped := countAction(results, "skipped")
 	overwritten := countAction(results, "overwritten")
+	unchanged := countAction(results, "unchanged")
+	existing := countAction(results, "exists")
+	deleted := countAction(results, "deleted")
+	pruned := countAction(results, "pruned")
+	moved := countAction(results, "moved")
+	rolledBack := countAction(results, "rolled back")
+	aborted := countAction(results, "aborted")
+	canceled := countAction(results, "canceled")
 	errors := countErrors(results)
+	retries := 0
+	for _, r := range results {
+		retries += r.Retries
+	}
 
 	fmt.Fprintf(w, "\n  %sDone: %d resource(s)", colorGray, len(results))
 	if created > 0 {
@@ -213,9 +527,36 @@ func printDoneSummary(results []copier.CopyResult, w io.Writer) {
 	if overwritten > 0 {
 		fmt.Fprintf(w, ", %s%d overwritten%s", colorYellow, overwritten, colorGray)
 	}
+	if unchanged > 0 {
+		fmt.Fprintf(w, ", %d unchanged", unchanged)
+	}
+	if existing > 0 {
+		fmt.Fprintf(w, ", %d already existing", existing)
+	}
+	if deleted > 0 {
+		fmt.Fprintf(w, ", %s%d deleted%s", colorRed, deleted, colorGray)
+	}
+	if pruned > 0 {
+		fmt.Fprintf(w, ", %s%d pruned%s", colorRed, pruned, colorGray)
+	}
+	if moved > 0 {
+		fmt.Fprintf(w, ", %s%d moved%s", colorGreen, moved, colorGray)
+	}
+	if rolledBack > 0 {
+		fmt.Fprintf(w, ", %s%d rolled back%s", colorYellow, rolledBack, colorGray)
+	}
+	if aborted > 0 {
+		fmt.Fprintf(w, ", %d aborted", aborted)
+	}
+	if canceled > 0 {
+		fmt.Fprintf(w, ", %d canceled", canceled)
+	}
 	if errors > 0 {
 		fmt.Fprintf(w, ", %s%d error(s)%s", colorRed, errors, colorGray)
 	}
+	if retries > 0 {
+		fmt.Fprintf(w, ", %d transient error(s) retried", retries)
+	}
 	fmt.Fprintf(w, "%s\n\n", colorReset)
 }
 
@@ -241,11 +582,13 @@ func countErrors(results []copier.CopyResult) int {
 
 // ---- YAML / JSON output (for piping) ----
 
-func printYAML(results []copier.CopyResult, w io.Writer) error {
+// printYAML prints the objects as ----separated documents in apply order,
+// or wrapped in a v1 List.
+func printYAML(results []copier.CopyResult, asList bool, w io.Writer) error {
 	objects := collectObjects(results)
 
-	if len(objects) == 1 {
-		data, err := yaml.Marshal(objects[0])
+	if asList {
+		data, err := yaml.Marshal(buildList(objects))
 		if err != nil {
 			return err
 		}
@@ -253,29 +596,36 @@ func printYAML(results []copier.CopyResult, w io.Writer) error {
 		return nil
 	}
 
-	list := buildList(objects)
-	data, err := yaml.Marshal(list)
-	if err != nil {
-		return err
+	for i, o := range objects {
+		data, err := yaml.Marshal(o)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			fmt.Fprintln(w, "---")
+		}
+		fmt.Fprint(w, string(data))
 	}
-	fmt.Fprint(w, string(data))
 	return nil
 }
 
-func printJSON(results []copier.CopyResult, w io.Writer) error {
+// printJSON prints a single object as is and several as a v1 List, or with
+// asItems always as {"items": [...]}. Nothing but the objects is added, so
+// the output decodes as a manifest; diffs are in the report.
+func printJSON(results []copier.CopyResult, asItems bool, w io.Writer) error {
 	objects := collectObjects(results)
 
-	if len(objects) == 1 {
-		data, err := json.MarshalIndent(objects[0], "", "  ")
-		if err != nil {
-			return err
-		}
-		fmt.Fprintln(w, string(data))
-		return nil
+	var doc interface{}
+	switch {
+	case asItems:
+		doc = map[string]interface{}{"items": buildList(objects)["items"]}
+	case len(objects) == 1:
+		doc = objects[0]
+	default:
+		doc = buildList(objects)
 	}
 
-	list := buildList(objects)
-	data, err := json.MarshalIndent(list, "", "  ")
+	data, err := json.MarshalIndent(doc, "", "  ")
 	if err != nil {
 		return err
 	}
@@ -310,11 +660,11 @@ func FormatResourceRef(kind, name string) string {
 	return fmt.Sprintf("%s/%s", kind, name)
 }
 
-// Print is a backwards-compatible wrapper. Deprecated: use PrintPlan/PrintResults.
+// Print is a backwards-compatible wrapper writing objects to stdout and
+// tables to stderr. Deprecated: use PrintPlan/PrintResults.
 func Print(results []copier.CopyResult, format string, dryRun bool) error {
 	if dryRun {
-		return PrintPlan(results, format)
+		return PrintPlan(os.Stdout, os.Stderr, results, format, nil)
 	}
-	return PrintResults(results, format)
+	return PrintResults(os.Stdout, os.Stderr, results, format, nil)
 }
-
@@ -0,0 +1,67 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+
+	"golang.org/x/term"
+)
+
+// defaultPager is used when $PAGER is not set. -F quits right away when the
+// text fits on one screen, -R passes colors, -X leaves it on the screen.
+const defaultPager = "less -FRX"
+
+// Pager collects output and, on Flush, shows it through the user's pager
+// when it is taller than the terminal it would otherwise be written to.
+type Pager struct {
+	bytes.Buffer
+	out io.Writer
+}
+
+// NewPager returns a Pager for output meant for w.
+func NewPager(w io.Writer) *Pager {
+	return &Pager{out: w}
+}
+
+// Flush writes the collected output to the underlying writer, through $PAGER
+// (default "less -FRX") when both it and stdin are terminals and the output
+// has more lines than the terminal. It returns once the pager exits. For a
+// ProgressReporter's Writer, the status line is paused while the pager runs.
+func (p *Pager) Flush() {
+	defer p.Reset()
+	out := p.out
+	if pw, ok := out.(*progressWriter); ok {
+		resume := pw.p.Pause()
+		defer resume()
+		out = pw.w
+	}
+	f, ok := out.(*os.File)
+	if !ok || !term.IsTerminal(int(f.Fd())) || !term.IsTerminal(int(os.Stdin.Fd())) {
+		p.out.Write(p.Bytes())
+		return
+	}
+	_, height, err := term.GetSize(int(f.Fd()))
+	if err != nil || bytes.Count(p.Bytes(), []byte("\n")) < height {
+		p.out.Write(p.Bytes())
+		return
+	}
+
+	pager := os.Getenv("PAGER")
+	if pager == "" {
+		pager = defaultPager
+	}
+	cmd := exec.Command("sh", "-c", pager)
+	cmd.Stdin = bytes.NewReader(p.Bytes())
+	cmd.Stdout = f
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		// A pager that could not run showed nothing; one that failed later did
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() == 127 {
+			f.Write(p.Bytes())
+		}
+	}
+}
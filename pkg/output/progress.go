@@ -2,45 +2,168 @@ package output
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"sync"
+	"time"
+	"unicode/utf8"
 
 	"golang.org/x/term"
+
+	"github.com/a13x22/kube-copy/pkg/copier"
 )
 
+// spinnerFrames animate the status line while a step is in progress.
+var spinnerFrames = []rune("⠋⠙⠹⠸⠼⠴⠦⠧⠇⠏")
+
+const spinnerInterval = 100 * time.Millisecond
+
 // ProgressReporter writes real-time status updates to stderr.
-// Uses carriage return to overwrite lines for a clean look.
-// Automatically disables itself when stderr is not a terminal or quiet mode is on.
+// On a terminal it uses carriage return to overwrite one status line for a
+// clean look, animated with a spinner and the time the current step has
+// taken so a slow step can be told from a hang. When stderr is not a terminal
+// it instead logs one persistent line per finished resource. Quiet mode
+// disables both. Everything else written to the terminal goes through Writer,
+// and prompts run under Pause, so nothing is drawn over.
+// Safe for concurrent use.
 type ProgressReporter struct {
-	enabled   bool
-	lastLen   int
+	mu      sync.Mutex
+	out     io.Writer // stderr
+	enabled bool      // overwrite a status line on the terminal
+	log     bool      // print a line per resource outcome
+	lastLen int
+	paused  int // Pause calls not yet resumed; the line is not drawn meanwhile
+
+	step    string        // current step, empty when the line is cleared
+	msg     string        // the step's status line
+	started time.Time     // when the current step began
+	frame   int           // spinner frame
+	stop    chan struct{} // stops the spinner goroutine; nil when none runs
 }
 
 // NewProgress creates a new progress reporter.
-// Disabled when quiet=true or stderr is not a terminal.
+// Silent when quiet=true; logs outcomes when stderr is not a terminal.
 func NewProgress(quiet bool) *ProgressReporter {
-	enabled := !quiet && term.IsTerminal(int(os.Stderr.Fd()))
-	return &ProgressReporter{enabled: enabled}
+	tty := term.IsTerminal(int(os.Stderr.Fd()))
+	return &ProgressReporter{out: os.Stderr, enabled: !quiet && tty, log: !quiet && !tty}
 }
 
 func (p *ProgressReporter) write(msg string) {
+	p.update(msg, msg)
+}
+
+// update shows msg as the status line of step. The elapsed time restarts
+// only when the step changes, not when its line does.
+func (p *ProgressReporter) update(step, msg string) {
 	if !p.enabled {
 		return
 	}
-	// Clear previous line
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if step != p.step {
+		p.step = step
+		p.started = time.Now()
+	}
+	p.msg = msg
+	p.render()
+	if p.stop == nil {
+		p.stop = make(chan struct{})
+		go p.spin(p.stop)
+	}
+}
+
+// spin redraws the status line until stop is closed.
+func (p *ProgressReporter) spin(stop chan struct{}) {
+	ticker := time.NewTicker(spinnerInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			p.mu.Lock()
+			p.frame = (p.frame + 1) % len(spinnerFrames)
+			p.render()
+			p.mu.Unlock()
+		}
+	}
+}
+
+// render redraws the status line. Callers hold p.mu.
+func (p *ProgressReporter) render() {
+	if p.msg == "" || p.paused > 0 {
+		return
+	}
+	line := string(spinnerFrames[p.frame]) + " " + p.msg
+	if elapsed := time.Since(p.started); elapsed >= time.Second {
+		line += " " + elapsed.Truncate(time.Second).String()
+	}
+	p.erase()
+	fmt.Fprintf(p.out, "  %s%s%s", colorGray, line, colorReset)
+	p.lastLen = utf8.RuneCountInString(line) + 2 // +2 for "  " prefix
+}
+
+// erase clears the status line from the terminal. Callers hold p.mu.
+func (p *ProgressReporter) erase() {
 	if p.lastLen > 0 {
-		fmt.Fprintf(os.Stderr, "\r%*s\r", p.lastLen, "")
+		fmt.Fprintf(p.out, "\r%*s\r", p.lastLen, "")
+		p.lastLen = 0
 	}
-	fmt.Fprintf(os.Stderr, "  %s%s%s", colorGray, msg, colorReset)
-	p.lastLen = len(msg) + 2 // +2 for "  " prefix
 }
 
-// Clear removes the progress line.
+// Pause clears the status line and keeps it off until resume is called, so
+// a prompt or pager has the terminal to itself. Steps reported meanwhile
+// are shown once the last Pause resumes.
+func (p *ProgressReporter) Pause() (resume func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.paused++
+	p.erase()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.paused--
+			p.render()
+		})
+	}
+}
+
+// Writer returns a writer to w that clears the status line before each
+// write, under the lock that draws it, so output to the terminal is never
+// drawn over or interleaved with an outcome line. The spinner draws the
+// line again below it; a write that leaves the cursor mid-line, such as a
+// prompt, should be made under Pause.
+func (p *ProgressReporter) Writer(w io.Writer) io.Writer {
+	return &progressWriter{p: p, w: w}
+}
+
+type progressWriter struct {
+	p *ProgressReporter
+	w io.Writer
+}
+
+func (pw *progressWriter) Write(b []byte) (int, error) {
+	pw.p.mu.Lock()
+	defer pw.p.mu.Unlock()
+	pw.p.erase()
+	return pw.w.Write(b)
+}
+
+// Clear removes the progress line and stops the spinner.
 func (p *ProgressReporter) Clear() {
-	if !p.enabled || p.lastLen == 0 {
+	if !p.enabled {
 		return
 	}
-	fmt.Fprintf(os.Stderr, "\r%*s\r", p.lastLen, "")
-	p.lastLen = 0
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stop != nil {
+		close(p.stop)
+		p.stop = nil
+	}
+	p.step, p.msg = "", ""
+	p.erase()
 }
 
 // Connecting reports that the tool is connecting to the cluster.
@@ -82,6 +205,58 @@ func (p *ProgressReporter) DiscoveredCount(count int) {
 	}
 }
 
+// Waiting reports that a copied workload is awaited to become ready.
+func (p *ProgressReporter) Waiting(displayName string) {
+	p.write(fmt.Sprintf("Waiting for %s to become ready...", displayName))
+}
+
+// Deleting reports that the source of a moved resource is being deleted.
+func (p *ProgressReporter) Deleting(displayName, namespace string) {
+	p.write(fmt.Sprintf("Deleting source %s from %s...", displayName, namespace))
+}
+
+// Transferring reports how much volume data has been copied so far.
+func (p *ProgressReporter) Transferring(displayName string, bytes int64) {
+	step := "Copying data of " + displayName
+	p.update(step, fmt.Sprintf("%s: %s...", step, formatBytes(bytes)))
+}
+
+// formatBytes renders a byte count with a binary unit, e.g. "1.5 GiB".
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// Completed implements copier.OutcomeReporter: it logs the resource's final
+// action when not writing to a terminal.
+func (p *ProgressReporter) Completed(ref copier.ResourceRef, action string) {
+	if !p.log {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fmt.Fprintf(p.out, "  %-12s %s\n", action, ref.DisplayName())
+}
+
+// Failed implements copier.OutcomeReporter: it logs the resource's error
+// when not writing to a terminal.
+func (p *ProgressReporter) Failed(ref copier.ResourceRef, err error) {
+	if !p.log {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fmt.Fprintf(p.out, "  %-12s %s: %v\n", "failed", ref.DisplayName(), err)
+}
+
 // Discovered implements copier.Progress interface.
 func (p *ProgressReporter) Discovered(count int) {
 	p.DiscoveredCount(count)
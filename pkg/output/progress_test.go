@@ -0,0 +1,91 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// terminalProgress returns a reporter drawing its status line into a buffer,
+// as it would on a terminal.
+func terminalProgress() (*ProgressReporter, *bytes.Buffer) {
+	var buf bytes.Buffer
+	return &ProgressReporter{out: &buf, enabled: true}, &buf
+}
+
+// drawn returns what p has written so far and resets it.
+func drawn(p *ProgressReporter, buf *bytes.Buffer) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := buf.String()
+	buf.Reset()
+	return s
+}
+
+func TestProgressTransferringKeepsItsStartTime(t *testing.T) {
+	p, _ := terminalProgress()
+	defer p.Clear()
+
+	p.Transferring("PersistentVolumeClaim/data", 0)
+	started := p.started
+	time.Sleep(10 * time.Millisecond)
+	p.Transferring("PersistentVolumeClaim/data", 2048)
+	if !p.started.Equal(started) {
+		t.Errorf("progress of the same claim restarted the timer")
+	}
+	if !strings.Contains(p.msg, "2.0 KiB") {
+		t.Errorf("status line = %q, want the latest byte count", p.msg)
+	}
+
+	p.Transferring("PersistentVolumeClaim/logs", 0)
+	if p.started.Equal(started) {
+		t.Errorf("the next claim kept the timer of the previous one")
+	}
+}
+
+func TestProgressPause(t *testing.T) {
+	p, buf := terminalProgress()
+	defer p.Clear()
+
+	p.Creating("Deployment/web", "staging")
+	if got := drawn(p, buf); !strings.Contains(got, "Creating Deployment/web") {
+		t.Fatalf("status line not drawn: %q", got)
+	}
+
+	resume := p.Pause()
+	fmt.Fprint(p.Writer(buf), "  Proceed? [y/N]: ")
+	if got := drawn(p, buf); !strings.HasPrefix(got, "\r") || !strings.HasSuffix(got, "  Proceed? [y/N]: ") {
+		t.Errorf("pausing did not clear the status line before the prompt: %q", got)
+	}
+
+	// Steps reported while paused, and the spinner, stay off the prompt
+	p.Creating("Service/web", "staging")
+	time.Sleep(3 * spinnerInterval)
+	if got := drawn(p, buf); got != "" {
+		t.Errorf("drew over the prompt while paused: %q", got)
+	}
+
+	resume()
+	resume() // a second call is a no-op
+	if got := drawn(p, buf); !strings.Contains(got, "Creating Service/web") {
+		t.Errorf("resuming did not draw the latest step: %q", got)
+	}
+	if p.paused != 0 {
+		t.Errorf("paused = %d after resuming, want 0", p.paused)
+	}
+}
+
+func TestProgressWriterClearsTheLine(t *testing.T) {
+	p, buf := terminalProgress()
+	defer p.Clear()
+
+	p.Creating("Deployment/web", "staging")
+	drawn(p, buf)
+	fmt.Fprintln(p.Writer(buf), "  ✓ created Deployment/web")
+	got := drawn(p, buf)
+	if !strings.HasPrefix(got, "\r") || !strings.HasSuffix(got, "\r  ✓ created Deployment/web\n") {
+		t.Errorf("write did not clear the status line first: %q", got)
+	}
+}
@@ -0,0 +1,181 @@
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/a13x22/kube-copy/pkg/copier"
+)
+
+// report is the -o report document: what was planned or done with every
+// resource, for pipelines that need more than the sanitized objects.
+type report struct {
+	Results []reportResult `json:"results"`
+	Stats   *copier.Stats  `json:"stats,omitempty"`
+}
+
+type reportResult struct {
+	Source       reportRef          `json:"source"`
+	Target       reportRef          `json:"target"`
+	Action       string             `json:"action"`
+	Decision     *copier.Decision   `json:"decision,omitempty"` // chosen at the --on-conflict=prompt prompt
+	Conflicts    []reportConflict   `json:"conflicts,omitempty"`
+	Warnings     []reportWarning    `json:"warnings,omitempty"`
+	Error        string             `json:"error,omitempty"`
+	ErrorClass   copier.ErrorClass  `json:"errorClass,omitempty"`
+	Retries      int                `json:"retries,omitempty"`
+	DeletionTime string             `json:"deletionTime,omitempty"` // how long an overwrite took to delete the existing object
+	Diff         []copier.FieldDiff `json:"diff,omitempty"`
+	Verification string             `json:"verification,omitempty"`
+	ReadbackDiff []copier.FieldDiff `json:"readbackDiff,omitempty"`
+
+	Object map[string]interface{} `json:"object,omitempty"`
+}
+
+type reportRef struct {
+	APIVersion string `json:"apiVersion"`
+	Resource   string `json:"resource"`
+	Kind       string `json:"kind,omitempty"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+}
+
+type reportWarning struct {
+	Code       string `json:"code,omitempty"`
+	Severity   string `json:"severity,omitempty"`
+	Message    string `json:"message"`
+	Suppressed bool   `json:"suppressed,omitempty"`
+}
+
+type reportConflict struct {
+	Type     string `json:"type"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// printReport prints one report entry per result, in apply order, with the
+// sanitized objects inline when withObjects is set.
+func printReport(results []copier.CopyResult, stats *copier.Stats, withObjects bool, w io.Writer) error {
+	rep := report{Results: make([]reportResult, len(results)), Stats: stats}
+	for i, r := range results {
+		targetNS := r.TargetNS
+		if !r.Source.Namespaced {
+			targetNS = ""
+		}
+		entry := reportResult{
+			Source: reportRef{
+				APIVersion: r.Source.GVR.GroupVersion().String(),
+				Resource:   r.Source.GVR.Resource,
+				Kind:       r.Source.Kind,
+				Namespace:  r.Source.Namespace,
+				Name:       r.Source.Name,
+			},
+			Target: reportRef{
+				APIVersion: r.TargetGVR.GroupVersion().String(),
+				Resource:   r.TargetGVR.Resource,
+				Kind:       r.Source.Kind,
+				Namespace:  targetNS,
+				Name:       r.TargetName,
+			},
+			Action:       r.Action,
+			Decision:     r.Decision,
+			ErrorClass:   r.ErrorClass,
+			Retries:      r.Retries,
+			Diff:         r.Diff,
+			Verification: r.Verification,
+			ReadbackDiff: r.ReadbackDiff,
+		}
+		if r.DeletionTime > 0 {
+			entry.DeletionTime = r.DeletionTime.String()
+		}
+		if r.Error != nil && r.Action != "deleted" {
+			entry.Action = "error"
+		}
+		if r.Error != nil {
+			entry.Error = r.Error.Error()
+		}
+		for _, c := range r.Conflicts {
+			entry.Conflicts = append(entry.Conflicts, reportConflict{Type: string(c.Type), Severity: string(c.Severity), Message: c.Message})
+		}
+		for _, warn := range r.Warnings {
+			entry.Warnings = append(entry.Warnings, reportWarning{
+				Code:       warn.Code,
+				Severity:   string(warn.Severity),
+				Message:    warn.Message,
+				Suppressed: warn.Suppressed,
+			})
+		}
+		if withObjects && r.Sanitized != nil {
+			entry.Object = r.Sanitized.Object
+		}
+		rep.Results[i] = entry
+	}
+
+	data, err := json.MarshalIndent(rep, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, string(data))
+	return nil
+}
+
+// ReadReport reads the results of a -o report document, as needed to resume
+// the run that printed it: resources, actions, decisions, errors, and
+// verification.
+func ReadReport(path string) ([]copier.CopyResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rep report
+	if err := json.Unmarshal(data, &rep); err != nil {
+		return nil, fmt.Errorf("%s is not a -o report document: %w", path, err)
+	}
+	results := make([]copier.CopyResult, len(rep.Results))
+	for i, entry := range rep.Results {
+		source, err := entry.Source.ref()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		target, err := entry.Target.ref()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		results[i] = copier.CopyResult{
+			Source:       source,
+			TargetNS:     target.Namespace,
+			TargetName:   target.Name,
+			TargetGVR:    target.GVR,
+			Action:       entry.Action,
+			Decision:     entry.Decision,
+			ErrorClass:   entry.ErrorClass,
+			Retries:      entry.Retries,
+			Diff:         entry.Diff,
+			Verification: entry.Verification,
+			ReadbackDiff: entry.ReadbackDiff,
+		}
+		if entry.Error != "" {
+			results[i].Error = errors.New(entry.Error)
+		}
+	}
+	return results, nil
+}
+
+func (r reportRef) ref() (copier.ResourceRef, error) {
+	gv, err := schema.ParseGroupVersion(r.APIVersion)
+	if err != nil {
+		return copier.ResourceRef{}, fmt.Errorf("invalid apiVersion %q of %s/%s: %w", r.APIVersion, r.Kind, r.Name, err)
+	}
+	return copier.ResourceRef{
+		GVR:        gv.WithResource(r.Resource),
+		Kind:       r.Kind,
+		Name:       r.Name,
+		Namespace:  r.Namespace,
+		Namespaced: r.Namespace != "",
+	}, nil
+}
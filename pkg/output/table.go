@@ -0,0 +1,128 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+const (
+	columnGap      = 2 // spaces between columns
+	tableIndent    = 2 // spaces before every row
+	minColumnWidth = 12
+)
+
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// table lays out rows of plain-text cells, each row in one color. Unlike
+// tabwriter it measures cells without their color codes, and it can shrink
+// columns to fit a terminal.
+type table struct {
+	rows []tableRow
+}
+
+type tableRow struct {
+	color string
+	cells []string
+}
+
+func (t *table) add(color string, cells ...string) {
+	t.rows = append(t.rows, tableRow{color: color, cells: cells})
+}
+
+// render writes the table. With width > 0, the columns marked in shrinkable
+// are cut down, widest first, until rows fit in width, and cells longer
+// than their column are truncated in the middle.
+func (t *table) render(w io.Writer, width int, shrinkable ...bool) {
+	var widths []int
+	for _, row := range t.rows {
+		for i, cell := range row.cells {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], visibleWidth(cell))
+		}
+	}
+	if width > 0 {
+		fitColumns(widths, shrinkable, width)
+	}
+
+	for _, row := range t.rows {
+		var b strings.Builder
+		for i, cell := range row.cells {
+			cell = truncateMiddle(cell, widths[i])
+			b.WriteString(cell)
+			if i < len(row.cells)-1 {
+				b.WriteString(strings.Repeat(" ", widths[i]-visibleWidth(cell)+columnGap))
+			}
+		}
+		fmt.Fprintf(w, "%s%s%s%s\n", strings.Repeat(" ", tableIndent), row.color, b.String(), colorReset)
+	}
+}
+
+// fitColumns narrows the widest shrinkable column, one character at a time,
+// until the row fits in width or no shrinkable column is above
+// minColumnWidth.
+func fitColumns(widths []int, shrinkable []bool, width int) {
+	total := func() int {
+		sum := tableIndent + columnGap*(len(widths)-1)
+		for _, w := range widths {
+			sum += w
+		}
+		return sum
+	}
+	for total() > width {
+		widest := -1
+		for i, w := range widths {
+			if i < len(shrinkable) && shrinkable[i] && w > minColumnWidth && (widest < 0 || w > widths[widest]) {
+				widest = i
+			}
+		}
+		if widest < 0 {
+			return
+		}
+		widths[widest]--
+	}
+}
+
+// visibleWidth is the number of characters s takes on a terminal, ignoring
+// color codes.
+func visibleWidth(s string) int {
+	return utf8.RuneCountInString(ansiEscape.ReplaceAllString(s, ""))
+}
+
+// truncateMiddle shortens plain-text s to at most n characters by replacing
+// its middle with an ellipsis, keeping both the start and the distinctive
+// end of a name: "my-very-long…-name".
+func truncateMiddle(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n || n < 3 {
+		return s
+	}
+	head := (n - 1) / 2
+	tail := n - 1 - head
+	return string(runes[:head]) + "…" + string(runes[len(runes)-tail:])
+}
+
+// terminalWidth returns the width of the terminal w writes to, or 0 when w
+// is not a terminal. Output collected by a Pager is for the terminal it
+// pages to.
+func terminalWidth(w io.Writer) int {
+	if p, ok := w.(*Pager); ok {
+		w = p.out
+	}
+	f, ok := w.(*os.File)
+	if !ok || !term.IsTerminal(int(f.Fd())) {
+		return 0
+	}
+	width, _, err := term.GetSize(int(f.Fd()))
+	if err != nil {
+		return 0
+	}
+	return width
+}
@@ -0,0 +1,61 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/a13x22/kube-copy/pkg/copier"
+)
+
+// planned returns the results of a plan with a ConfigMap and a Deployment,
+// and a Secret planned without an object.
+func planned() []copier.CopyResult {
+	configMap := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "web-config", "namespace": "staging"},
+		"data":       map[string]interface{}{"mode": "production"},
+	}}
+	deployment := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": "web", "namespace": "staging"},
+		"spec": map[string]interface{}{
+			"replicas": int64(2),
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{map[string]interface{}{"name": "web", "image": "nginx:1.27"}},
+				},
+			},
+		},
+	}}
+	return []copier.CopyResult{
+		{Source: ref(schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}, "ConfigMap", "prod", "web-config"), Action: "create", Sanitized: configMap},
+		{Source: ref(schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}, "Deployment", "prod", "web"), Action: "create", Sanitized: deployment},
+		{Source: ref(schema.GroupVersionResource{Version: "v1", Resource: "secrets"}, "Secret", "prod", "web-tls"), Action: "skip"},
+	}
+}
+
+func TestPrintYAML(t *testing.T) {
+	tests := []struct {
+		golden  string
+		results []copier.CopyResult
+		asList  bool
+	}{
+		{"documents.yaml", planned(), false},
+		{"list.yaml", planned(), true},
+		{"single.yaml", planned()[:1], false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := printYAML(tt.results, tt.asList, &buf); err != nil {
+				t.Fatal(err)
+			}
+			golden(t, tt.golden, buf.Bytes())
+		})
+	}
+}
@@ -0,0 +1,82 @@
+// Package provenance records on a copied resource where it was copied from.
+// The keys are the anchor for finding resources kubecopy created.
+package provenance
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Annotation and label keys stamped on every created resource.
+const (
+	AnnotationSourceCluster   = "kubecopy.io/source-cluster"
+	AnnotationSourceNamespace = "kubecopy.io/source-namespace"
+	AnnotationSourceName      = "kubecopy.io/source-name"
+	AnnotationCopiedAt        = "kubecopy.io/copied-at" // RFC3339
+	AnnotationVersion         = "kubecopy.io/version"   // kubecopy version that made the copy
+
+	LabelManaged = "kubecopy.io/managed" // "true"
+)
+
+var annotations = []string{
+	AnnotationSourceCluster,
+	AnnotationSourceNamespace,
+	AnnotationSourceName,
+	AnnotationCopiedAt,
+	AnnotationVersion,
+}
+
+// Info describes the run that copies resources.
+type Info struct {
+	Cluster string // source cluster, e.g. its kubeconfig context name
+	Version string // kubecopy version
+}
+
+// Stamp records on obj that it is a copy of sourceNS/sourceName, made now.
+func Stamp(obj *unstructured.Unstructured, info Info, sourceNS, sourceName string) {
+	values := map[string]string{
+		AnnotationSourceCluster:   info.Cluster,
+		AnnotationSourceNamespace: sourceNS,
+		AnnotationSourceName:      sourceName,
+		AnnotationCopiedAt:        time.Now().UTC().Format(time.RFC3339),
+		AnnotationVersion:         info.Version,
+	}
+	a := obj.GetAnnotations()
+	if a == nil {
+		a = map[string]string{}
+	}
+	for key, value := range values {
+		if value != "" {
+			a[key] = value
+		}
+	}
+	obj.SetAnnotations(a)
+
+	l := obj.GetLabels()
+	if l == nil {
+		l = map[string]string{}
+	}
+	l[LabelManaged] = "true"
+	obj.SetLabels(l)
+}
+
+// Strip removes provenance from obj, e.g. when the source is itself a copy.
+func Strip(obj *unstructured.Unstructured) {
+	if a := obj.GetAnnotations(); a != nil {
+		for _, key := range annotations {
+			delete(a, key)
+		}
+		if len(a) == 0 {
+			a = nil
+		}
+		obj.SetAnnotations(a)
+	}
+	if l := obj.GetLabels(); l != nil {
+		delete(l, LabelManaged)
+		if len(l) == 0 {
+			l = nil
+		}
+		obj.SetLabels(l)
+	}
+}
@@ -0,0 +1,75 @@
+package sanitizer
+
+// Severity tells a warning that needs attention apart from a note about a
+// routine change.
+type Severity string
+
+const (
+	SeverityInfo    Severity = "info"    // a routine change; nothing to check
+	SeverityWarning Severity = "warning" // the copy may not behave like the original
+)
+
+// Warning codes are stable identifiers for each kind of warning, so they can
+// be filtered without matching messages. Codes are never reused.
+const (
+	CodeServiceNodePort       = "KC-SVC-001" // removed nodePort
+	CodeServiceClusterIP      = "KC-SVC-002" // reset clusterIP
+	CodeServiceLoadBalancerIP = "KC-SVC-003" // loadBalancerIP may conflict
+	CodeServiceExternalName   = "KC-SVC-004" // ExternalName must resolve in the target
+
+	CodePodNodeName        = "KC-POD-001" // removed nodeName
+	CodePodInjectedVolume  = "KC-POD-002" // removed auto-injected volume
+	CodePodHostPort        = "KC-POD-003" // hostPort may be taken on the target's nodes
+	CodePodHostPortCleared = "KC-POD-004" // removed hostPort (--clear-host-ports)
+	CodePodNodeNameSet     = "KC-POD-005" // set nodeName (--preserve-node-name)
+
+	CodePVCVolumeName         = "KC-PVC-001" // removed PV binding
+	CodePVCBindingAnnotations = "KC-PVC-002" // removed PV binding annotations
+
+	CodePVClaimRef      = "KC-PV-001" // removed the claim a PersistentVolume was bound to
+	CodePVSharedStorage = "KC-PV-002" // storage referenced from two clusters
+	CodePVReclaimDelete = "KC-PV-003" // reclaim policy Delete deletes the shared storage
+
+	CodeSATokenSecret     = "KC-SA-001" // removed auto-generated token secret
+	CodeSAImagePullSecret = "KC-SA-002" // removed auto-generated imagePullSecret
+
+	CodeStatefulSetClaims = "KC-STS-001" // renamed StatefulSet does not adopt the claims of the source
+
+	CodeJobLabels         = "KC-JOB-001" // removed controller-generated labels
+	CodeJobSelector       = "KC-JOB-002" // removed auto-generated selector
+	CodeJobTemplateLabels = "KC-JOB-003" // removed controller-generated template labels
+
+	CodeIngressHost    = "KC-ING-001" // hardcoded host may conflict
+	CodeIngressTLSHost = "KC-ING-002" // TLS host needs a valid secret and DNS
+
+	CodeRouteParentRef  = "KC-ROUTE-001" // parentRef in another namespace
+	CodeRouteBackendRef = "KC-ROUTE-002" // backendRef in another namespace
+
+	CodeNetPolNamespaceSelector = "KC-NETPOL-001" // peers selected by namespace labels
+
+	CodeNamespaceSubject      = "KC-NS-001" // rewrote RoleBinding subject namespace
+	CodeNamespaceExternalName = "KC-NS-002" // rewrote in-cluster externalName
+	CodeNamespaceAnnotation   = "KC-NS-003" // rewrote namespace/name annotation
+	CodeNamespaceHelm         = "KC-NS-004" // rewrote the release namespace of a Helm release
+	CodeNamespaceMetadata     = "KC-NS-005" // carried over the labels and annotations of a Namespace
+	CodeNamespaceUnread       = "KC-NS-006" // created a namespace without the source's metadata
+
+	CodeHelmMetadata = "KC-HELM-001" // removed Helm release metadata
+
+	CodeNameReference = "KC-REF-001" // rewrote reference to a renamed resource
+
+	CodeImageReplaced = "KC-IMG-001" // replaced a container image (--image)
+
+	CodeEnvSet              = "KC-ENV-001" // set an environment variable (--env)
+	CodeEnvValueFromDropped = "KC-ENV-002" // replaced a variable that came from a valueFrom source
+
+	CodeConverted          = "KC-CONV-001" // converted to a served version
+	CodeUnconverted        = "KC-CONV-002" // rewrote apiVersion without converting fields
+	CodeHPAMetricsDropped  = "KC-CONV-003" // dropped metrics autoscaling/v1 cannot express
+	CodeHPABehaviorDropped = "KC-CONV-004" // dropped spec.behavior
+	CodeCronJobTimeZone    = "KC-CONV-005" // dropped spec.timeZone
+
+	CodeNotRolledBack   = "KC-COPY-001"   // overwritten object could not be rolled back
+	CodeReadbackFailed  = "KC-VERIFY-001" // could not read back a created object
+	CodeReadbackChanged = "KC-VERIFY-002" // target changed a created object
+)
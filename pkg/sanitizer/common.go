@@ -2,6 +2,8 @@ package sanitizer
 
 import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/a13x22/kube-copy/pkg/provenance"
 )
 
 // SanitizeCommon strips metadata and fields that would cause conflicts when
@@ -37,6 +39,9 @@ func SanitizeCommon(obj *unstructured.Unstructured, targetNamespace, targetName
 		}
 	}
 
+	// Strip provenance of an earlier copy; Apply stamps its own
+	provenance.Strip(obj)
+
 	// ---- Strip status ----
 	delete(obj.Object, "status")
 
@@ -0,0 +1,145 @@
+package sanitizer
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// versionConverter rewrites an object's fields from one version of its
+// group/kind to another. It does not touch apiVersion.
+type versionConverter func(obj *unstructured.Unstructured, from, to string) []Warning
+
+// converters hold explicit field mappings for kinds whose schema differs
+// between versions. Other kinds only get their apiVersion rewritten.
+var converters = map[schema.GroupKind]versionConverter{
+	{Group: "autoscaling", Kind: "HorizontalPodAutoscaler"}: convertHPA,
+	{Group: "batch", Kind: "CronJob"}:                       convertCronJob,
+}
+
+// ConvertToServed rewrites obj to a version of its group/kind that the
+// target serves when the target does not serve obj's own version, preferring
+// the target's preferred version. It returns the mapping the object was
+// converted to, or nil when no conversion was needed or none is possible
+// (the API version conflict then reports it).
+func ConvertToServed(obj *unstructured.Unstructured, mapper meta.RESTMapper) (*meta.RESTMapping, []Warning) {
+	gvk := obj.GroupVersionKind()
+	if mapper == nil || gvk.Kind == "" {
+		return nil, nil
+	}
+	if _, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version); err == nil {
+		return nil, nil
+	}
+	mapping, err := mapper.RESTMapping(gvk.GroupKind())
+	if err != nil {
+		return nil, nil
+	}
+
+	identifier := fmt.Sprintf("%s/%s", gvk.Kind, obj.GetName())
+	from, to := gvk.Version, mapping.GroupVersionKind.Version
+	var warnings []Warning
+	if convert, ok := converters[gvk.GroupKind()]; ok {
+		warnings = convert(obj, from, to)
+		warnings = append(warnings, Warning{
+			Resource: identifier,
+			Code:     CodeConverted,
+			Severity: SeverityInfo,
+			Message:  fmt.Sprintf("converted from %s to %s, which the target serves", gvk.GroupVersion(), mapping.GroupVersionKind.GroupVersion()),
+		})
+	} else {
+		warnings = append(warnings, Warning{
+			Resource: identifier,
+			Code:     CodeUnconverted,
+			Severity: SeverityWarning,
+			Message: fmt.Sprintf("target does not serve %s; rewrote apiVersion to %s without converting fields -- verify the schemas are compatible",
+				gvk.GroupVersion(), mapping.GroupVersionKind.GroupVersion()),
+		})
+	}
+	obj.SetAPIVersion(mapping.GroupVersionKind.GroupVersion().String())
+	return mapping, warnings
+}
+
+// convertHPA maps between autoscaling/v1, which only knows a CPU utilization
+// target, and autoscaling/v2 (and v2beta2), which has a list of metrics.
+func convertHPA(obj *unstructured.Unstructured, from, to string) []Warning {
+	identifier := fmt.Sprintf("HorizontalPodAutoscaler/%s", obj.GetName())
+	spec, ok := obj.Object["spec"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	var warnings []Warning
+
+	switch {
+	case to == "v1" && from != "v1":
+		metrics, _ := spec["metrics"].([]interface{})
+		dropped := 0
+		for _, m := range metrics {
+			metric, _ := m.(map[string]interface{})
+			name, _, _ := unstructured.NestedString(metric, "resource", "name")
+			targetType, _, _ := unstructured.NestedString(metric, "resource", "target", "type")
+			utilization, found, _ := unstructured.NestedInt64(metric, "resource", "target", "averageUtilization")
+			if metric["type"] == "Resource" && name == "cpu" && targetType == "Utilization" && found {
+				spec["targetCPUUtilizationPercentage"] = utilization
+				continue
+			}
+			dropped++
+		}
+		delete(spec, "metrics")
+		if dropped > 0 {
+			warnings = append(warnings, Warning{
+				Resource: identifier,
+				Code:     CodeHPAMetricsDropped,
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("dropped %d metric(s) autoscaling/v1 cannot express (only CPU utilization is supported)", dropped),
+			})
+		}
+		if _, ok := spec["behavior"]; ok {
+			delete(spec, "behavior")
+			warnings = append(warnings, Warning{
+				Resource: identifier,
+				Code:     CodeHPABehaviorDropped,
+				Severity: SeverityWarning,
+				Message:  "dropped spec.behavior, which autoscaling/v1 does not support",
+			})
+		}
+
+	case from == "v1" && to != "v1":
+		if utilization, found, _ := unstructured.NestedInt64(spec, "targetCPUUtilizationPercentage"); found {
+			spec["metrics"] = []interface{}{
+				map[string]interface{}{
+					"type": "Resource",
+					"resource": map[string]interface{}{
+						"name": "cpu",
+						"target": map[string]interface{}{
+							"type":               "Utilization",
+							"averageUtilization": utilization,
+						},
+					},
+				},
+			}
+		}
+		delete(spec, "targetCPUUtilizationPercentage")
+	}
+	return warnings
+}
+
+// convertCronJob maps between batch/v1beta1 and batch/v1. The schemas are
+// the same except for spec.timeZone, which only v1 has.
+func convertCronJob(obj *unstructured.Unstructured, from, to string) []Warning {
+	if to != "v1beta1" {
+		return nil
+	}
+	tz, found, _ := unstructured.NestedString(obj.Object, "spec", "timeZone")
+	if !found {
+		return nil
+	}
+	unstructured.RemoveNestedField(obj.Object, "spec", "timeZone")
+	return []Warning{{
+		Resource: fmt.Sprintf("CronJob/%s", obj.GetName()),
+		Code:     CodeCronJobTimeZone,
+		Severity: SeverityWarning,
+		Message:  fmt.Sprintf("dropped spec.timeZone %q, which batch/v1beta1 does not support -- the schedule now runs in the controller's time zone", tz),
+	}}
+}
@@ -0,0 +1,124 @@
+package sanitizer
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// EnvVar sets an environment variable in the containers of a pod or
+// workload, or only in the container named Container when it is set.
+type EnvVar struct {
+	Container string
+	Name      string
+	Value     string
+}
+
+// ParseEnvVar parses "[container:]NAME=value".
+func ParseEnvVar(s string) (EnvVar, error) {
+	key, value, ok := strings.Cut(s, "=")
+	if !ok {
+		return EnvVar{}, fmt.Errorf("%q is not [container:]NAME=value", s)
+	}
+	var v EnvVar
+	if container, name, ok := strings.Cut(key, ":"); ok {
+		v.Container, v.Name = container, name
+		if errs := validation.IsDNS1123Label(container); len(errs) > 0 {
+			return EnvVar{}, fmt.Errorf("invalid container name %q: %s", container, strings.Join(errs, "; "))
+		}
+	} else {
+		v.Name = key
+	}
+	if errs := validation.IsEnvVarName(v.Name); len(errs) > 0 {
+		return EnvVar{}, fmt.Errorf("invalid environment variable name %q: %s", v.Name, strings.Join(errs, "; "))
+	}
+	v.Value = value
+	return v, nil
+}
+
+// SetEnv applies vars to the containers of a pod or workload: variables
+// with the same name are replaced and new ones appended. A variable without
+// a container is set in every container; one with a container also reaches
+// init containers of that name. Replacing a variable that came from a
+// valueFrom source drops the source with a warning.
+func SetEnv(obj *unstructured.Unstructured, vars []EnvVar) []Warning {
+	spec := podSpecOf(obj)
+	if spec == nil || len(vars) == 0 {
+		return nil
+	}
+	identifier := fmt.Sprintf("%s/%s", obj.GetKind(), obj.GetName())
+
+	var warnings []Warning
+	for _, field := range []string{"initContainers", "containers"} {
+		for _, c := range mapsOf(spec[field]) {
+			name, _ := c["name"].(string)
+			for _, v := range vars {
+				if v.Container != name && (v.Container != "" || field != "containers") {
+					continue
+				}
+				warnings = append(warnings, setEnv(c, name, v, identifier)...)
+			}
+		}
+	}
+	return warnings
+}
+
+func setEnv(container map[string]interface{}, containerName string, v EnvVar, identifier string) []Warning {
+	env, _ := container["env"].([]interface{})
+	var warnings []Warning
+	for _, e := range env {
+		existing, ok := e.(map[string]interface{})
+		if !ok || existing["name"] != v.Name {
+			continue
+		}
+		if from, ok := existing["valueFrom"].(map[string]interface{}); ok {
+			delete(existing, "valueFrom")
+			warnings = append(warnings, Warning{
+				Resource: identifier,
+				Code:     CodeEnvValueFromDropped,
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("env %s of container %q no longer comes from %s", v.Name, containerName, describeValueFrom(from)),
+			})
+		}
+		existing["value"] = v.Value
+		return append(warnings, Warning{
+			Resource: identifier,
+			Code:     CodeEnvSet,
+			Severity: SeverityInfo,
+			Message:  fmt.Sprintf("replaced env %s of container %q", v.Name, containerName),
+		})
+	}
+
+	container["env"] = append(env, map[string]interface{}{"name": v.Name, "value": v.Value})
+	return []Warning{{
+		Resource: identifier,
+		Code:     CodeEnvSet,
+		Severity: SeverityInfo,
+		Message:  fmt.Sprintf("added env %s to container %q", v.Name, containerName),
+	}}
+}
+
+// describeValueFrom names the source of a valueFrom, e.g. "secret db/url".
+func describeValueFrom(from map[string]interface{}) string {
+	ref := func(kind, key string) string {
+		m, _ := from[key].(map[string]interface{})
+		name, _ := m["name"].(string)
+		k, _ := m["key"].(string)
+		return fmt.Sprintf("%s %s/%s", kind, name, k)
+	}
+	switch {
+	case from["secretKeyRef"] != nil:
+		return ref("secret", "secretKeyRef")
+	case from["configMapKeyRef"] != nil:
+		return ref("configmap", "configMapKeyRef")
+	case from["fieldRef"] != nil:
+		path, _, _ := unstructured.NestedString(from, "fieldRef", "fieldPath")
+		return "field " + path
+	case from["resourceFieldRef"] != nil:
+		resource, _, _ := unstructured.NestedString(from, "resourceFieldRef", "resource")
+		return "resource " + resource
+	}
+	return "valueFrom"
+}
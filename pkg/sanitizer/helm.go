@@ -0,0 +1,173 @@
+package sanitizer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Helm records the release an object belongs to in these annotations, and
+// refuses to manage an object whose release namespace is not its own.
+const (
+	HelmReleaseNameAnnotation      = "meta.helm.sh/release-name"
+	HelmReleaseNamespaceAnnotation = "meta.helm.sh/release-namespace"
+)
+
+// HelmManagedByLabel is set to "Helm" on every object Helm installs.
+const HelmManagedByLabel = "app.kubernetes.io/managed-by"
+
+// helmReleaseSecretType is the type of the Secrets Helm stores releases in.
+const helmReleaseSecretType = "helm.sh/release.v1"
+
+// StripHelmMetadata removes the release annotations and managed-by label
+// Helm puts on the objects it installs. A copy that keeps them is adopted
+// by, or blocks, a release of the same name in the target, so a resource
+// copied out of a release is no longer managed by Helm; the warning says
+// so.
+func StripHelmMetadata(obj *unstructured.Unstructured) []Warning {
+	annotations := obj.GetAnnotations()
+	labels := obj.GetLabels()
+	release := annotations[HelmReleaseNameAnnotation]
+	var removed []string
+	for _, key := range []string{HelmReleaseNameAnnotation, HelmReleaseNamespaceAnnotation} {
+		if _, ok := annotations[key]; ok {
+			delete(annotations, key)
+			removed = append(removed, key)
+		}
+	}
+	if len(removed) > 0 {
+		obj.SetAnnotations(annotations)
+	}
+	if labels[HelmManagedByLabel] == "Helm" {
+		delete(labels, HelmManagedByLabel)
+		obj.SetLabels(labels)
+		removed = append(removed, HelmManagedByLabel+"=Helm")
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+
+	of := "Helm"
+	if release != "" {
+		of = fmt.Sprintf("Helm release %q", release)
+	}
+	return []Warning{{
+		Resource: fmt.Sprintf("%s/%s", obj.GetKind(), obj.GetName()),
+		Code:     CodeHelmMetadata,
+		Severity: SeverityWarning,
+		Message:  fmt.Sprintf("removed %s (installed by %s): the copy is not managed by Helm; pass --keep-helm-metadata to keep it", strings.Join(removed, ", "), of),
+	}}
+}
+
+// rewriteHelmRelease points the release namespace annotation of an object
+// managed by Helm, and the namespace recorded in a Helm release Secret, at
+// the namespace the release is copied to, so helm in the target recognizes
+// the release as its own.
+func rewriteHelmRelease(obj *unstructured.Unstructured, mapNS NamespaceMapper) []Warning {
+	var warnings []Warning
+	identifier := fmt.Sprintf("%s/%s", obj.GetKind(), obj.GetName())
+
+	annotations := obj.GetAnnotations()
+	if ns := annotations[HelmReleaseNamespaceAnnotation]; ns != "" {
+		if target, ok := mapNS(ns); ok && target != ns {
+			annotations[HelmReleaseNamespaceAnnotation] = target
+			obj.SetAnnotations(annotations)
+			warnings = append(warnings, Warning{
+				Resource: identifier,
+				Code:     CodeNamespaceHelm,
+				Severity: SeverityInfo,
+				Message:  fmt.Sprintf("rewrote annotation %s from %q to %q", HelmReleaseNamespaceAnnotation, ns, target),
+			})
+		}
+	}
+
+	if obj.GetKind() != "Secret" {
+		return warnings
+	}
+	if t, _, _ := unstructured.NestedString(obj.Object, "type"); t != helmReleaseSecretType {
+		return warnings
+	}
+	encoded, _, _ := unstructured.NestedString(obj.Object, "data", "release")
+	release, err := decodeHelmRelease(encoded)
+	if err != nil {
+		return append(warnings, Warning{
+			Resource: identifier,
+			Code:     CodeNamespaceHelm,
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf("cannot read the Helm release record (%v); it still names its source namespace", err),
+		})
+	}
+	ns, _ := release["namespace"].(string)
+	target, ok := mapNS(ns)
+	if !ok || target == ns {
+		return warnings
+	}
+	release["namespace"] = target
+	encoded, err = encodeHelmRelease(release)
+	if err != nil {
+		return warnings
+	}
+	_ = unstructured.SetNestedField(obj.Object, encoded, "data", "release")
+	return append(warnings, Warning{
+		Resource: identifier,
+		Code:     CodeNamespaceHelm,
+		Severity: SeverityInfo,
+		Message:  fmt.Sprintf("rewrote the namespace of the Helm release record from %q to %q", ns, target),
+	})
+}
+
+// decodeHelmRelease decodes the release field of a Helm release Secret:
+// Secret data holding Helm's base64 encoding of the release JSON, gzipped.
+func decodeHelmRelease(data string) (map[string]interface{}, error) {
+	helmEncoded, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, err
+	}
+	gzipped, err := base64.StdEncoding.DecodeString(string(helmEncoded))
+	if err != nil {
+		return nil, err
+	}
+	// Helm reads records that are not compressed too
+	raw := gzipped
+	if bytes.HasPrefix(gzipped, []byte{0x1f, 0x8b}) {
+		zr, err := gzip.NewReader(bytes.NewReader(gzipped))
+		if err != nil {
+			return nil, err
+		}
+		if raw, err = io.ReadAll(zr); err != nil {
+			return nil, err
+		}
+	}
+	// Numbers stay as written, since the record is encoded again
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var release map[string]interface{}
+	if err := dec.Decode(&release); err != nil {
+		return nil, err
+	}
+	return release, nil
+}
+
+// encodeHelmRelease is the reverse of decodeHelmRelease.
+func encodeHelmRelease(release map[string]interface{}) (string, error) {
+	raw, err := json.Marshal(release)
+	if err != nil {
+		return "", err
+	}
+	var gzipped bytes.Buffer
+	zw := gzip.NewWriter(&gzipped)
+	if _, err := zw.Write(raw); err != nil {
+		return "", err
+	}
+	if err := zw.Close(); err != nil {
+		return "", err
+	}
+	helmEncoded := base64.StdEncoding.EncodeToString(gzipped.Bytes())
+	return base64.StdEncoding.EncodeToString([]byte(helmEncoded)), nil
+}
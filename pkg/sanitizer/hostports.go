@@ -0,0 +1,69 @@
+package sanitizer
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func init() {
+	for _, kind := range []string{"Deployment", "StatefulSet", "DaemonSet", "ReplicaSet"} {
+		builtin(schema.GroupVersionKind{Group: "apps", Kind: kind}, SanitizerFunc(hostPortWarnings))
+	}
+}
+
+// hostPortWarnings warns about each hostPort of the containers of a pod or
+// workload, which may be taken on the target's nodes or unsupported by its
+// CNI plugin. The sanitizers of every kind with a pod spec call it.
+func hostPortWarnings(obj *unstructured.Unstructured) []Warning {
+	var warnings []Warning
+	identifier := fmt.Sprintf("%s/%s", obj.GetKind(), obj.GetName())
+	forEachHostPort(obj, func(container string, port map[string]interface{}, hostPort int64) {
+		warnings = append(warnings, Warning{
+			Resource: identifier,
+			Code:     CodePodHostPort,
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf("container %q binds hostPort %d, which may be taken on the target's nodes; pass --clear-host-ports to remove it", container, hostPort),
+		})
+	})
+	return warnings
+}
+
+// ClearHostPorts removes the hostPorts of the containers of a pod or
+// workload, with a note for each. It runs before the sanitizers, so they do
+// not warn about the ports it removed.
+func ClearHostPorts(obj *unstructured.Unstructured) []Warning {
+	var warnings []Warning
+	identifier := fmt.Sprintf("%s/%s", obj.GetKind(), obj.GetName())
+	forEachHostPort(obj, func(container string, port map[string]interface{}, hostPort int64) {
+		delete(port, "hostPort")
+		delete(port, "hostIP")
+		warnings = append(warnings, Warning{
+			Resource: identifier,
+			Code:     CodePodHostPortCleared,
+			Severity: SeverityInfo,
+			Message:  fmt.Sprintf("removed hostPort %d of container %q", hostPort, container),
+		})
+	})
+	return warnings
+}
+
+// forEachHostPort calls fn with every container port of obj's pod spec
+// that binds a hostPort.
+func forEachHostPort(obj *unstructured.Unstructured, fn func(container string, port map[string]interface{}, hostPort int64)) {
+	spec := podSpecOf(obj)
+	if spec == nil {
+		return
+	}
+	for _, field := range []string{"initContainers", "containers"} {
+		for _, c := range mapsOf(spec[field]) {
+			name, _ := c["name"].(string)
+			for _, port := range mapsOf(c["ports"]) {
+				if hostPort, ok, _ := unstructured.NestedInt64(port, "hostPort"); ok && hostPort != 0 {
+					fn(name, port, hostPort)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package sanitizer
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func init() {
+	builtin(schema.GroupVersionKind{Group: "gateway.networking.k8s.io", Kind: "HTTPRoute"}, SanitizerFunc(sanitizeHTTPRoute))
+}
+
+// sanitizeHTTPRoute warns about parentRefs and backendRefs that name another
+// namespace explicitly. Those keep pointing at the source namespace's objects
+// (typically a shared Gateway) after the copy. Status is stripped by the
+// common sanitizer.
+func sanitizeHTTPRoute(obj *unstructured.Unstructured) []Warning {
+	var warnings []Warning
+	identifier := fmt.Sprintf("HTTPRoute/%s", obj.GetName())
+
+	parents, _, _ := unstructured.NestedSlice(obj.Object, "spec", "parentRefs")
+	for _, p := range parents {
+		parent, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := parent["name"].(string)
+		if ns, _ := parent["namespace"].(string); ns != "" && ns != obj.GetNamespace() {
+			warnings = append(warnings, Warning{
+				Resource: identifier,
+				Code:     CodeRouteParentRef,
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("parentRef %s/%s is in another namespace -- the route attaches to that Gateway, verify it exists in the target cluster and allows routes from %q", ns, name, obj.GetNamespace()),
+			})
+		}
+	}
+
+	rules, _, _ := unstructured.NestedSlice(obj.Object, "spec", "rules")
+	for _, r := range rules {
+		rule, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		backends, _ := rule["backendRefs"].([]interface{})
+		for _, b := range backends {
+			backend, ok := b.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _ := backend["name"].(string)
+			if ns, _ := backend["namespace"].(string); ns != "" && ns != obj.GetNamespace() {
+				warnings = append(warnings, Warning{
+					Resource: identifier,
+					Code:     CodeRouteBackendRef,
+					Severity: SeverityWarning,
+					Message:  fmt.Sprintf("backendRef %s/%s is in another namespace and requires a ReferenceGrant there", ns, name),
+				})
+			}
+		}
+	}
+
+	return warnings
+}
@@ -0,0 +1,50 @@
+package sanitizer
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// SetImages replaces the images of the containers and init containers of a
+// pod or workload, like kubectl set image: images maps container names, or
+// "*" for every container, to the new image, and a name takes precedence over
+// "*". It returns a warning per replaced image and the keys of images that
+// matched a container.
+func SetImages(obj *unstructured.Unstructured, images map[string]string) ([]Warning, []string) {
+	spec := podSpecOf(obj)
+	if spec == nil || len(images) == 0 {
+		return nil, nil
+	}
+	identifier := fmt.Sprintf("%s/%s", obj.GetKind(), obj.GetName())
+
+	var warnings []Warning
+	var matched []string
+	for _, field := range []string{"initContainers", "containers"} {
+		for _, c := range mapsOf(spec[field]) {
+			name, _ := c["name"].(string)
+			key := name
+			image, ok := images[key]
+			if !ok {
+				key = "*"
+				image, ok = images[key]
+			}
+			if !ok {
+				continue
+			}
+			matched = append(matched, key)
+			current, _ := c["image"].(string)
+			if current == image {
+				continue
+			}
+			c["image"] = image
+			warnings = append(warnings, Warning{
+				Resource: identifier,
+				Code:     CodeImageReplaced,
+				Severity: SeverityInfo,
+				Message:  fmt.Sprintf("replaced image of container %q: %s → %s", name, current, image),
+			})
+		}
+	}
+	return warnings, matched
+}
@@ -4,10 +4,14 @@ import (
 	"fmt"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 func init() {
-	Register("Ingress", SanitizerFunc(sanitizeIngress))
+	// The fields checked are the same in networking.k8s.io/v1 and the
+	// v1beta1 versions
+	builtin(schema.GroupVersionKind{Group: "networking.k8s.io", Kind: "Ingress"}, SanitizerFunc(sanitizeIngress))
+	builtin(schema.GroupVersionKind{Group: "extensions", Kind: "Ingress"}, SanitizerFunc(sanitizeIngress))
 }
 
 func sanitizeIngress(obj *unstructured.Unstructured) []Warning {
@@ -33,6 +37,8 @@ func sanitizeIngress(obj *unstructured.Unstructured) []Warning {
 		if host, ok := rule["host"].(string); ok && host != "" {
 			warnings = append(warnings, Warning{
 				Resource: identifier,
+				Code:     CodeIngressHost,
+				Severity: SeverityWarning,
 				Message:  fmt.Sprintf("ingress rule has hardcoded host %q -- this may conflict if the same hostname is already used in the target", host),
 			})
 		}
@@ -50,6 +56,8 @@ func sanitizeIngress(obj *unstructured.Unstructured) []Warning {
 					if host, ok := h.(string); ok {
 						warnings = append(warnings, Warning{
 							Resource: identifier,
+							Code:     CodeIngressTLSHost,
+							Severity: SeverityWarning,
 							Message:  fmt.Sprintf("TLS entry references host %q -- verify the TLS secret and DNS are valid in the target", host),
 						})
 					}
@@ -4,11 +4,12 @@ import (
 	"fmt"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 func init() {
-	Register("Job", SanitizerFunc(sanitizeJob))
-	Register("CronJob", SanitizerFunc(sanitizeCronJob))
+	builtin(schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "Job"}, SanitizerFunc(sanitizeJob))
+	builtin(schema.GroupVersionKind{Group: "batch", Kind: "CronJob"}, SanitizerFunc(sanitizeCronJob))
 }
 
 func sanitizeJob(obj *unstructured.Unstructured) []Warning {
@@ -39,6 +40,8 @@ func sanitizeJob(obj *unstructured.Unstructured) []Warning {
 			}
 			warnings = append(warnings, Warning{
 				Resource: identifier,
+				Code:     CodeJobLabels,
+				Severity: SeverityInfo,
 				Message:  "removed controller-generated labels",
 			})
 		}
@@ -56,17 +59,19 @@ func sanitizeJob(obj *unstructured.Unstructured) []Warning {
 		delete(spec, "selector")
 		warnings = append(warnings, Warning{
 			Resource: identifier,
+			Code:     CodeJobSelector,
+			Severity: SeverityInfo,
 			Message:  "removed auto-generated selector to let the controller create a new one",
 		})
 	}
 
-	return warnings
+	return append(warnings, hostPortWarnings(obj)...)
 }
 
 func sanitizeCronJob(obj *unstructured.Unstructured) []Warning {
 	// CronJobs themselves do not need much specific sanitization beyond common.
 	// The Job template inside them will be handled by the Job controller at runtime.
-	return nil
+	return hostPortWarnings(obj)
 }
 
 func stripJobLabelsFromTemplate(obj *unstructured.Unstructured, identifier string, warnings *[]Warning) {
@@ -103,6 +108,8 @@ func stripJobLabelsFromTemplate(obj *unstructured.Unstructured, identifier strin
 	if changed {
 		*warnings = append(*warnings, Warning{
 			Resource: identifier,
+			Code:     CodeJobTemplateLabels,
+			Severity: SeverityInfo,
 			Message:  "removed controller-generated labels from pod template",
 		})
 	}
@@ -0,0 +1,136 @@
+package sanitizer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Metadata is extra metadata stamped on every copied resource.
+type Metadata struct {
+	Labels      map[string]string
+	Annotations map[string]string
+
+	// Overwrite replaces keys the resource already has with other values;
+	// otherwise such a resource is refused.
+	Overwrite bool
+
+	// PodTemplates also labels the pod templates of workloads, which rolls
+	// out new pods when the labels change.
+	PodTemplates bool
+}
+
+// Validate checks that the keys are legal label and annotation keys and the
+// label values legal label values.
+func (m Metadata) Validate() error {
+	for _, key := range sortedKeys(m.Labels) {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return fmt.Errorf("invalid label key %q: %s", key, strings.Join(errs, "; "))
+		}
+		if errs := validation.IsValidLabelValue(m.Labels[key]); len(errs) > 0 {
+			return fmt.Errorf("invalid value %q for label %q: %s", m.Labels[key], key, strings.Join(errs, "; "))
+		}
+	}
+	for _, key := range sortedKeys(m.Annotations) {
+		if errs := validation.IsQualifiedName(strings.ToLower(key)); len(errs) > 0 {
+			return fmt.Errorf("invalid annotation key %q: %s", key, strings.Join(errs, "; "))
+		}
+	}
+	return nil
+}
+
+// StampMetadata merges m into obj's labels and annotations, and into its pod
+// template's labels when m.PodTemplates is set. It runs after SanitizeCommon,
+// so metadata of the source object that was stripped does not count as
+// existing.
+func StampMetadata(obj *unstructured.Unstructured, m Metadata) error {
+	labels, err := merge(obj.GetLabels(), m.Labels, m.Overwrite, "label")
+	if err != nil {
+		return err
+	}
+	annotations, err := merge(obj.GetAnnotations(), m.Annotations, m.Overwrite, "annotation")
+	if err != nil {
+		return err
+	}
+	if len(m.Labels) > 0 {
+		obj.SetLabels(labels)
+	}
+	if len(m.Annotations) > 0 {
+		obj.SetAnnotations(annotations)
+	}
+
+	if !m.PodTemplates || len(m.Labels) == 0 {
+		return nil
+	}
+	template := podTemplateMetadataOf(obj)
+	if template == nil {
+		return nil
+	}
+	existing := map[string]string{}
+	if l, ok := template["labels"].(map[string]interface{}); ok {
+		for key, value := range l {
+			existing[key], _ = value.(string)
+		}
+	}
+	merged, err := merge(existing, m.Labels, m.Overwrite, "pod template label")
+	if err != nil {
+		return err
+	}
+	l := make(map[string]interface{}, len(merged))
+	for key, value := range merged {
+		l[key] = value
+	}
+	template["labels"] = l
+	return nil
+}
+
+// merge adds extra to existing, refusing to change a key to another value
+// unless overwrite is set.
+func merge(existing, extra map[string]string, overwrite bool, what string) (map[string]string, error) {
+	if existing == nil {
+		existing = map[string]string{}
+	}
+	for _, key := range sortedKeys(extra) {
+		if current, ok := existing[key]; ok && current != extra[key] && !overwrite {
+			return nil, fmt.Errorf("%s %q is already set to %q; pass --overwrite-metadata to replace it with %q", what, key, current, extra[key])
+		}
+		existing[key] = extra[key]
+	}
+	return existing, nil
+}
+
+// podTemplateMetadataOf returns the pod template metadata of a workload,
+// creating it when the template has none.
+func podTemplateMetadataOf(obj *unstructured.Unstructured) map[string]interface{} {
+	var path []string
+	switch obj.GetKind() {
+	case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "Job":
+		path = []string{"spec", "template"}
+	case "CronJob":
+		path = []string{"spec", "jobTemplate", "spec", "template"}
+	default:
+		return nil
+	}
+	template, ok := lookup(obj.Object, path...).(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	metadata, ok := template["metadata"].(map[string]interface{})
+	if !ok {
+		metadata = map[string]interface{}{}
+		template["metadata"] = metadata
+	}
+	return metadata
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,201 @@
+package sanitizer
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// NameMapper returns the target name of a resource of the given kind in the
+// source namespace, and false when it is not part of the copy, in which case
+// references to it are left alone.
+type NameMapper func(kind, sourceNS, name string) (string, bool)
+
+// RewriteNameRefs points references to resources renamed by the copy at
+// their new names: ConfigMaps, Secrets, PVCs, and the ServiceAccount used by
+// pod specs, the Services and TLS Secrets of Ingresses, a StatefulSet's
+// governing Service, and an HPA's scale target. Like RewriteNamespaceRefs it
+// must run before Run, while obj still carries its source namespace.
+func RewriteNameRefs(obj *unstructured.Unstructured, mapName NameMapper) []Warning {
+	r := nameRewriter{
+		ns:       obj.GetNamespace(),
+		resource: fmt.Sprintf("%s/%s", obj.GetKind(), obj.GetName()),
+		mapName:  mapName,
+	}
+
+	if podSpec := podSpecOf(obj); podSpec != nil {
+		r.podSpec(podSpec)
+	}
+
+	switch obj.GetKind() {
+	case "StatefulSet":
+		if spec, ok := obj.Object["spec"].(map[string]interface{}); ok {
+			r.field(spec, "serviceName", "Service", "serviceName")
+		}
+	case "Ingress":
+		r.ingress(obj)
+	case "HorizontalPodAutoscaler":
+		if target, ok := lookup(obj.Object, "spec", "scaleTargetRef").(map[string]interface{}); ok {
+			if kind, _ := target["kind"].(string); kind != "" {
+				r.field(target, "name", kind, "scaleTargetRef")
+			}
+		}
+	}
+
+	return r.warnings
+}
+
+// RenamedStatefulSetClaims warns when a StatefulSet with
+// volumeClaimTemplates is copied under targetName: its pods use claims named
+// <template>-<targetName>-<ordinal>, so the claims of the source, named
+// after its old name, are not adopted and the pods start with empty
+// volumes. Like RewriteNameRefs it must run while obj still carries its
+// source name.
+func RenamedStatefulSetClaims(obj *unstructured.Unstructured, targetName string) []Warning {
+	if obj.GetKind() != "StatefulSet" || targetName == "" || targetName == obj.GetName() {
+		return nil
+	}
+	var templates []string
+	for _, t := range mapsOf(lookup(obj.Object, "spec", "volumeClaimTemplates")) {
+		if name, _ := lookup(t, "metadata", "name").(string); name != "" {
+			templates = append(templates, name)
+		}
+	}
+	if len(templates) == 0 {
+		return nil
+	}
+	return []Warning{{
+		Resource: fmt.Sprintf("StatefulSet/%s", obj.GetName()),
+		Code:     CodeStatefulSetClaims,
+		Severity: SeverityWarning,
+		Message: fmt.Sprintf("renamed to %q: its pods use claims named %s-%s-<ordinal>, not the %s-%s-<ordinal> claims of the source, and start with empty volumes",
+			targetName, templates[0], targetName, templates[0], obj.GetName()),
+	}}
+}
+
+type nameRewriter struct {
+	ns       string
+	resource string
+	mapName  NameMapper
+	warnings []Warning
+}
+
+// field renames m[key] when it names a renamed resource of the given kind.
+func (r *nameRewriter) field(m map[string]interface{}, key, kind, what string) {
+	name, _ := m[key].(string)
+	if name == "" {
+		return
+	}
+	target, ok := r.mapName(kind, r.ns, name)
+	if !ok || target == name {
+		return
+	}
+	m[key] = target
+	r.warnings = append(r.warnings, Warning{
+		Resource: r.resource,
+		Code:     CodeNameReference,
+		Severity: SeverityInfo,
+		Message:  fmt.Sprintf("rewrote %s reference from %q to %q", what, name, target),
+	})
+}
+
+// nested renames the field at path below m, if present.
+func (r *nameRewriter) nested(m map[string]interface{}, kind, what string, path ...string) {
+	for _, p := range path[:len(path)-1] {
+		next, ok := m[p].(map[string]interface{})
+		if !ok {
+			return
+		}
+		m = next
+	}
+	r.field(m, path[len(path)-1], kind, what)
+}
+
+func (r *nameRewriter) podSpec(spec map[string]interface{}) {
+	r.field(spec, "serviceAccountName", "ServiceAccount", "serviceAccountName")
+	for _, s := range mapsOf(spec["imagePullSecrets"]) {
+		r.field(s, "name", "Secret", "imagePullSecrets")
+	}
+
+	for _, vol := range mapsOf(spec["volumes"]) {
+		r.nested(vol, "ConfigMap", "configMap volume", "configMap", "name")
+		r.nested(vol, "Secret", "secret volume", "secret", "secretName")
+		r.nested(vol, "PersistentVolumeClaim", "persistentVolumeClaim volume", "persistentVolumeClaim", "claimName")
+		for _, src := range mapsOf(lookup(vol, "projected", "sources")) {
+			r.nested(src, "ConfigMap", "projected configMap", "configMap", "name")
+			r.nested(src, "Secret", "projected secret", "secret", "name")
+		}
+	}
+
+	for _, field := range []string{"initContainers", "containers"} {
+		for _, c := range mapsOf(spec[field]) {
+			for _, envFrom := range mapsOf(c["envFrom"]) {
+				r.nested(envFrom, "ConfigMap", "envFrom configMapRef", "configMapRef", "name")
+				r.nested(envFrom, "Secret", "envFrom secretRef", "secretRef", "name")
+			}
+			for _, env := range mapsOf(c["env"]) {
+				r.nested(env, "ConfigMap", "env configMapKeyRef", "valueFrom", "configMapKeyRef", "name")
+				r.nested(env, "Secret", "env secretKeyRef", "valueFrom", "secretKeyRef", "name")
+			}
+		}
+	}
+}
+
+func (r *nameRewriter) ingress(obj *unstructured.Unstructured) {
+	spec, ok := obj.Object["spec"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	r.nested(spec, "Service", "defaultBackend service", "defaultBackend", "service", "name")
+	for _, rule := range mapsOf(spec["rules"]) {
+		for _, path := range mapsOf(lookup(rule, "http", "paths")) {
+			r.nested(path, "Service", "backend service", "backend", "service", "name")
+		}
+	}
+	for _, tls := range mapsOf(spec["tls"]) {
+		r.field(tls, "secretName", "Secret", "tls secretName")
+	}
+}
+
+// podSpecOf returns the pod spec of a Pod or a workload's pod template.
+func podSpecOf(obj *unstructured.Unstructured) map[string]interface{} {
+	var path []string
+	switch obj.GetKind() {
+	case "Pod":
+		path = []string{"spec"}
+	case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "Job":
+		path = []string{"spec", "template", "spec"}
+	case "CronJob":
+		path = []string{"spec", "jobTemplate", "spec", "template", "spec"}
+	default:
+		return nil
+	}
+	spec, _ := lookup(obj.Object, path...).(map[string]interface{})
+	return spec
+}
+
+// lookup returns the value at path below m without copying it, unlike the
+// unstructured.Nested* helpers, so callers can modify it in place.
+func lookup(m map[string]interface{}, path ...string) interface{} {
+	var v interface{} = m
+	for _, p := range path {
+		next, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = next[p]
+	}
+	return v
+}
+
+// mapsOf returns the map elements of a list field.
+func mapsOf(v interface{}) []map[string]interface{} {
+	list, _ := v.([]interface{})
+	var maps []map[string]interface{}
+	for _, item := range list {
+		if m, ok := item.(map[string]interface{}); ok {
+			maps = append(maps, m)
+		}
+	}
+	return maps
+}
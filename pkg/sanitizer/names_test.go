@@ -0,0 +1,46 @@
+package sanitizer
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func statefulSet(templates ...string) *unstructured.Unstructured {
+	var claims []interface{}
+	for _, name := range templates {
+		claims = append(claims, map[string]interface{}{"metadata": map[string]interface{}{"name": name}})
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "StatefulSet",
+		"metadata":   map[string]interface{}{"name": "db"},
+		"spec":       map[string]interface{}{"volumeClaimTemplates": claims},
+	}}
+}
+
+func TestRenamedStatefulSetClaims(t *testing.T) {
+	tests := []struct {
+		name       string
+		obj        *unstructured.Unstructured
+		targetName string
+		want       string // substring of the warning, "" for none
+	}{
+		{"renamed", statefulSet("data"), "db-v2", "claims named data-db-v2-<ordinal>, not the data-db-<ordinal> claims"},
+		{"same name", statefulSet("data"), "db", ""},
+		{"no new name", statefulSet("data"), "", ""},
+		{"no claim templates", statefulSet(), "db-v2", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings := RenamedStatefulSetClaims(tt.obj, tt.targetName)
+			switch {
+			case tt.want == "" && len(warnings) != 0:
+				t.Errorf("warnings = %v, want none", warnings)
+			case tt.want != "" && (len(warnings) != 1 || warnings[0].Code != CodeStatefulSetClaims || !strings.Contains(warnings[0].Message, tt.want)):
+				t.Errorf("warnings = %v, want one %s containing %q", warnings, CodeStatefulSetClaims, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package sanitizer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func init() {
+	builtin(schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, SanitizerFunc(sanitizeNamespace))
+}
+
+// sanitizeNamespace drops the labels and annotations Kubernetes manages on
+// a Namespace, such as kubernetes.io/metadata.name, and its finalizers,
+// and lists the labels and annotations the copy carries over: the Pod
+// Security level, sidecar injection, and ownership a namespace is set up
+// with are what make its resources behave as they do.
+func sanitizeNamespace(obj *unstructured.Unstructured) []Warning {
+	delete(obj.Object, "spec")
+
+	var warnings []Warning
+	identifier := fmt.Sprintf("Namespace/%s", obj.GetName())
+	carry := func(what string, m map[string]string) map[string]string {
+		var kept []string
+		for key, value := range m {
+			if managedKey(key) {
+				delete(m, key)
+				continue
+			}
+			kept = append(kept, key+"="+value)
+		}
+		if len(kept) > 0 {
+			sort.Strings(kept)
+			warnings = append(warnings, Warning{
+				Resource: identifier,
+				Code:     CodeNamespaceMetadata,
+				Severity: SeverityInfo,
+				Message:  fmt.Sprintf("carried over %s %s", what, strings.Join(kept, ", ")),
+			})
+		}
+		return m
+	}
+	if labels := obj.GetLabels(); len(labels) > 0 {
+		obj.SetLabels(carry("labels", labels))
+	}
+	if annotations := obj.GetAnnotations(); len(annotations) > 0 {
+		obj.SetAnnotations(carry("annotations", annotations))
+	}
+	return warnings
+}
+
+// managedKey reports whether a label or annotation key is in the prefixes
+// reserved for Kubernetes itself. Keys of its subdomains, such as
+// pod-security.kubernetes.io/enforce, are set by users.
+func managedKey(key string) bool {
+	prefix, _, ok := strings.Cut(key, "/")
+	return ok && (prefix == "kubernetes.io" || prefix == "k8s.io")
+}
@@ -0,0 +1,146 @@
+package sanitizer
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// NamespaceMapper returns the target namespace for a source namespace, and
+// false when that namespace is not part of the copy, in which case
+// references into it are left alone.
+type NamespaceMapper func(sourceNS string) (string, bool)
+
+// IngressSecretAnnotations name Secrets as "namespace/name" (or just "name"
+// for the Ingress's own namespace).
+var IngressSecretAnnotations = []string{
+	"nginx.ingress.kubernetes.io/auth-tls-secret",
+	"nginx.ingress.kubernetes.io/auth-secret",
+}
+
+// RewriteNamespaceRefs points references into other namespaces at the
+// namespaces they are copied to: RoleBinding ServiceAccount subjects,
+// ExternalName Services' in-cluster DNS names, Ingress annotations naming
+// a "namespace/secret", and the release namespace of Helm-managed objects and
+// release records. It must run before Run, while obj still carries its
+// source namespace.
+func RewriteNamespaceRefs(obj *unstructured.Unstructured, mapNS NamespaceMapper) []Warning {
+	warnings := rewriteHelmRelease(obj, mapNS)
+	switch obj.GetKind() {
+	case "RoleBinding":
+		warnings = append(warnings, rewriteRoleBindingSubjects(obj, mapNS)...)
+	case "Service":
+		warnings = append(warnings, rewriteExternalName(obj, mapNS)...)
+	case "Ingress":
+		warnings = append(warnings, rewriteIngressSecretAnnotations(obj, mapNS)...)
+	}
+	return warnings
+}
+
+// rewriteRoleBindingSubjects points ServiceAccount subjects at the namespace
+// the ServiceAccounts are copied to.
+func rewriteRoleBindingSubjects(obj *unstructured.Unstructured, mapNS NamespaceMapper) []Warning {
+	var warnings []Warning
+	identifier := fmt.Sprintf("RoleBinding/%s", obj.GetName())
+
+	subjects, ok := obj.Object["subjects"].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	for _, s := range subjects {
+		subject, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if kind, _ := subject["kind"].(string); kind != "ServiceAccount" {
+			continue
+		}
+		ns, _ := subject["namespace"].(string)
+		if ns == "" {
+			ns = obj.GetNamespace()
+		}
+		target, ok := mapNS(ns)
+		if !ok || target == ns {
+			continue
+		}
+		subject["namespace"] = target
+		name, _ := subject["name"].(string)
+		warnings = append(warnings, Warning{
+			Resource: identifier,
+			Code:     CodeNamespaceSubject,
+			Severity: SeverityInfo,
+			Message:  fmt.Sprintf("rewrote subject ServiceAccount %q namespace from %q to %q", name, ns, target),
+		})
+	}
+
+	return warnings
+}
+
+// rewriteExternalName rewrites the namespace label of an ExternalName
+// Service's in-cluster DNS name ("db.shared.svc.cluster.local").
+func rewriteExternalName(obj *unstructured.Unstructured, mapNS NamespaceMapper) []Warning {
+	host, _, _ := unstructured.NestedString(obj.Object, "spec", "externalName")
+	_, ns, ok := ParseServiceHost(host)
+	if !ok {
+		return nil
+	}
+	target, ok := mapNS(ns)
+	if !ok || target == ns {
+		return nil
+	}
+	// Splitting keeps the empty label after a trailing dot, so the
+	// rewritten name stays fully qualified
+	labels := strings.Split(host, ".")
+	labels[1] = target
+	rewritten := strings.Join(labels, ".")
+	_ = unstructured.SetNestedField(obj.Object, rewritten, "spec", "externalName")
+	return []Warning{{
+		Resource: fmt.Sprintf("Service/%s", obj.GetName()),
+		Code:     CodeNamespaceExternalName,
+		Severity: SeverityInfo,
+		Message:  fmt.Sprintf("rewrote externalName from %q to %q", host, rewritten),
+	}}
+}
+
+// ParseServiceHost parses an in-cluster Service DNS name such as
+// "db.shared.svc" or "db.shared.svc.cluster.local" into the Service name and
+// namespace. Names without the ".svc" label are treated as external.
+func ParseServiceHost(host string) (name, namespace string, ok bool) {
+	labels := strings.Split(strings.TrimSuffix(host, "."), ".")
+	if len(labels) < 3 || labels[2] != "svc" || labels[0] == "" || labels[1] == "" {
+		return "", "", false
+	}
+	return labels[0], labels[1], true
+}
+
+// rewriteIngressSecretAnnotations rewrites "namespace/name" Secret references
+// in well-known Ingress controller annotations.
+func rewriteIngressSecretAnnotations(obj *unstructured.Unstructured, mapNS NamespaceMapper) []Warning {
+	var warnings []Warning
+	annotations := obj.GetAnnotations()
+
+	for _, key := range IngressSecretAnnotations {
+		ns, name, found := strings.Cut(annotations[key], "/")
+		if !found {
+			continue
+		}
+		target, ok := mapNS(ns)
+		if !ok || target == ns {
+			continue
+		}
+		annotations[key] = target + "/" + name
+		warnings = append(warnings, Warning{
+			Resource: fmt.Sprintf("Ingress/%s", obj.GetName()),
+			Code:     CodeNamespaceAnnotation,
+			Severity: SeverityInfo,
+			Message:  fmt.Sprintf("rewrote annotation %s from %q to %q", key, ns+"/"+name, annotations[key]),
+		})
+	}
+	if len(warnings) > 0 {
+		obj.SetAnnotations(annotations)
+	}
+
+	return warnings
+}
@@ -0,0 +1,68 @@
+package sanitizer
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestParseServiceHost(t *testing.T) {
+	tests := []struct {
+		host     string
+		name, ns string
+		ok       bool
+	}{
+		{"db.shared.svc", "db", "shared", true},
+		{"db.shared.svc.cluster.local", "db", "shared", true},
+		{"db.shared.svc.cluster.local.", "db", "shared", true},
+		{"db.shared", "", "", false},
+		{"db.example.com", "", "", false},
+		{".shared.svc", "", "", false},
+		{"", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			name, ns, ok := ParseServiceHost(tt.host)
+			if name != tt.name || ns != tt.ns || ok != tt.ok {
+				t.Errorf("ParseServiceHost(%q) = %q, %q, %v, want %q, %q, %v", tt.host, name, ns, ok, tt.name, tt.ns, tt.ok)
+			}
+		})
+	}
+}
+
+func TestRewriteExternalName(t *testing.T) {
+	mapNS := func(ns string) (string, bool) {
+		if ns == "shared" {
+			return "shared-staging", true
+		}
+		return "", false
+	}
+
+	tests := []struct {
+		host, want string
+		rewritten  bool
+	}{
+		{"db.shared.svc", "db.shared-staging.svc", true},
+		{"db.shared.svc.cluster.local", "db.shared-staging.svc.cluster.local", true},
+		{"db.shared.svc.cluster.local.", "db.shared-staging.svc.cluster.local.", true},
+		{"db.other.svc.cluster.local", "db.other.svc.cluster.local", false},
+		{"db.shared.example.com", "db.shared.example.com", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			obj := &unstructured.Unstructured{Object: map[string]interface{}{
+				"kind":     "Service",
+				"metadata": map[string]interface{}{"name": "db"},
+				"spec":     map[string]interface{}{"type": "ExternalName", "externalName": tt.host},
+			}}
+			warnings := rewriteExternalName(obj, mapNS)
+			got, _, _ := unstructured.NestedString(obj.Object, "spec", "externalName")
+			if got != tt.want {
+				t.Errorf("externalName = %q, want %q", got, tt.want)
+			}
+			if tt.rewritten != hasCode(warnings, CodeNamespaceExternalName) {
+				t.Errorf("warnings = %v, want rewritten %v", warnings, tt.rewritten)
+			}
+		})
+	}
+}
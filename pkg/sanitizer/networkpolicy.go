@@ -0,0 +1,49 @@
+package sanitizer
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func init() {
+	builtin(schema.GroupVersionKind{Group: "networking.k8s.io", Version: "v1", Kind: "NetworkPolicy"}, SanitizerFunc(sanitizeNetworkPolicy))
+}
+
+// sanitizeNetworkPolicy warns about peers selected by namespaceSelector,
+// since namespace labels rarely match between source and target.
+func sanitizeNetworkPolicy(obj *unstructured.Unstructured) []Warning {
+	var warnings []Warning
+	identifier := fmt.Sprintf("NetworkPolicy/%s", obj.GetName())
+
+	for _, direction := range []struct{ rules, peers string }{
+		{rules: "ingress", peers: "from"},
+		{rules: "egress", peers: "to"},
+	} {
+		rules, _, _ := unstructured.NestedSlice(obj.Object, "spec", direction.rules)
+		for i, r := range rules {
+			rule, ok := r.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			peers, _ := rule[direction.peers].([]interface{})
+			for _, p := range peers {
+				peer, ok := p.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				if _, ok := peer["namespaceSelector"]; ok {
+					warnings = append(warnings, Warning{
+						Resource: identifier,
+						Code:     CodeNetPolNamespaceSelector,
+						Severity: SeverityWarning,
+						Message:  fmt.Sprintf("%s rule %d selects peers by namespaceSelector -- verify the target cluster's namespace labels match", direction.rules, i),
+					})
+				}
+			}
+		}
+	}
+
+	return warnings
+}
@@ -5,13 +5,23 @@ import (
 	"strings"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 func init() {
-	Register("Pod", SanitizerFunc(sanitizePod))
+	builtin(schema.GroupVersionKind{Version: "v1", Kind: "Pod"}, PodSanitizer{})
 }
 
-func sanitizePod(obj *unstructured.Unstructured) []Warning {
+// PodSanitizer is the built-in Pod sanitizer. Its zero value removes the
+// nodeName of a Pod so the target's scheduler places it; PreserveNodeName
+// keeps it instead, or pins the Pod to NodeName when that is set, e.g. to
+// debug an issue on the equivalent node of the target.
+type PodSanitizer struct {
+	PreserveNodeName bool
+	NodeName         string
+}
+
+func (s PodSanitizer) Sanitize(obj *unstructured.Unstructured) []Warning {
 	var warnings []Warning
 	identifier := fmt.Sprintf("Pod/%s", obj.GetName())
 
@@ -20,11 +30,29 @@ func sanitizePod(obj *unstructured.Unstructured) []Warning {
 		return nil
 	}
 
-	// Remove nodeName (scheduling assignment)
-	if nodeName, ok := spec["nodeName"].(string); ok && nodeName != "" {
+	// Remove nodeName (scheduling assignment), unless asked to keep or set it
+	nodeName, _ := spec["nodeName"].(string)
+	switch {
+	case s.PreserveNodeName && s.NodeName != "" && s.NodeName != nodeName:
+		spec["nodeName"] = s.NodeName
+		was := ""
+		if nodeName != "" {
+			was = fmt.Sprintf(" (was %q)", nodeName)
+		}
+		warnings = append(warnings, Warning{
+			Resource: identifier,
+			Code:     CodePodNodeNameSet,
+			Severity: SeverityInfo,
+			Message:  fmt.Sprintf("set nodeName to %q%s, bypassing the scheduler", s.NodeName, was),
+		})
+	case s.PreserveNodeName:
+		// Keep the source's node
+	case nodeName != "":
 		delete(spec, "nodeName")
 		warnings = append(warnings, Warning{
 			Resource: identifier,
+			Code:     CodePodNodeName,
+			Severity: SeverityInfo,
 			Message:  fmt.Sprintf("removed nodeName %q to allow scheduler to place the pod", nodeName),
 		})
 	}
@@ -32,7 +60,7 @@ func sanitizePod(obj *unstructured.Unstructured) []Warning {
 	// Remove auto-injected service account token volumes and volume mounts
 	sanitizeSATokenVolumes(spec, identifier, &warnings)
 
-	return warnings
+	return append(warnings, hostPortWarnings(obj)...)
 }
 
 // sanitizeSATokenVolumes removes the auto-injected service account token projected
@@ -58,6 +86,8 @@ func sanitizeSATokenVolumes(spec map[string]interface{}, identifier string, warn
 			removedNames[name] = true
 			*warnings = append(*warnings, Warning{
 				Resource: identifier,
+				Code:     CodePodInjectedVolume,
+				Severity: SeverityInfo,
 				Message:  fmt.Sprintf("removed auto-injected volume %q", name),
 			})
 			continue
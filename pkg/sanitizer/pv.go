@@ -0,0 +1,70 @@
+package sanitizer
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func init() {
+	builtin(schema.GroupVersionKind{Version: "v1", Kind: "PersistentVolume"}, SanitizerFunc(sanitizePV))
+}
+
+// sanitizePV removes the claim a PersistentVolume is bound to, so the copy
+// can bind to the copied claim, and warns that the copy points at the same
+// storage as the original.
+func sanitizePV(obj *unstructured.Unstructured) []Warning {
+	var warnings []Warning
+	identifier := fmt.Sprintf("PersistentVolume/%s", obj.GetName())
+
+	spec, ok := obj.Object["spec"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	if claimRef, ok := spec["claimRef"].(map[string]interface{}); ok {
+		delete(spec, "claimRef")
+		namespace, _ := claimRef["namespace"].(string)
+		name, _ := claimRef["name"].(string)
+		warnings = append(warnings, Warning{
+			Resource: identifier,
+			Code:     CodePVClaimRef,
+			Severity: SeverityInfo,
+			Message:  fmt.Sprintf("removed the binding to claim %s/%s", namespace, name),
+		})
+	}
+
+	warnings = append(warnings, Warning{
+		Resource: identifier,
+		Code:     CodePVSharedStorage,
+		Severity: SeverityWarning,
+		Message:  fmt.Sprintf("the copy references the same storage (%s) as the original, so it is used from two clusters; do not write to it from both unless the storage supports that", volumeSource(spec)),
+	})
+
+	if policy, _ := spec["persistentVolumeReclaimPolicy"].(string); policy == "Delete" {
+		warnings = append(warnings, Warning{
+			Resource: identifier,
+			Code:     CodePVReclaimDelete,
+			Severity: SeverityWarning,
+			Message:  "reclaim policy is Delete: deleting the claim of either copy deletes the storage both use; consider Retain",
+		})
+	}
+
+	return warnings
+}
+
+// volumeSource describes the storage a PersistentVolume spec points at.
+func volumeSource(spec map[string]interface{}) string {
+	if csi, ok := spec["csi"].(map[string]interface{}); ok {
+		driver, _ := csi["driver"].(string)
+		handle, _ := csi["volumeHandle"].(string)
+		return fmt.Sprintf("CSI volume %s of %s", handle, driver)
+	}
+	if nfs, ok := spec["nfs"].(map[string]interface{}); ok {
+		server, _ := nfs["server"].(string)
+		path, _ := nfs["path"].(string)
+		return fmt.Sprintf("NFS export %s:%s", server, path)
+	}
+	return "its volume source"
+}
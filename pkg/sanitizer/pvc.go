@@ -4,13 +4,22 @@ import (
 	"fmt"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 func init() {
-	Register("PersistentVolumeClaim", SanitizerFunc(sanitizePVC))
+	builtin(schema.GroupVersionKind{Version: "v1", Kind: "PersistentVolumeClaim"}, PVCSanitizer{})
 }
 
-func sanitizePVC(obj *unstructured.Unstructured) []Warning {
+// PVCSanitizer is the built-in PersistentVolumeClaim sanitizer. Its zero
+// value removes the volumeName of a claim so the target provisions a new
+// volume; PreserveVolumeName keeps it, for copies that bring the bound
+// PersistentVolume along.
+type PVCSanitizer struct {
+	PreserveVolumeName bool
+}
+
+func (s PVCSanitizer) Sanitize(obj *unstructured.Unstructured) []Warning {
 	var warnings []Warning
 	identifier := fmt.Sprintf("PersistentVolumeClaim/%s", obj.GetName())
 
@@ -20,10 +29,12 @@ func sanitizePVC(obj *unstructured.Unstructured) []Warning {
 	}
 
 	// Remove volumeName (PV binding) so a new PV can be dynamically provisioned
-	if volumeName, ok := spec["volumeName"].(string); ok && volumeName != "" {
+	if volumeName, ok := spec["volumeName"].(string); ok && volumeName != "" && !s.PreserveVolumeName {
 		delete(spec, "volumeName")
 		warnings = append(warnings, Warning{
 			Resource: identifier,
+			Code:     CodePVCVolumeName,
+			Severity: SeverityInfo,
 			Message:  fmt.Sprintf("removed volumeName %q (PV binding) to allow dynamic provisioning", volumeName),
 		})
 	}
@@ -53,6 +64,8 @@ func sanitizePVC(obj *unstructured.Unstructured) []Warning {
 			}
 			warnings = append(warnings, Warning{
 				Resource: identifier,
+				Code:     CodePVCBindingAnnotations,
+				Severity: SeverityInfo,
 				Message:  "removed PV binding annotations",
 			})
 		}
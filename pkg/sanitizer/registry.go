@@ -2,12 +2,19 @@ package sanitizer
 
 import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 // Warning represents an advisory message produced during sanitization.
 type Warning struct {
 	Resource string // e.g. "Service/my-svc"
+	Code     string // e.g. "KC-SVC-001", see codes.go
+	Severity Severity
 	Message  string
+
+	// Suppressed is set on warnings the user asked not to see; they are
+	// left out of tables but kept in reports.
+	Suppressed bool
 }
 
 // Sanitizer transforms a resource to make it safe to create in the target.
@@ -23,28 +30,87 @@ func (f SanitizerFunc) Sanitize(obj *unstructured.Unstructured) []Warning {
 	return f(obj)
 }
 
-// Registry maps resource kinds to their specific sanitizers.
-// The common sanitizer is always applied first.
-var Registry = map[string]Sanitizer{}
+// Registry maps resource types to their specific sanitizers. The common
+// sanitizer is always applied first. Registries are independent of each
+// other, so programs can run copies with different sanitization side by side.
+type Registry struct {
+	sanitizers map[schema.GroupVersionKind]Sanitizer
+}
+
+// builtins hold the sanitizers of this package, registered by init.
+var builtins = map[schema.GroupVersionKind]Sanitizer{}
+
+// defaultRegistry backs the package-level functions.
+var defaultRegistry = &Registry{sanitizers: map[schema.GroupVersionKind]Sanitizer{}}
+
+// builtin registers a sanitizer of this package.
+func builtin(gvk schema.GroupVersionKind, s Sanitizer) {
+	builtins[gvk] = s
+	defaultRegistry.sanitizers[gvk] = s
+}
+
+// NewRegistry returns a registry holding the built-in sanitizers. Sanitizers
+// added to the default registry with the package-level Register are not
+// included.
+func NewRegistry() *Registry {
+	r := &Registry{sanitizers: make(map[schema.GroupVersionKind]Sanitizer, len(builtins))}
+	for gvk, s := range builtins {
+		r.sanitizers[gvk] = s
+	}
+	return r
+}
+
+// Register adds a resource-specific sanitizer for the given type, replacing
+// any registered for exactly the same type. An empty Version applies it to
+// every version of the group and kind; an empty Group and Version apply it
+// to the kind in any group, so use a version for core kinds to keep CRDs of
+// the same name out.
+func (r *Registry) Register(gvk schema.GroupVersionKind, s Sanitizer) {
+	r.sanitizers[gvk] = s
+}
 
-// Register adds a resource-specific sanitizer for the given kind (lowercase).
-func Register(kind string, s Sanitizer) {
-	Registry[kind] = s
+// Lookup returns the sanitizer for gvk, trying the exact type, then its
+// group and kind, then the bare kind.
+func (r *Registry) Lookup(gvk schema.GroupVersionKind) (Sanitizer, bool) {
+	for _, key := range []schema.GroupVersionKind{
+		gvk,
+		{Group: gvk.Group, Kind: gvk.Kind},
+		{Kind: gvk.Kind},
+	} {
+		if s, ok := r.sanitizers[key]; ok {
+			return s, true
+		}
+	}
+	return nil, false
 }
 
 // Run applies the common sanitizer followed by any resource-specific sanitizer.
 // Returns collected warnings.
-func Run(obj *unstructured.Unstructured, targetNamespace, targetName string) []Warning {
+func (r *Registry) Run(obj *unstructured.Unstructured, targetNamespace, targetName string) []Warning {
 	var warnings []Warning
 
 	// Always apply universal sanitization
 	warnings = append(warnings, SanitizeCommon(obj, targetNamespace, targetName)...)
 
 	// Apply resource-specific sanitizer if registered
-	kind := obj.GetKind()
-	if s, ok := Registry[kind]; ok {
+	if s, ok := r.Lookup(obj.GroupVersionKind()); ok {
 		warnings = append(warnings, s.Sanitize(obj)...)
 	}
 
 	return warnings
 }
+
+// Register adds a sanitizer to the default registry.
+func Register(gvk schema.GroupVersionKind, s Sanitizer) {
+	defaultRegistry.Register(gvk, s)
+}
+
+// Lookup returns the sanitizer for gvk from the default registry.
+func Lookup(gvk schema.GroupVersionKind) (Sanitizer, bool) {
+	return defaultRegistry.Lookup(gvk)
+}
+
+// Run sanitizes obj with the default registry.
+func Run(obj *unstructured.Unstructured, targetNamespace, targetName string) []Warning {
+	return defaultRegistry.Run(obj, targetNamespace, targetName)
+}
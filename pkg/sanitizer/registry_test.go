@@ -0,0 +1,107 @@
+package sanitizer
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// named returns a sanitizer that identifies itself by a warning code.
+func named(name string) Sanitizer {
+	return SanitizerFunc(func(*unstructured.Unstructured) []Warning {
+		return []Warning{{Code: name}}
+	})
+}
+
+// nameOf returns the name of a sanitizer built by named, or "" for none.
+func nameOf(s Sanitizer, ok bool) string {
+	if !ok {
+		return ""
+	}
+	return s.Sanitize(&unstructured.Unstructured{})[0].Code
+}
+
+func TestRegistryLookup(t *testing.T) {
+	r := NewRegistry()
+	r.Register(schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"}, named("exact"))
+	r.Register(schema.GroupVersionKind{Group: "example.com", Kind: "Widget"}, named("group"))
+	r.Register(schema.GroupVersionKind{Kind: "Widget"}, named("kind"))
+	r.Register(schema.GroupVersionKind{Group: "example.com", Kind: "Gadget"}, named("gadget"))
+
+	tests := []struct {
+		name string
+		gvk  schema.GroupVersionKind
+		want string
+	}{
+		{"exact type", schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"}, "exact"},
+		{"other version of the group", schema.GroupVersionKind{Group: "example.com", Version: "v2", Kind: "Widget"}, "group"},
+		{"same kind in another group", schema.GroupVersionKind{Group: "other.io", Version: "v1", Kind: "Widget"}, "kind"},
+		{"group and kind only", schema.GroupVersionKind{Group: "example.com", Version: "v1beta1", Kind: "Gadget"}, "gadget"},
+		{"kind fallback is per group", schema.GroupVersionKind{Group: "other.io", Version: "v1", Kind: "Gadget"}, ""},
+		{"unknown kind", schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Gizmo"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nameOf(r.Lookup(tt.gvk)); got != tt.want {
+				t.Errorf("Lookup(%s) = %q, want %q", tt.gvk, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistryLookupBuiltins(t *testing.T) {
+	r := NewRegistry()
+	if _, ok := r.Lookup(schema.GroupVersionKind{Version: "v1", Kind: "Service"}); !ok {
+		t.Error("no sanitizer for core v1 Service")
+	}
+	if _, ok := r.Lookup(schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Service"}); ok {
+		t.Error("the core Service sanitizer applies to a CRD named Service")
+	}
+}
+
+func TestRegistriesAreIndependent(t *testing.T) {
+	widget := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"}
+	labeled, plain := NewRegistry(), NewRegistry()
+	labeled.Register(widget, SanitizerFunc(func(obj *unstructured.Unstructured) []Warning {
+		obj.SetLabels(map[string]string{"sanitized": "true"})
+		return []Warning{{Code: "labeled"}}
+	}))
+
+	run := func(r *Registry) (*unstructured.Unstructured, []Warning) {
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(widget)
+		obj.SetName("w")
+		return obj, r.Run(obj, "staging", "w")
+	}
+	obj, warnings := run(labeled)
+	if obj.GetLabels()["sanitized"] != "true" || !hasCode(warnings, "labeled") {
+		t.Errorf("registry with a Widget sanitizer did not run it: labels %v, warnings %v", obj.GetLabels(), warnings)
+	}
+	obj, warnings = run(plain)
+	if obj.GetLabels()["sanitized"] != "" || hasCode(warnings, "labeled") {
+		t.Errorf("registry without a Widget sanitizer ran another registry's: labels %v, warnings %v", obj.GetLabels(), warnings)
+	}
+}
+
+func TestPackageRegisterDoesNotLeakIntoNewRegistry(t *testing.T) {
+	widget := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"}
+	Register(widget, named("default"))
+	t.Cleanup(func() { delete(defaultRegistry.sanitizers, widget) })
+
+	if got := nameOf(Lookup(widget)); got != "default" {
+		t.Errorf("package-level Lookup = %q, want the registered sanitizer", got)
+	}
+	if _, ok := NewRegistry().Lookup(widget); ok {
+		t.Error("NewRegistry includes a sanitizer added with the package-level Register")
+	}
+}
+
+func hasCode(warnings []Warning, code string) bool {
+	for _, w := range warnings {
+		if w.Code == code {
+			return true
+		}
+	}
+	return false
+}
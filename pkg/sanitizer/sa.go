@@ -5,10 +5,11 @@ import (
 	"strings"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 func init() {
-	Register("ServiceAccount", SanitizerFunc(sanitizeServiceAccount))
+	builtin(schema.GroupVersionKind{Version: "v1", Kind: "ServiceAccount"}, SanitizerFunc(sanitizeServiceAccount))
 }
 
 func sanitizeServiceAccount(obj *unstructured.Unstructured) []Warning {
@@ -29,6 +30,8 @@ func sanitizeServiceAccount(obj *unstructured.Unstructured) []Warning {
 			if strings.Contains(name, "-token-") {
 				warnings = append(warnings, Warning{
 					Resource: identifier,
+					Code:     CodeSATokenSecret,
+					Severity: SeverityInfo,
 					Message:  fmt.Sprintf("removed auto-generated token secret reference %q", name),
 				})
 				continue
@@ -55,6 +58,8 @@ func sanitizeServiceAccount(obj *unstructured.Unstructured) []Warning {
 			if strings.Contains(name, "-dockercfg-") {
 				warnings = append(warnings, Warning{
 					Resource: identifier,
+					Code:     CodeSAImagePullSecret,
+					Severity: SeverityInfo,
 					Message:  fmt.Sprintf("removed auto-generated imagePullSecret reference %q", name),
 				})
 				continue
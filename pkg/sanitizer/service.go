@@ -4,13 +4,24 @@ import (
 	"fmt"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 func init() {
-	Register("Service", SanitizerFunc(sanitizeService))
+	builtin(schema.GroupVersionKind{Version: "v1", Kind: "Service"}, ServiceSanitizer{})
 }
 
-func sanitizeService(obj *unstructured.Unstructured) []Warning {
+// ServiceSanitizer is the built-in Service sanitizer. Its zero value resets
+// the clusterIP and nodePorts of a Service so the target cluster assigns
+// new ones; the Preserve fields keep them instead, e.g. for firewall rules
+// that expect the same nodePorts, and leave it to the address conflict
+// check to verify they are free.
+type ServiceSanitizer struct {
+	PreserveNodePorts bool
+	PreserveClusterIP bool
+}
+
+func (s ServiceSanitizer) Sanitize(obj *unstructured.Unstructured) []Warning {
 	var warnings []Warning
 	identifier := fmt.Sprintf("Service/%s", obj.GetName())
 
@@ -20,16 +31,18 @@ func sanitizeService(obj *unstructured.Unstructured) []Warning {
 	}
 
 	// Reset clusterIP to let the API server assign a new one
-	if clusterIP, ok := spec["clusterIP"].(string); ok && clusterIP != "" && clusterIP != "None" {
+	if clusterIP, ok := spec["clusterIP"].(string); ok && clusterIP != "" && clusterIP != "None" && !s.PreserveClusterIP {
 		spec["clusterIP"] = ""
 		warnings = append(warnings, Warning{
 			Resource: identifier,
+			Code:     CodeServiceClusterIP,
+			Severity: SeverityInfo,
 			Message:  fmt.Sprintf("reset clusterIP (was %s) to let the cluster assign a new one", clusterIP),
 		})
 	}
 
 	// Reset clusterIPs
-	if clusterIPs, ok := spec["clusterIPs"].([]interface{}); ok && len(clusterIPs) > 0 {
+	if clusterIPs, ok := spec["clusterIPs"].([]interface{}); ok && len(clusterIPs) > 0 && !s.PreserveClusterIP {
 		// Keep "None" for headless services
 		if len(clusterIPs) == 1 {
 			if ip, ok := clusterIPs[0].(string); ok && ip == "None" {
@@ -42,7 +55,7 @@ func sanitizeService(obj *unstructured.Unstructured) []Warning {
 skipClusterIPs:
 
 	// Clear nodePorts from each port entry
-	if ports, ok := spec["ports"].([]interface{}); ok {
+	if ports, ok := spec["ports"].([]interface{}); ok && !s.PreserveNodePorts {
 		for _, p := range ports {
 			port, ok := p.(map[string]interface{})
 			if !ok {
@@ -52,6 +65,8 @@ skipClusterIPs:
 				delete(port, "nodePort")
 				warnings = append(warnings, Warning{
 					Resource: identifier,
+					Code:     CodeServiceNodePort,
+					Severity: SeverityInfo,
 					Message:  fmt.Sprintf("removed nodePort %v to let the cluster assign a new one", np),
 				})
 			}
@@ -62,6 +77,8 @@ skipClusterIPs:
 	if lbIP, ok := spec["loadBalancerIP"].(string); ok && lbIP != "" {
 		warnings = append(warnings, Warning{
 			Resource: identifier,
+			Code:     CodeServiceLoadBalancerIP,
+			Severity: SeverityWarning,
 			Message:  fmt.Sprintf("loadBalancerIP is set to %s -- this may conflict in the target cluster", lbIP),
 		})
 	}
@@ -70,6 +87,8 @@ skipClusterIPs:
 	if svcType, ok := spec["type"].(string); ok && svcType == "ExternalName" {
 		warnings = append(warnings, Warning{
 			Resource: identifier,
+			Code:     CodeServiceExternalName,
+			Severity: SeverityWarning,
 			Message:  "ExternalName service -- verify the external name is valid in the target",
 		})
 	}
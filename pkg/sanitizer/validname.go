@@ -0,0 +1,109 @@
+package sanitizer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// nameRule is what the API server accepts as the name of a kind.
+type nameRule struct {
+	maxLength   int
+	label       bool // no dots: a DNS label rather than a DNS subdomain
+	startLetter bool // DNS-1035: starts with a letter
+	pathSegment bool // anything that is safe in a URL path, with no length limit
+}
+
+// subdomainRule applies to the names of most kinds: a DNS-1123 subdomain.
+var subdomainRule = nameRule{maxLength: 253}
+
+// nameRules are the kinds whose names are held to tighter rules.
+var nameRules = map[string]nameRule{
+	"Service":     {maxLength: 63, label: true, startLetter: true}, // DNS-1035 label, as it becomes a DNS name
+	"Namespace":   {maxLength: 63, label: true},
+	"StatefulSet": {maxLength: 63, label: true}, // its pods' hostnames are its name and an ordinal
+	"CronJob":     {maxLength: 52},              // its Jobs are named after it plus a timestamp
+
+	// RBAC objects only need names that fit in a URL path, such as
+	// "system:controller:foo"
+	"Role":               {pathSegment: true},
+	"ClusterRole":        {pathSegment: true},
+	"RoleBinding":        {pathSegment: true},
+	"ClusterRoleBinding": {pathSegment: true},
+}
+
+func ruleFor(kind string) nameRule {
+	if rule, ok := nameRules[kind]; ok {
+		return rule
+	}
+	return subdomainRule
+}
+
+// ValidateName checks that name is a valid name for a resource of kind, so
+// an invalid new name is rejected up front rather than by the API server.
+// An empty kind applies the DNS-1123 subdomain rules most kinds use. The
+// error names the offending characters or how far the name is too long.
+func ValidateName(kind, name string) error {
+	rule := ruleFor(kind)
+	what := "a name"
+	if kind != "" {
+		what = "a " + kind + " name"
+	}
+	if name == "" {
+		return fmt.Errorf("%s must not be empty", what)
+	}
+	if rule.pathSegment {
+		switch {
+		case name == "." || name == "..":
+			return fmt.Errorf("%q is not valid as %s", name, what)
+		case strings.ContainsAny(name, "/%"):
+			return fmt.Errorf("%q is not valid as %s: it must not contain '/' or '%%'", name, what)
+		}
+		return nil
+	}
+
+	allowed := "lowercase letters, digits, '-', and '.'"
+	if rule.label {
+		allowed = "lowercase letters, digits, and '-'"
+	}
+	var invalid []string
+	seen := map[rune]bool{}
+	for _, r := range name {
+		ok := r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '.' && !rule.label
+		if !ok && !seen[r] {
+			seen[r] = true
+			invalid = append(invalid, fmt.Sprintf("%q", r))
+		}
+	}
+	switch {
+	case len(invalid) > 0:
+		return fmt.Errorf("%q is not valid as %s: it contains %s, but only %s are allowed", name, what, strings.Join(invalid, ", "), allowed)
+	case len(name) > rule.maxLength:
+		return fmt.Errorf("%q is not valid as %s: it is %d characters, %d over the limit of %d", name, what, len(name), len(name)-rule.maxLength, rule.maxLength)
+	case rule.startLetter && !(name[0] >= 'a' && name[0] <= 'z'):
+		return fmt.Errorf("%q is not valid as %s: it must start with a lowercase letter", name, what)
+	case !alphanumeric(name[0]) || !alphanumeric(name[len(name)-1]):
+		return fmt.Errorf("%q is not valid as %s: it must start and end with a lowercase letter or digit", name, what)
+	}
+	if !rule.label {
+		for _, part := range strings.Split(name, ".") {
+			if part == "" || !alphanumeric(part[0]) || !alphanumeric(part[len(part)-1]) {
+				return fmt.Errorf("%q is not valid as %s: each '.'-separated part must start and end with a lowercase letter or digit", name, what)
+			}
+		}
+	}
+	return nil
+}
+
+// GenerateName appends suffix to base, shortening base as needed for the
+// result to fit the name length limit of kind, so generated names never
+// fail for being too long. Kinds without a limit keep base whole.
+func GenerateName(kind, base, suffix string) string {
+	if room := ruleFor(kind).maxLength - len(suffix); len(base) > room && room > 0 {
+		base = strings.TrimRight(base[:room], "-.")
+	}
+	return base + suffix
+}
+
+func alphanumeric(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= '0' && c <= '9'
+}
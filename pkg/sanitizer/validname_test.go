@@ -0,0 +1,64 @@
+package sanitizer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateName(t *testing.T) {
+	tests := []struct {
+		kind, name string
+		wantErr    string // substring of the error, "" for a valid name
+	}{
+		{"", "web-v2", ""},
+		{"", "web.v2", ""},
+		{"", "Web", `contains 'W'`},
+		{"", "web.-v2", "each '.'-separated part"},
+		{"", strings.Repeat("a", 254), "1 over the limit of 253"},
+		{"Service", "web.v2", `contains '.'`},
+		{"Service", "2web", "must start with a lowercase letter"},
+		{"CronJob", strings.Repeat("a", 53), "1 over the limit of 52"},
+		{"Role", "system:foo-copy", ""},
+		{"ClusterRole", "system:controller:Foo_copy", ""},
+		{"RoleBinding", "system:foo-copy", ""},
+		{"ClusterRoleBinding", strings.Repeat("a", 300), ""},
+		{"ClusterRole", "..", "not valid as a ClusterRole name"},
+		{"ClusterRole", "foo/bar", "must not contain '/' or '%'"},
+		{"Role", "foo%2f", "must not contain '/' or '%'"},
+		{"Role", "", "must not be empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind+" "+tt.name, func(t *testing.T) {
+			err := ValidateName(tt.kind, tt.name)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("ValidateName(%q, %q) = %v, want valid", tt.kind, tt.name, err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("ValidateName(%q, %q) = %v, want an error containing %q", tt.kind, tt.name, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerateName(t *testing.T) {
+	long := strings.Repeat("a", 60)
+	tests := []struct {
+		kind, base, suffix, want string
+	}{
+		{"Deployment", "web", "-copy", "web-copy"},
+		{"Service", long, "-copy", strings.Repeat("a", 58) + "-copy"},
+		{"CronJob", "backup." + long, "-copy", ("backup." + long)[:47] + "-copy"},
+		{"ClusterRole", "system:" + long, "-copy", "system:" + long + "-copy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			got := GenerateName(tt.kind, tt.base, tt.suffix)
+			if got != tt.want {
+				t.Errorf("GenerateName(%q, %q, %q) = %q, want %q", tt.kind, tt.base, tt.suffix, got, tt.want)
+			}
+			if err := ValidateName(tt.kind, got); err != nil {
+				t.Errorf("generated name is invalid: %v", err)
+			}
+		})
+	}
+}
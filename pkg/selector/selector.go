@@ -0,0 +1,63 @@
+// Package selector matches label selectors found in unstructured objects
+// against a set of labels.
+package selector
+
+import (
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// MatchesMap reports whether every key/value of a Service-style map selector
+// is present in labels. An empty selector matches nothing, since Services
+// without a selector do not select pods.
+func MatchesMap(selector map[string]interface{}, labels map[string]string) bool {
+	if len(selector) == 0 {
+		return false
+	}
+	for k, v := range selector {
+		sv, ok := v.(string)
+		if !ok {
+			return false
+		}
+		if labels[k] != sv {
+			return false
+		}
+	}
+	return true
+}
+
+// MapKeys returns the selector's key=value pairs in sorted order, for messages.
+func MapKeys(selector map[string]interface{}) []string {
+	pairs := make([]string, 0, len(selector))
+	for k, v := range selector {
+		sv, _ := v.(string)
+		pairs = append(pairs, k+"="+sv)
+	}
+	sort.Strings(pairs)
+	return pairs
+}
+
+// MatchesLabelSelector reports whether a metav1.LabelSelector in unstructured
+// form (matchLabels/matchExpressions) selects the given labels. An empty
+// selector matches everything; a malformed one matches nothing.
+func MatchesLabelSelector(selector map[string]interface{}, set map[string]string) bool {
+	var ls metav1.LabelSelector
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(selector, &ls); err != nil {
+		return false
+	}
+	sel, err := metav1.LabelSelectorAsSelector(&ls)
+	if err != nil {
+		return false
+	}
+	return sel.Matches(labels.Set(set))
+}
+
+// IsEmptyLabelSelector reports whether a label selector has no requirements.
+func IsEmptyLabelSelector(selector map[string]interface{}) bool {
+	matchLabels, _ := selector["matchLabels"].(map[string]interface{})
+	matchExpressions, _ := selector["matchExpressions"].([]interface{})
+	return len(matchLabels) == 0 && len(matchExpressions) == 0
+}
@@ -0,0 +1,204 @@
+package transfer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// TransferLabel marks every helper object with the id of its transfer.
+	TransferLabel = "kubecopy.io/transfer"
+	rsyncPort     = 873
+	sourceMount   = "/source"
+	targetMount   = "/target"
+)
+
+// rsyncArgs preserves ownership, permissions, and hard links, and reports
+// overall progress on one line.
+const rsyncArgs = "-aH --numeric-ids --no-inc-recursive --info=progress2"
+
+// rsyncdConfig serves /source read-only as module "data". The daemon only
+// listens on the pod's loopback interface, which the tunnel reaches through
+// a port-forward, so nothing else can connect to it.
+const rsyncdConfig = `address = 127.0.0.1
+[data]
+path = ` + sourceMount + `
+read only = true
+use chroot = false
+uid = root
+gid = root
+`
+
+func (m *Mover) image() string {
+	if m.Image != "" {
+		return m.Image
+	}
+	return DefaultImage
+}
+
+func helperLabels(id, role string) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/managed-by": "kubecopy",
+		"app.kubernetes.io/component":  role,
+		TransferLabel:                  id,
+	}
+}
+
+func claimVolume(name, claim string, readOnly bool) corev1.Volume {
+	return corev1.Volume{
+		Name: name,
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claim, ReadOnly: readOnly},
+		},
+	}
+}
+
+// helperPod builds a run-to-completion pod running script in sh.
+func (m *Mover) helperPod(name, id, role, script string, volumes []corev1.Volume, mounts []corev1.VolumeMount) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: helperLabels(id, role)},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{{
+				Name:         "rsync",
+				Image:        m.image(),
+				Command:      []string{"sh", "-c", script},
+				VolumeMounts: mounts,
+			}},
+			Volumes: volumes,
+		},
+	}
+}
+
+// localPod mounts both claims and copies between them.
+func (m *Mover) localPod(id string, src, dst Volume, node string) *corev1.Pod {
+	pod := m.helperPod("kubecopy-rsync-"+id, id, "rsync",
+		fmt.Sprintf("rsync %s %s/ %s/", rsyncArgs, sourceMount, targetMount),
+		[]corev1.Volume{claimVolume("source", src.Claim, true), claimVolume("target", dst.Claim, false)},
+		[]corev1.VolumeMount{{Name: "source", MountPath: sourceMount, ReadOnly: true}, {Name: "target", MountPath: targetMount}})
+	pod.Spec.NodeName = node
+	return pod
+}
+
+// daemonPod serves the source claim with an rsync daemon.
+func (m *Mover) daemonPod(id string, src Volume, node string) *corev1.Pod {
+	script := fmt.Sprintf("cat > /tmp/rsyncd.conf <<'EOF'\n%sEOF\nexec rsync --daemon --no-detach --port=%d --config=/tmp/rsyncd.conf",
+		rsyncdConfig, rsyncPort)
+	pod := m.helperPod("kubecopy-rsyncd-"+id, id, "rsyncd", script,
+		[]corev1.Volume{claimVolume("source", src.Claim, true)},
+		[]corev1.VolumeMount{{Name: "source", MountPath: sourceMount, ReadOnly: true}})
+	pod.Spec.NodeName = node
+	return pod
+}
+
+// receiverPod pulls from the daemon into the target claim. Its rsync talks
+// to the daemon over the connection nc accepts on the loopback interface,
+// which the tunnel forwards to the daemon (rsync's daemon protocol over a
+// remote shell, with nc standing in for the shell).
+func (m *Mover) receiverPod(id string, dst Volume) *corev1.Pod {
+	script := fmt.Sprintf(`exec rsync %s -e "sh -c 'exec nc -l -s 127.0.0.1 -p %d' --" tunnel::data/ %s/`,
+		rsyncArgs, tunnelPort, targetMount)
+	return m.helperPod("kubecopy-rsync-"+id, id, "rsync", script,
+		[]corev1.Volume{claimVolume("target", dst.Claim, false)},
+		[]corev1.VolumeMount{{Name: "target", MountPath: targetMount}})
+}
+
+func podStarted(pod *corev1.Pod) bool {
+	return pod.Status.Phase != corev1.PodPending
+}
+
+func podFinished(pod *corev1.Pod) bool {
+	return pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed
+}
+
+// waitForPod polls a pod until done reports true. A zero timeout waits until
+// ctx is cancelled.
+func waitForPod(ctx context.Context, client kubernetes.Interface, namespace, name string, timeout time.Duration, done func(*corev1.Pod) bool) (*corev1.Pod, error) {
+	var pod *corev1.Pod
+	condition := func(ctx context.Context) (bool, error) {
+		var err error
+		pod, err = client.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		if pod.Status.Phase == corev1.PodFailed {
+			return true, nil
+		}
+		return done(pod), nil
+	}
+
+	var err error
+	if timeout > 0 {
+		err = wait.PollUntilContextTimeout(ctx, pollInterval, timeout, true, condition)
+	} else {
+		err = wait.PollUntilContextCancel(ctx, pollInterval, true, condition)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("waiting for pod %s/%s: %w", namespace, name, err)
+	}
+	if pod.Status.Phase == corev1.PodFailed && !done(pod) {
+		return nil, fmt.Errorf("pod %s/%s failed: %s", namespace, name, pod.Status.Message)
+	}
+	return pod, nil
+}
+
+// followProgress streams the pod's logs, passing rsync's progress2 byte
+// counts to report, and returns the last line that was not a progress line.
+func followProgress(ctx context.Context, client kubernetes.Interface, namespace, name string, report func(int64)) string {
+	stream, err := client.CoreV1().Pods(namespace).GetLogs(name, &corev1.PodLogOptions{Follow: true}).Stream(ctx)
+	if err != nil {
+		return ""
+	}
+	defer stream.Close()
+
+	var last string
+	scanner := bufio.NewScanner(stream)
+	// progress2 rewrites its line with carriage returns
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+			return i + 1, data[:i], nil
+		}
+		if atEOF && len(data) > 0 {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	})
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if n, ok := parseProgress(line); ok {
+			if report != nil {
+				report(n)
+			}
+			continue
+		}
+		last = line
+	}
+	return last
+}
+
+// parseProgress reads the byte count from an rsync progress2 line such as
+// "  1,234,567  45%  1.23MB/s    0:00:12".
+func parseProgress(line string) (int64, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 || !strings.HasSuffix(fields[1], "%") {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(strings.ReplaceAll(fields[0], ",", ""), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
@@ -0,0 +1,296 @@
+// Package transfer copies the contents of a PersistentVolumeClaim into
+// another one by running short-lived rsync helper pods.
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// DefaultImage is the helper image; it must provide sh, rsync, and nc.
+const DefaultImage = "docker.io/instrumentisto/rsync-ssh:alpine3.20"
+
+// Timeouts for the phases that wait on the cluster rather than on the data.
+var (
+	bindTimeout    = 2 * time.Minute
+	startTimeout   = 5 * time.Minute
+	pollInterval   = 2 * time.Second
+	cleanupTimeout = 30 * time.Second
+)
+
+// Volume identifies a PersistentVolumeClaim.
+type Volume struct {
+	Namespace string
+	Claim     string
+}
+
+func (v Volume) String() string {
+	return v.Namespace + "/" + v.Claim
+}
+
+// Mover copies volume contents between two PVCs.
+//
+// Within one namespace of one cluster a single pod mounts both claims and
+// runs rsync locally. Otherwise a pod in the source namespace serves the
+// source claim read-only with an rsync daemon, and a pod in the target
+// namespace pulls from it through a tunnel: a port-forward to each pod,
+// joined by this process. Neither pod is exposed beyond its loopback
+// interface, and the clusters need not reach each other. Helper pods are
+// always deleted when the transfer ends.
+type Mover struct {
+	Source kubernetes.Interface
+	Target kubernetes.Interface
+
+	// SourceConfig and TargetConfig reach the API servers of Source and
+	// Target, for the tunnel's port-forwards.
+	SourceConfig *rest.Config
+	TargetConfig *rest.Config
+
+	// SameCluster is true when Source and Target talk to the same cluster.
+	SameCluster bool
+
+	// Force transfers from a ReadWriteOnce claim that a running pod mounts,
+	// pinning the helper to that pod's node.
+	Force bool
+
+	// Image overrides DefaultImage.
+	Image string
+
+	// Progress, when set, is called with the number of bytes transferred so far.
+	Progress func(bytes int64)
+
+	// Audit, when set, is called for every helper pod created or deleted,
+	// with source set for those in the source cluster and err the request's
+	// error.
+	Audit func(source bool, verb, namespace, name string, err error)
+}
+
+// Copy transfers the contents of src into dst. dst must already exist; Copy
+// waits for it to bind unless its StorageClass binds on first consumer.
+func (m *Mover) Copy(ctx context.Context, src, dst Volume) error {
+	node, err := m.sourceNode(ctx, src)
+	if err != nil {
+		return err
+	}
+	if err := m.waitForBound(ctx, dst); err != nil {
+		return err
+	}
+
+	id := utilrand.String(5)
+	helpers := &cleanup{}
+	defer helpers.run()
+
+	if m.SameCluster && src.Namespace == dst.Namespace {
+		pod := m.localPod(id, src, dst, node)
+		if err := m.createPod(ctx, false, dst.Namespace, pod, helpers); err != nil {
+			return fmt.Errorf("create transfer pod: %w", err)
+		}
+		return m.run(ctx, m.Target, dst.Namespace, pod.Name)
+	}
+
+	daemon := m.daemonPod(id, src, node)
+	if err := m.createPod(ctx, true, src.Namespace, daemon, helpers); err != nil {
+		return fmt.Errorf("create rsync daemon pod: %w", err)
+	}
+
+	running, err := waitForPod(ctx, m.Source, src.Namespace, daemon.Name, startTimeout, podStarted)
+	if err != nil {
+		return fmt.Errorf("rsync daemon for %s: %w", src, err)
+	}
+	if running.Status.Phase != corev1.PodRunning {
+		return fmt.Errorf("rsync daemon for %s exited (%s)", src, running.Status.Phase)
+	}
+
+	pod := m.receiverPod(id, dst)
+	if err := m.createPod(ctx, false, dst.Namespace, pod, helpers); err != nil {
+		return fmt.Errorf("create transfer pod: %w", err)
+	}
+	if _, err := waitForPod(ctx, m.Target, dst.Namespace, pod.Name, startTimeout, podStarted); err != nil {
+		return fmt.Errorf("transfer pod: %w", err)
+	}
+
+	// The tunnel ends the transfer early when it cannot be set up, since the
+	// receiver would wait for it forever
+	tunnelCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	tunnelErr := make(chan error, 1)
+	go func() {
+		err := m.tunnel(tunnelCtx, src, daemon.Name, dst, pod.Name)
+		if err != nil {
+			cancel()
+		}
+		tunnelErr <- err
+	}()
+	err = m.run(tunnelCtx, m.Target, dst.Namespace, pod.Name)
+	cancel()
+	if tErr := <-tunnelErr; tErr != nil && !errors.Is(tErr, context.Canceled) {
+		return tErr
+	}
+	return err
+}
+
+// createPod creates a helper pod in the source or target cluster and has
+// helpers delete it.
+func (m *Mover) createPod(ctx context.Context, source bool, namespace string, pod *corev1.Pod, helpers *cleanup) error {
+	client := m.Target
+	if source {
+		client = m.Source
+	}
+	_, err := client.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+	m.audit(source, "create", namespace, pod.Name, err)
+	if err != nil {
+		return err
+	}
+	grace := int64(0)
+	helpers.deletes = append(helpers.deletes, func(ctx context.Context) error {
+		err := client.CoreV1().Pods(namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{GracePeriodSeconds: &grace})
+		m.audit(source, "delete", namespace, pod.Name, err)
+		return err
+	})
+	return nil
+}
+
+// audit reports a request for a helper pod to Audit.
+func (m *Mover) audit(source bool, verb, namespace, name string, err error) {
+	if m.Audit != nil {
+		m.Audit(source, verb, namespace, name, err)
+	}
+}
+
+// sourceNode refuses a ReadWriteOnce source claim that a running pod mounts,
+// since the helper could not attach it, unless Force is set. With Force, it
+// returns the node of that pod so the helper can share the attachment.
+func (m *Mover) sourceNode(ctx context.Context, src Volume) (string, error) {
+	pvc, err := m.Source.CoreV1().PersistentVolumeClaims(src.Namespace).Get(ctx, src.Claim, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("get source PVC %s: %w", src, err)
+	}
+	if !slices.Contains(pvc.Spec.AccessModes, corev1.ReadWriteOnce) && !slices.Contains(pvc.Spec.AccessModes, corev1.ReadWriteOncePod) {
+		return "", nil
+	}
+
+	pods, err := m.Source.CoreV1().Pods(src.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return "", fmt.Errorf("list pods using %s: %w", src, err)
+	}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodRunning || !mountsClaim(&pod, src.Claim) {
+			continue
+		}
+		if !m.Force {
+			return "", fmt.Errorf("source PVC %s is ReadWriteOnce and mounted by running pod %s.\n"+
+				"    Scale the workload down first, or pass --force-data to copy it live", src, pod.Name)
+		}
+		return pod.Spec.NodeName, nil
+	}
+	return "", nil
+}
+
+func mountsClaim(pod *corev1.Pod, claim string) bool {
+	for _, v := range pod.Spec.Volumes {
+		if v.PersistentVolumeClaim != nil && v.PersistentVolumeClaim.ClaimName == claim {
+			return true
+		}
+	}
+	return false
+}
+
+// waitForBound waits for dst to bind. Claims whose StorageClass uses
+// WaitForFirstConsumer are bound by the transfer pod itself.
+func (m *Mover) waitForBound(ctx context.Context, dst Volume) error {
+	pvcs := m.Target.CoreV1().PersistentVolumeClaims(dst.Namespace)
+	pvc, err := pvcs.Get(ctx, dst.Claim, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("get target PVC %s: %w", dst, err)
+	}
+	if pvc.Status.Phase == corev1.ClaimBound || m.bindsOnFirstConsumer(ctx, pvc) {
+		return nil
+	}
+
+	err = wait.PollUntilContextTimeout(ctx, pollInterval, bindTimeout, false, func(ctx context.Context) (bool, error) {
+		pvc, err := pvcs.Get(ctx, dst.Claim, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return pvc.Status.Phase == corev1.ClaimBound, nil
+	})
+	if err != nil {
+		return fmt.Errorf("target PVC %s did not bind within %s: %w", dst, bindTimeout, err)
+	}
+	return nil
+}
+
+func (m *Mover) bindsOnFirstConsumer(ctx context.Context, pvc *corev1.PersistentVolumeClaim) bool {
+	var class *storagev1.StorageClass
+	var err error
+	if pvc.Spec.StorageClassName != nil && *pvc.Spec.StorageClassName != "" {
+		class, err = m.Target.StorageV1().StorageClasses().Get(ctx, *pvc.Spec.StorageClassName, metav1.GetOptions{})
+		if err != nil {
+			return false
+		}
+	} else {
+		class = defaultStorageClass(ctx, m.Target)
+	}
+	return class != nil && class.VolumeBindingMode != nil && *class.VolumeBindingMode == storagev1.VolumeBindingWaitForFirstConsumer
+}
+
+func defaultStorageClass(ctx context.Context, client kubernetes.Interface) *storagev1.StorageClass {
+	classes, err := client.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil
+	}
+	for i := range classes.Items {
+		if classes.Items[i].Annotations["storageclass.kubernetes.io/is-default-class"] == "true" {
+			return &classes.Items[i]
+		}
+	}
+	return nil
+}
+
+// run waits for a transfer pod to finish, reporting progress from its logs.
+func (m *Mover) run(ctx context.Context, client kubernetes.Interface, namespace, name string) error {
+	if _, err := waitForPod(ctx, client, namespace, name, startTimeout, podStarted); err != nil {
+		return fmt.Errorf("transfer pod: %w", err)
+	}
+
+	logs := make(chan string, 1)
+	go func() {
+		logs <- followProgress(ctx, client, namespace, name, m.Progress)
+	}()
+
+	pod, err := waitForPod(ctx, client, namespace, name, 0, podFinished)
+	if err != nil {
+		return fmt.Errorf("transfer pod: %w", err)
+	}
+	lastLine := <-logs
+	if pod.Status.Phase != corev1.PodSucceeded {
+		return fmt.Errorf("rsync failed: %s", lastLine)
+	}
+	return nil
+}
+
+// cleanup deletes the helper objects of a transfer. It runs on its own
+// context so helpers are removed even when the transfer was cancelled.
+type cleanup struct {
+	deletes []func(context.Context) error
+}
+
+func (c *cleanup) run() {
+	ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
+	for i := len(c.deletes) - 1; i >= 0; i-- {
+		// Best effort: leftovers carry the managed-by label
+		_ = c.deletes[i](ctx)
+	}
+}
@@ -0,0 +1,73 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func claim(namespace, name string, phase corev1.PersistentVolumeClaimPhase) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Status:     corev1.PersistentVolumeClaimStatus{Phase: phase},
+	}
+}
+
+func TestCopyAuditsHelperPods(t *testing.T) {
+	source := fake.NewClientset(claim("prod", "data", corev1.ClaimBound))
+	target := fake.NewClientset(claim("staging", "data", corev1.ClaimBound))
+
+	// The daemon starts at once; the receiver is refused
+	source.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		pod := action.(k8stesting.CreateAction).GetObject().(*corev1.Pod)
+		pod.Status.Phase = corev1.PodRunning
+		return false, nil, nil
+	})
+	target.PrependReactor("create", "pods", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("quota exceeded")
+	})
+
+	var got []string
+	m := &Mover{
+		Source: source,
+		Target: target,
+		Audit: func(source bool, verb, namespace, name string, err error) {
+			cluster := "target"
+			if source {
+				cluster = "source"
+			}
+			// Helper names end in a random transfer id
+			name = name[:strings.LastIndex(name, "-")]
+			got = append(got, fmt.Sprintf("%s %s %s/%s %v", cluster, verb, namespace, name, err))
+		},
+	}
+	err := m.Copy(context.Background(), Volume{Namespace: "prod", Claim: "data"}, Volume{Namespace: "staging", Claim: "data"})
+	if err == nil || !strings.Contains(err.Error(), "quota exceeded") {
+		t.Fatalf("Copy() error = %v, want the refused receiver", err)
+	}
+
+	want := []string{
+		"source create prod/kubecopy-rsyncd <nil>",
+		"target create staging/kubecopy-rsync quota exceeded",
+		"source delete prod/kubecopy-rsyncd <nil>",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("audited\n  %s\nwant\n  %s", strings.Join(got, "\n  "), strings.Join(want, "\n  "))
+	}
+	pods, err := source.CoreV1().Pods("prod").List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pods.Items) != 0 {
+		t.Errorf("%d helper pods left in the source", len(pods.Items))
+	}
+}
@@ -0,0 +1,156 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+// tunnelPort is where the receiver pod accepts the tunneled connection to
+// the rsync daemon, on its loopback interface.
+const tunnelPort = 8730
+
+// podStream is one connection to a port of a pod, forwarded through the
+// API server as kubectl port-forward does.
+type podStream struct {
+	conn httpstream.Connection
+	data httpstream.Stream
+	errc chan error // what the kubelet reported about the connection
+}
+
+// dialPod opens a connection to port on a pod's loopback interface.
+func dialPod(cfg *rest.Config, client kubernetes.Interface, namespace, name string, port int) (*podStream, error) {
+	transport, upgrader, err := spdy.RoundTripperFor(cfg)
+	if err != nil {
+		return nil, err
+	}
+	url := client.CoreV1().RESTClient().Post().Resource("pods").Namespace(namespace).Name(name).SubResource("portforward").URL()
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, url)
+	conn, _, err := dialer.Dial(portforward.PortForwardProtocolV1Name)
+	if err != nil {
+		return nil, fmt.Errorf("port-forward to pod %s/%s: %w", namespace, name, err)
+	}
+
+	headers := http.Header{}
+	headers.Set(corev1.StreamType, corev1.StreamTypeError)
+	headers.Set(corev1.PortHeader, strconv.Itoa(port))
+	headers.Set(corev1.PortForwardRequestIDHeader, "0")
+	errorStream, err := conn.CreateStream(headers)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("port-forward to pod %s/%s: %w", namespace, name, err)
+	}
+	// Only read from
+	errorStream.Close()
+
+	s := &podStream{conn: conn, errc: make(chan error, 1)}
+	go func() {
+		message, err := io.ReadAll(errorStream)
+		switch {
+		case err != nil:
+			s.errc <- err
+		case len(message) > 0:
+			s.errc <- errors.New(string(message))
+		}
+		close(s.errc)
+	}()
+
+	headers.Set(corev1.StreamType, corev1.StreamTypeData)
+	if s.data, err = conn.CreateStream(headers); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("port-forward to pod %s/%s: %w", namespace, name, err)
+	}
+	return s, nil
+}
+
+func (s *podStream) Close() error {
+	return s.conn.Close()
+}
+
+// refused returns why the kubelet ended a connection that carried no data,
+// typically that nothing listens on the port yet.
+func (s *podStream) refused() error {
+	select {
+	case err := <-s.errc:
+		if err != nil {
+			return err
+		}
+	case <-time.After(pollInterval):
+	}
+	return errors.New("connection closed")
+}
+
+// tunnel connects the receiver pod's rsync to the daemon pod: it forwards a
+// connection to each and copies between them until both sides end. It
+// returns an error only when the tunnel could not be set up; failures of the
+// transfer itself are rsync's to report.
+func (m *Mover) tunnel(ctx context.Context, src Volume, daemon string, dst Volume, receiver string) error {
+	// The receiver's rsync sends its greeting as soon as a connection is
+	// accepted. Until its listener is up, the kubelet refuses connections.
+	var target *podStream
+	var greeting []byte
+	var lastErr error
+	err := wait.PollUntilContextTimeout(ctx, pollInterval, startTimeout, true, func(ctx context.Context) (bool, error) {
+		s, err := dialPod(m.TargetConfig, m.Target, dst.Namespace, receiver, tunnelPort)
+		if err != nil {
+			return false, err
+		}
+		stop := context.AfterFunc(ctx, func() { s.Close() })
+		buf := make([]byte, 4096)
+		n, _ := s.data.Read(buf)
+		stop()
+		if n == 0 {
+			lastErr = s.refused()
+			s.Close()
+			return false, nil
+		}
+		target, greeting = s, buf[:n]
+		return true, nil
+	})
+	if err != nil {
+		if lastErr != nil && !errors.Is(err, context.Canceled) {
+			err = fmt.Errorf("%w (last attempt: %v)", err, lastErr)
+		}
+		return fmt.Errorf("connect to transfer pod %s/%s: %w", dst.Namespace, receiver, err)
+	}
+	defer target.Close()
+
+	source, err := dialPod(m.SourceConfig, m.Source, src.Namespace, daemon, rsyncPort)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+	stop := context.AfterFunc(ctx, func() {
+		source.Close()
+		target.Close()
+	})
+	defer stop()
+
+	if _, err := source.data.Write(greeting); err != nil {
+		return fmt.Errorf("connect to rsync daemon %s/%s: %w", src.Namespace, daemon, err)
+	}
+	// Each side's end is passed on, so the other one ends too
+	done := make(chan struct{}, 2)
+	pipe := func(w httpstream.Stream, r io.Reader) {
+		_, _ = io.Copy(w, r)
+		w.Close()
+		done <- struct{}{}
+	}
+	go pipe(source.data, target.data)
+	go pipe(target.data, source.data)
+	<-done
+	<-done
+	return nil
+}